@@ -2,8 +2,9 @@ package main
 
 import (
 	"github.com/nickeskov/db_forum/internal/app/db_forum"
+	"os"
 )
 
 func main() {
-	db_forum.StartNew()
+	os.Exit(db_forum.RunCommand(os.Args))
 }
@@ -1,78 +1,184 @@
 package db_forum
 
 import (
+	"context"
 	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
+	authDelivery "github.com/nickeskov/db_forum/internal/pkg/auth/delivery"
+	"github.com/nickeskov/db_forum/internal/pkg/config"
+	"github.com/nickeskov/db_forum/internal/pkg/events"
+	eventsDelivery "github.com/nickeskov/db_forum/internal/pkg/events/delivery"
 	forumDelivery "github.com/nickeskov/db_forum/internal/pkg/forum/delivery"
-	forumRepository "github.com/nickeskov/db_forum/internal/pkg/forum/repository"
-	forumUseCase "github.com/nickeskov/db_forum/internal/pkg/forum/usecase"
+	"github.com/nickeskov/db_forum/internal/pkg/health"
+	healthDelivery "github.com/nickeskov/db_forum/internal/pkg/health/delivery"
+	healthUseCase "github.com/nickeskov/db_forum/internal/pkg/health/usecase"
+	"github.com/nickeskov/db_forum/internal/pkg/migrations"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/pagination"
 	postDelivery "github.com/nickeskov/db_forum/internal/pkg/post/delivery"
-	postRepository "github.com/nickeskov/db_forum/internal/pkg/post/repository"
-	postUseCase "github.com/nickeskov/db_forum/internal/pkg/post/usecase"
+	searchDelivery "github.com/nickeskov/db_forum/internal/pkg/search/delivery"
 	serviceDelivery "github.com/nickeskov/db_forum/internal/pkg/service/delivery"
-	serviceRepository "github.com/nickeskov/db_forum/internal/pkg/service/repository"
-	serviceUseCase "github.com/nickeskov/db_forum/internal/pkg/service/usecase"
 	threadDelivery "github.com/nickeskov/db_forum/internal/pkg/thread/delivery"
-	threadRepository "github.com/nickeskov/db_forum/internal/pkg/thread/repository"
-	threadUseCase "github.com/nickeskov/db_forum/internal/pkg/thread/usecase"
 	userDelivery "github.com/nickeskov/db_forum/internal/pkg/user/delivery"
-	userRepository "github.com/nickeskov/db_forum/internal/pkg/user/repository"
-	userUseCase "github.com/nickeskov/db_forum/internal/pkg/user/usecase"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+	webhookDelivery "github.com/nickeskov/db_forum/internal/pkg/webhook/delivery"
+	webhookUseCase "github.com/nickeskov/db_forum/internal/pkg/webhook/usecase"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
 	"github.com/nickeskov/db_forum/pkg/logger"
 	"github.com/nickeskov/db_forum/pkg/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
 	"os"
 	"time"
 )
 
-const loggerKey = 1
+func StartNew(cfg config.Config) {
+	serve(cfg, NewLogger(cfg.Logger, os.Stdout))
+}
 
-func StartNew() {
-	customLogger := logger.NewTextFormatSimpleLogger(os.Stdout, loggerKey)
+func serve(cfg config.Config, customLogger logger.SimpleLogger) {
 	customLogger.Printf(">>>>>>>>>>>>%v<<<<<<<<<<<<\n", time.Now())
+	customLogger.Printf("effective config:\n%s", cfg)
 
-	// TODO(nickeskov): hardcoded database credentials
-	dbConnPool, err := ConnectToDB(
-		"localhost",
-		"my_db_forum",
-		"my_db_forum",
-		"my_db_forum",
-		10,
-	)
-
+	tracerProvider, err := newTracerProvider(cfg.Tracing, customLogger)
 	if err != nil {
-		customLogger.Fatalln("cannot connect to postgres:", err)
-	} else {
-		customLogger.Println("successfully connected to postgres")
+		customLogger.Fatalln("cannot start tracing:", err)
+	}
+	var tracer trace.Tracer
+	if tracerProvider != nil {
+		tracer = tracerProvider.Tracer(tracerName)
+		customLogger.Printf("tracing is enabled, spans are exported to %s", cfg.Tracing.Exporter)
+	}
+
+	var dbConnPool *pgxpool.Pool
+	var repos repositories
+
+	switch cfg.Storage.Type {
+	case config.MemoryStorage:
+		customLogger.Println("using in-memory storage, all data will be lost on shutdown")
+		repos = newMemoryRepositories(memoryDB.NewDB())
+
+	default:
+		dbConnPool, err = ConnectToDB(cfg.Database, tracer)
+
+		if err != nil {
+			customLogger.Fatalln("cannot connect to postgres:", err)
+		} else {
+			customLogger.Println("successfully connected to postgres")
+		}
+
+		repos = newPgxRepositories(dbConnPool)
+	}
+
+	broker := events.NewBroker(int(cfg.Events.HistorySize), int(cfg.Events.SubscriberBuffer))
+
+	registry := newRegistry()
+	var publisher events.Publisher = broker
+	var repositoryDurations *prometheus.HistogramVec
+	if cfg.Metrics.Enabled {
+		repositoryDurations = newRepositoryDurations(registry)
+		if dbConnPool != nil {
+			registry.MustRegister(newPoolCollector(dbConnPool))
+		}
+		publisher = newCountingPublisher(broker, registry)
+	}
+	if cfg.Metrics.Enabled || tracer != nil {
+		repos = instrumentRepositories(repos, repositoryDurations, tracer)
 	}
 
-	userRepo := userRepository.NewRepository(dbConnPool)
-	forumRepo := forumRepository.NewRepository(dbConnPool)
-	threadRepo := threadRepository.NewRepository(dbConnPool, forumRepo)
-	postRepo := postRepository.NewRepository(dbConnPool)
-	serviceRepo := serviceRepository.NewRepository(dbConnPool)
+	useCases := newUseCases(repos, cfg, publisher)
+	if tracer != nil {
+		useCases = instrumentUseCases(useCases, tracer)
+	}
 
-	userUC := userUseCase.NewUseCase(userRepo)
-	forumUC := forumUseCase.NewUseCase(forumRepo)
-	threadUC := threadUseCase.NewUseCase(threadRepo)
-	postUC := postUseCase.NewUseCase(postRepo, userRepo, forumRepo, threadRepo)
-	serviceUC := serviceUseCase.NewUseCase(serviceRepo)
+	guard := auth.NewGuard(cfg.Auth.Enabled, useCases.forum)
+	if !guard.Enabled() {
+		customLogger.Println("authentication is disabled, anyone can write on behalf of any user, " +
+			"moderation and webhooks still require session")
+	}
 
-	userHandlers := userDelivery.NewDelivery(userUC, customLogger)
-	forumHandlers := forumDelivery.NewDelivery(forumUC, customLogger)
-	threadHandlers := threadDelivery.NewDelivery(threadUC, customLogger)
-	postHandlers := postDelivery.NewDelivery(postUC, customLogger)
-	serviceHandlers := serviceDelivery.NewDelivery(serviceUC, customLogger)
+	cursorSecret := []byte(cfg.Pagination.CursorSecret)
+	if len(cursorSecret) == 0 {
+		var err error
+		if cursorSecret, err = pagination.RandomSecret(); err != nil {
+			customLogger.Fatalln("cannot generate cursor secret:", err)
+		}
+		customLogger.Println("pagination.cursor_secret is empty, cursor tokens will be invalid after restart")
+	}
+	signer := pagination.NewSigner(cursorSecret)
 
-	router := mux.NewRouter().PathPrefix("/api").Subrouter()
+	authHandlers := authDelivery.NewDelivery(useCases.auth, customLogger)
+	userHandlers := userDelivery.NewDelivery(useCases.user, guard, customLogger)
+	forumHandlers := forumDelivery.NewDelivery(useCases.forum, guard, signer, customLogger)
+	threadHandlers := threadDelivery.NewDelivery(useCases.thread, guard, signer, customLogger)
+	postHandlers := postDelivery.NewDelivery(useCases.post, guard, signer, customLogger)
+	searchHandlers := searchDelivery.NewDelivery(useCases.search, customLogger)
+	serviceHandlers := serviceDelivery.NewDelivery(useCases.service, customLogger)
+	webhookHandlers := webhookDelivery.NewDelivery(useCases.webhook, guard, customLogger)
+	// streams end a bit before write timeout, clients reconnect and resume from Last-Event-ID
+	eventsHandlers := eventsDelivery.NewDelivery(useCases.thread, broker,
+		cfg.Events.Heartbeat.Duration, cfg.Server.WriteTimeout.Duration*9/10, customLogger)
+
+	trustedProxies, err := httpUtils.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		customLogger.Fatalln("invalid server.trusted_proxies:", err)
+	}
+
+	rootRouter := mux.NewRouter()
+	if cfg.Metrics.Enabled {
+		rootRouter.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{})).Methods(http.MethodGet)
+	}
+
+	router := rootRouter.PathPrefix("/api").Subrouter()
+	var metricsRegisterer prometheus.Registerer
+	if cfg.Metrics.Enabled {
+		metricsRegisterer = registry
+	}
+	useObservingMiddlewares(router, cfg.Logger, customLogger, trustedProxies, metricsRegisterer, tracer)
 	router.Use(middleware.JsonContentTypeMiddleware)
 
+	routeQueryTimeouts := make(map[string]time.Duration, len(cfg.Server.RouteQueryTimeouts))
+	for route, timeout := range cfg.Server.RouteQueryTimeouts {
+		routeQueryTimeouts[route] = timeout.Duration
+	}
+	// streams live until client disconnects, their lifetime is limited by events delivery
+	routeQueryTimeouts[middleware.RouteKey(http.MethodGet, "/api/thread/{slug_or_id}/stream")] = 0
+	routeQueryTimeouts[middleware.RouteKey(http.MethodGet, "/api/ws")] = 0
+	router.Use(middleware.CreateRouteTimeoutMiddleware(cfg.Server.QueryTimeout.Duration, routeQueryTimeouts))
+	// stale token must not lock client out of login, logout checks token itself
+	authSkipRoutes := map[string]bool{
+		middleware.RouteKey(http.MethodPost, "/api/user/login"):  true,
+		middleware.RouteKey(http.MethodPost, "/api/user/logout"): true,
+	}
+	router.Use(middleware.CreateAuthMiddleware(useCases.auth.Authenticate, models.ErrUnauthorized,
+		authSkipRoutes, customLogger))
+
+	adminOnly := middleware.CreateAdminTokenMiddleware(cfg.Admin.Token)
+
+	router.HandleFunc("/user/login", authHandlers.Login).Methods(http.MethodPost)
+	router.HandleFunc("/user/logout", authHandlers.Logout).Methods(http.MethodPost)
+
 	router.HandleFunc("/user/{nickname}/profile", userHandlers.GetUser).Methods(http.MethodGet)
 	router.HandleFunc("/user/{nickname}/create", userHandlers.CreateUser).Methods(http.MethodPost)
 	router.HandleFunc("/user/{nickname}/profile", userHandlers.UpdateUser).Methods(http.MethodPost)
+	router.Handle("/user/{nickname}/password",
+		adminOnly(http.HandlerFunc(userHandlers.SetPassword))).Methods(http.MethodPost)
 
 	router.HandleFunc("/forum/create", forumHandlers.CreateForum).Methods(http.MethodPost)
 	router.HandleFunc("/forum/{slug}/details", forumHandlers.GetForumDetails).Methods(http.MethodGet)
 	router.HandleFunc("/forum/{slug}/users", forumHandlers.GetForumUsers).Methods(http.MethodGet)
+	router.HandleFunc("/forum/{slug}/roles", forumHandlers.GetForumRoles).Methods(http.MethodGet)
+	router.HandleFunc("/forum/{slug}/roles/{nickname}", forumHandlers.GrantForumRole).Methods(http.MethodPost)
+	router.HandleFunc("/forum/{slug}/roles/{nickname}", forumHandlers.RevokeForumRole).Methods(http.MethodDelete)
+
+	router.HandleFunc("/forum/{slug}/webhooks", webhookHandlers.CreateWebhook).Methods(http.MethodPost)
+	router.HandleFunc("/forum/{slug}/webhooks", webhookHandlers.GetForumWebhooks).Methods(http.MethodGet)
+
+	router.HandleFunc("/webhook/{id}", webhookHandlers.DeleteWebhook).Methods(http.MethodDelete)
+	router.HandleFunc("/webhook/{id}/deliveries", webhookHandlers.GetWebhookDeliveries).Methods(http.MethodGet)
 
 	router.HandleFunc("/forum/{slug}/create", threadHandlers.CreateThread).Methods(http.MethodPost)
 	router.HandleFunc("/forum/{slug}/threads", threadHandlers.GetThreadsByForumSlug).Methods(http.MethodGet)
@@ -80,18 +186,113 @@ func StartNew() {
 	router.HandleFunc("/thread/{slug_or_id}/details", threadHandlers.GetThreadBySlugOrID).Methods(http.MethodGet)
 	router.HandleFunc("/thread/{slug_or_id}/details", threadHandlers.UpdateThreadBySlugOrID).Methods(http.MethodPost)
 	router.HandleFunc("/thread/{slug_or_id}/vote", threadHandlers.VoteThreadBySlugOrID).Methods(http.MethodPost)
+	router.HandleFunc("/thread/{slug_or_id}/move", threadHandlers.MoveThreadBySlugOrID).Methods(http.MethodPost)
+	router.HandleFunc("/thread/{slug_or_id}/merge", threadHandlers.MergeThreadBySlugOrID).Methods(http.MethodPost)
+	router.HandleFunc("/thread/{slug_or_id}/stream", eventsHandlers.StreamThread).Methods(http.MethodGet)
 
 	router.HandleFunc("/thread/{slug_or_id}/create", postHandlers.CreatePostsByThreadSlugOrID).Methods(http.MethodPost)
 	router.HandleFunc("/thread/{slug_or_id}/posts", postHandlers.GetSortedPostsByThreadSlugOrID).Methods(http.MethodGet)
 
 	router.HandleFunc("/post/{id}/details", postHandlers.GetPostInfoByID).Methods(http.MethodGet)
 	router.HandleFunc("/post/{id}/details", postHandlers.UpdatePostByID).Methods(http.MethodPost)
+	router.HandleFunc("/post/{id}/details", postHandlers.DeletePostByID).Methods(http.MethodDelete)
+	router.HandleFunc("/post/{id}/children", postHandlers.GetPostChildren).Methods(http.MethodGet)
+	router.HandleFunc("/post/{id}/descendants", postHandlers.GetPostDescendants).Methods(http.MethodGet)
+	router.HandleFunc("/post/{id}/ancestors", postHandlers.GetPostAncestors).Methods(http.MethodGet)
+	router.HandleFunc("/post/{id}/split", postHandlers.SplitPostByID).Methods(http.MethodPost)
+	router.HandleFunc("/post/{id}/revisions", postHandlers.GetPostRevisions).Methods(http.MethodGet)
+	router.HandleFunc("/post/{id}/revisions/{n}", postHandlers.GetPostRevision).Methods(http.MethodGet)
+	router.Handle("/post/{id}/restore",
+		adminOnly(http.HandlerFunc(postHandlers.RestorePostByID))).Methods(http.MethodPost)
+
+	router.HandleFunc("/search", searchHandlers.Search).Methods(http.MethodGet)
+
+	router.HandleFunc("/ws", eventsHandlers.Gateway).Methods(http.MethodGet)
 
 	router.HandleFunc("/service/clear", serviceHandlers.DropAllData).Methods(http.MethodPost)
 	router.HandleFunc("/service/status", serviceHandlers.GetStatus).Methods(http.MethodGet)
 
-	// TODO(nickeskov): hardcoded server address and port
-	if err := http.ListenAndServe(":5000", router); err != nil {
-		customLogger.Fatalln("cannot start service:", err)
+	if err := checkRouteKeys(router, routeQueryTimeouts); err != nil {
+		customLogger.Fatalln("invalid server.route_query_timeouts:", err)
+	}
+
+	app := NewApplication(cfg.Server, rootRouter, dbConnPool, customLogger)
+	app.OnShutdown(func(ctx context.Context) {
+		broker.Close()
+		if err := eventsHandlers.WaitConnections(ctx); err != nil {
+			customLogger.LogError(err, "cannot wait for websocket connections")
+		}
+	})
+
+	healthChecks := []health.Check{health.ServerCheck(app.Draining)}
+	if dbConnPool != nil {
+		allMigrations, err := migrations.Load()
+		if err != nil {
+			customLogger.Fatalln("cannot load migrations:", err)
+		}
+		healthChecks = append(healthChecks,
+			health.DatabaseCheck(dbConnPool),
+			health.SchemaCheck(migrations.NewMigrator(dbConnPool, allMigrations)))
+	}
+	healthHandlers := healthDelivery.NewDelivery(
+		healthUseCase.NewUseCase(cfg.Health.CheckTimeout.Duration, healthChecks...), customLogger)
+
+	// probes are registered outside of /api, so they aren't limited by query timeouts and auth
+	rootRouter.HandleFunc("/healthz", healthHandlers.Healthz).Methods(http.MethodGet)
+	rootRouter.HandleFunc("/readyz", healthHandlers.Readyz).Methods(http.MethodGet)
+
+	if cfg.Webhooks.PollPeriod.Duration > 0 {
+		dispatcher := webhookUseCase.NewDispatcher(repos.webhook, cfg.Webhooks.RequestTimeout.Duration,
+			cfg.Webhooks.BatchSize, cfg.Webhooks.MaxAttempts,
+			cfg.Webhooks.BackoffBase.Duration, cfg.Webhooks.BackoffMax.Duration, customLogger)
+
+		dispatcherCtx, stopDispatcher := context.WithCancel(context.Background())
+		dispatcherDone := make(chan struct{})
+		go func() {
+			defer close(dispatcherDone)
+			dispatcher.Run(dispatcherCtx, cfg.Webhooks.PollPeriod.Duration)
+		}()
+
+		// interrupted deliveries are claimed again after their lease expires
+		app.OnShutdown(func(ctx context.Context) {
+			stopDispatcher()
+			select {
+			case <-dispatcherDone:
+			case <-ctx.Done():
+				customLogger.LogError(ctx.Err(), "webhooks dispatcher is not stopped")
+			}
+		})
+	} else {
+		customLogger.Println("webhooks.poll_period is 0, webhooks are not sent by this instance")
+	}
+	runErr := app.Run()
+
+	// spans of drained requests are exported after server is stopped
+	if tracerProvider != nil {
+		tracerCtx, cancelTracer := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+		if err := tracerProvider.Shutdown(tracerCtx); err != nil {
+			customLogger.LogError(err, "cannot export remaining spans")
+		}
+		cancelTracer()
+	}
+
+	if runErr != nil {
+		customLogger.Fatalln("service stopped with error:", runErr)
+	}
+	customLogger.Println("service stopped")
+}
+
+// useObservingMiddlewares adds tracing, access log and metrics of requests, registerer and tracer may be nil.
+// Tracing is the outermost one, so access log entry of request has trace id of its server span.
+func useObservingMiddlewares(router *mux.Router, cfg config.LoggerConfig, customLogger logger.SimpleLogger,
+	trustedProxies httpUtils.TrustedProxies, registerer prometheus.Registerer, tracer trace.Tracer) {
+
+	if tracer != nil {
+		router.Use(middleware.CreateTracingMiddleware(tracer))
+	}
+	router.Use(middleware.CreateAccessLogMiddleware(customLogger, trustedProxies,
+		int(cfg.AccessSampleInitial), int(cfg.AccessSampleThereafter)))
+	if registerer != nil {
+		router.Use(middleware.CreateMetricsMiddleware(registerer, metricsNamespace))
 	}
 }
@@ -0,0 +1,120 @@
+package db_forum
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nickeskov/db_forum/internal/pkg/config"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"github.com/pkg/errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Application owns http server and database pool and controls their lifecycle:
+// it serves requests until SIGINT/SIGTERM, then drains in-flight requests and closes the pool.
+// Database pool is nil when service runs with in-memory storage.
+type Application struct {
+	server          *http.Server
+	dbConnPool      *pgxpool.Pool
+	logger          logger.SimpleLogger
+	shutdownTimeout time.Duration
+	shutdownHooks   []func(ctx context.Context)
+	// draining is set to 1 when shutdown is started
+	draining int32
+}
+
+func NewApplication(cfg config.ServerConfig, handler http.Handler,
+	dbConnPool *pgxpool.Pool, logger logger.SimpleLogger) *Application {
+
+	return &Application{
+		server: &http.Server{
+			Addr:              cfg.Address,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout.Duration,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout.Duration,
+			WriteTimeout:      cfg.WriteTimeout.Duration,
+			IdleTimeout:       cfg.IdleTimeout.Duration,
+			MaxHeaderBytes:    int(cfg.MaxHeaderBytes),
+		},
+		dbConnPool:      dbConnPool,
+		logger:          logger,
+		shutdownTimeout: cfg.ShutdownTimeout.Duration,
+	}
+}
+
+// OnShutdown registers function which is called before draining requests, it must end long-lived requests
+// like streams, otherwise they hold draining until timeout. Hijacked connections are not drained by server,
+// so hook has to wait for them itself, but not after ctx is done: hooks share shutdown timeout with draining.
+func (app *Application) OnShutdown(hook func(ctx context.Context)) {
+	app.shutdownHooks = append(app.shutdownHooks, hook)
+}
+
+// Draining reports that shutdown is started and service should not receive new requests
+func (app *Application) Draining() bool {
+	return atomic.LoadInt32(&app.draining) == 1
+}
+
+// Run blocks until server fails or shutdown signal is received, shutdown is done in both cases
+func (app *Application) Run() error {
+	serverErr := make(chan error, 1)
+	go func() {
+		app.logger.Println("start listening on", app.server.Addr)
+		serverErr <- app.server.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-serverErr:
+		// hooks stop workers which were started with server, e.g. webhooks dispatcher
+		app.logger.LogError(err, "http server failed, shutting down")
+		if shutdownErr := app.Shutdown(); shutdownErr != nil {
+			app.logger.LogError(shutdownErr, "cannot shut down after server failure")
+		}
+		return errors.WithStack(err)
+
+	case sig := <-signals:
+		app.logger.Printf("received signal %s, shutting down", sig)
+	}
+
+	return app.Shutdown()
+}
+
+// Shutdown stops accepting new connections, waits for in-flight requests
+// not longer than shutdown timeout and then closes database pool
+func (app *Application) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
+	defer cancel()
+
+	atomic.StoreInt32(&app.draining, 1)
+
+	for _, hook := range app.shutdownHooks {
+		hook(ctx)
+	}
+
+	shutdownErr := app.server.Shutdown(ctx)
+	if shutdownErr != nil {
+		app.logger.LogError(shutdownErr, "cannot gracefully drain requests, closing connections")
+		if closeErr := app.server.Close(); closeErr != nil {
+			app.logger.LogError(closeErr, "cannot close http server")
+		}
+	}
+
+	app.closeDBConnPool()
+
+	return errors.WithStack(shutdownErr)
+}
+
+func (app *Application) closeDBConnPool() {
+	if app.dbConnPool == nil {
+		return
+	}
+	app.dbConnPool.Close()
+	app.logger.Println("database connection pool closed")
+}
@@ -0,0 +1,42 @@
+package db_forum
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/config"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"github.com/nickeskov/db_forum/pkg/requestid"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestApplicationRunsShutdownHooksWhenServerFails(t *testing.T) {
+	// address is busy, so server fails to listen
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %+v", err)
+	}
+	defer listener.Close()
+
+	cfg := config.Default().Server
+	cfg.Address = listener.Addr().String()
+
+	app := NewApplication(cfg, http.NotFoundHandler(), nil,
+		logger.NewTextFormatSimpleLogger(ioutil.Discard, requestid.ContextKey{}))
+
+	hookCalled := false
+	app.OnShutdown(func(ctx context.Context) {
+		hookCalled = true
+	})
+
+	if err := app.Run(); err == nil {
+		t.Fatalf("run on busy address succeeded")
+	}
+	if !hookCalled {
+		t.Fatalf("shutdown hook is not called after server failure")
+	}
+	if !app.Draining() {
+		t.Fatalf("application is not draining after server failure")
+	}
+}
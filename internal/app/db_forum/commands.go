@@ -0,0 +1,247 @@
+package db_forum
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/config"
+	"github.com/nickeskov/db_forum/internal/pkg/events"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	exitCodeOK    = 0
+	exitCodeError = 1
+	exitCodeUsage = 2
+)
+
+// commandEnv is shared by all commands: config is loaded and logger is set up before command run
+type commandEnv struct {
+	cfg    config.Config
+	logger logger.SimpleLogger
+}
+
+type command struct {
+	usage       string
+	description string
+	// setupFlags registers command specific flags and returns function which runs command
+	setupFlags func(flagSet *flag.FlagSet) func(env commandEnv, args []string) error
+}
+
+var commands = map[string]command{
+	"serve": {
+		usage:       "serve [flags]",
+		description: "start http server",
+		setupFlags: func(flagSet *flag.FlagSet) func(env commandEnv, args []string) error {
+			return func(env commandEnv, args []string) error {
+				serve(env.cfg, env.logger)
+				return nil
+			}
+		},
+	},
+	"migrate": {
+		usage:       "migrate [flags] up|down|status|redo",
+		description: "manage database schema migrations",
+		setupFlags: func(flagSet *flag.FlagSet) func(env commandEnv, args []string) error {
+			return func(env commandEnv, args []string) error {
+				if len(args) != 1 {
+					return errUsage
+				}
+				return migrate(env.cfg, env.logger, args[0])
+			}
+		},
+	},
+	"status": {
+		usage:       "status [flags]",
+		description: "print count of users, forums, threads and posts",
+		setupFlags: func(flagSet *flag.FlagSet) func(env commandEnv, args []string) error {
+			return runStatus
+		},
+	},
+	"clear": {
+		usage:       "clear [flags]",
+		description: "drop all forum data",
+		setupFlags: func(flagSet *flag.FlagSet) func(env commandEnv, args []string) error {
+			confirmed := flagSet.Bool("yes", false, "do not ask for confirmation")
+			return func(env commandEnv, args []string) error {
+				return runClear(env, *confirmed, os.Stdin, os.Stdout)
+			}
+		},
+	},
+	"recount": {
+		usage:       "recount [flags]",
+		description: "rebuild threads and posts counters of forums",
+		setupFlags: func(flagSet *flag.FlagSet) func(env commandEnv, args []string) error {
+			return runRecount
+		},
+	},
+	"seed": {
+		usage:       "seed [flags]",
+		description: "fill database with generated data",
+		setupFlags: func(flagSet *flag.FlagSet) func(env commandEnv, args []string) error {
+			seedCfg := defaultSeedConfig()
+			flagSet.IntVar(&seedCfg.Users, "users", seedCfg.Users, "number of users")
+			flagSet.IntVar(&seedCfg.Forums, "forums", seedCfg.Forums, "number of forums")
+			flagSet.IntVar(&seedCfg.ThreadsPerForum, "threads", seedCfg.ThreadsPerForum,
+				"number of threads per forum")
+			flagSet.IntVar(&seedCfg.PostsPerThread, "posts", seedCfg.PostsPerThread,
+				"number of posts per thread")
+			flagSet.Int64Var(&seedCfg.RandomSeed, "random-seed", seedCfg.RandomSeed, "seed of random generator")
+			return func(env commandEnv, args []string) error {
+				return runSeed(env, seedCfg)
+			}
+		},
+	},
+}
+
+var errUsage = errors.New("bad command usage")
+
+// RunCommand runs subcommand from args (os.Args format) and returns process exit code.
+// Without subcommand service is started as with "serve".
+func RunCommand(args []string) int {
+	programName, args := args[0], args[1:]
+
+	commandName := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		commandName, args = args[0], args[1:]
+	}
+
+	cmd, ok := commands[commandName]
+	if !ok {
+		printUsage(os.Stderr, programName)
+		return exitCodeUsage
+	}
+
+	flagSet := flag.NewFlagSet(programName+" "+commandName, flag.ContinueOnError)
+	flagSet.Usage = func() {
+		_, _ = fmt.Fprintf(flagSet.Output(), "Usage: %s %s\n%s\n\nFlags:\n",
+			programName, cmd.usage, cmd.description)
+		flagSet.PrintDefaults()
+	}
+
+	run := cmd.setupFlags(flagSet)
+
+	cfg, err := config.Load(flagSet, args)
+	switch {
+	case err == flag.ErrHelp:
+		return exitCodeOK
+	case err != nil:
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return exitCodeUsage
+	}
+
+	// serve logs to stdout as before, other commands keep stdout for their output
+	logOutput := os.Stderr
+	if commandName == "serve" {
+		logOutput = os.Stdout
+	}
+
+	env := commandEnv{
+		cfg:    cfg,
+		logger: NewLogger(cfg.Logger, logOutput),
+	}
+
+	switch err := run(env, flagSet.Args()); {
+	case err == errUsage:
+		flagSet.Usage()
+		return exitCodeUsage
+	case err != nil:
+		_, _ = fmt.Fprintf(os.Stderr, "%s failed: %v\n", commandName, err)
+		return exitCodeError
+	}
+
+	return exitCodeOK
+}
+
+func printUsage(writer io.Writer, programName string) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	_, _ = fmt.Fprintf(writer, "Usage: %s <command> [flags]\n\nCommands:\n", programName)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(writer, "  %-10s %s\n", name, commands[name].description)
+	}
+	_, _ = fmt.Fprintf(writer, "\nRun '%s <command> -h' for command flags.\n", programName)
+}
+
+func runStatus(env commandEnv, args []string) error {
+	return withUseCases(env, func(ctx context.Context, useCases useCases) error {
+		status, err := useCases.service.GetStatus(ctx)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(status)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		_, err = fmt.Println(string(data))
+		return errors.WithStack(err)
+	})
+}
+
+func runClear(env commandEnv, confirmed bool, input io.Reader, output io.Writer) error {
+	if !confirmed {
+		_, _ = fmt.Fprintf(output, "All data in database %s on %s will be dropped. Type 'yes' to continue: ",
+			env.cfg.Database.Name, env.cfg.Database.Host)
+
+		answer, err := bufio.NewReader(input).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return errors.WithStack(err)
+		}
+		if strings.TrimSpace(answer) != "yes" {
+			return errors.New("not confirmed")
+		}
+	}
+
+	return withUseCases(env, func(ctx context.Context, useCases useCases) error {
+		if err := useCases.service.DropAllData(ctx); err != nil {
+			return err
+		}
+		env.logger.Println("all data dropped")
+		return nil
+	})
+}
+
+func runRecount(env commandEnv, args []string) error {
+	return withUseCases(env, func(ctx context.Context, useCases useCases) error {
+		if err := useCases.service.RecountCounters(ctx); err != nil {
+			return err
+		}
+		env.logger.Println("forums counters rebuilt")
+		return nil
+	})
+}
+
+func withUseCases(env commandEnv, action func(ctx context.Context, useCases useCases) error) error {
+	if err := requirePostgresStorage(env.cfg); err != nil {
+		return err
+	}
+
+	dbConnPool, err := ConnectToDB(env.cfg.Database, nil)
+	if err != nil {
+		return errors.Wrap(err, "cannot connect to postgres")
+	}
+	defer dbConnPool.Close()
+
+	return action(context.Background(), newUseCases(newPgxRepositories(dbConnPool), env.cfg, events.NopPublisher{}))
+}
+
+// requirePostgresStorage rejects in-memory storage for commands: its data lives only in serving process
+func requirePostgresStorage(cfg config.Config) error {
+	if cfg.Storage.Type != config.PostgresStorage {
+		return errors.Errorf("command requires storage.type %q, got %q", config.PostgresStorage, cfg.Storage.Type)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package db_forum
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
+	authRepository "github.com/nickeskov/db_forum/internal/pkg/auth/repository"
+	authInstrumentedRepository "github.com/nickeskov/db_forum/internal/pkg/auth/repository/instrumented"
+	authMemoryRepository "github.com/nickeskov/db_forum/internal/pkg/auth/repository/memory"
+	authUseCase "github.com/nickeskov/db_forum/internal/pkg/auth/usecase"
+	authInstrumentedUseCase "github.com/nickeskov/db_forum/internal/pkg/auth/usecase/instrumented"
+	"github.com/nickeskov/db_forum/internal/pkg/config"
+	"github.com/nickeskov/db_forum/internal/pkg/events"
+	"github.com/nickeskov/db_forum/internal/pkg/forum"
+	forumRepository "github.com/nickeskov/db_forum/internal/pkg/forum/repository"
+	forumInstrumentedRepository "github.com/nickeskov/db_forum/internal/pkg/forum/repository/instrumented"
+	forumMemoryRepository "github.com/nickeskov/db_forum/internal/pkg/forum/repository/memory"
+	forumUseCase "github.com/nickeskov/db_forum/internal/pkg/forum/usecase"
+	forumInstrumentedUseCase "github.com/nickeskov/db_forum/internal/pkg/forum/usecase/instrumented"
+	"github.com/nickeskov/db_forum/internal/pkg/post"
+	postRepository "github.com/nickeskov/db_forum/internal/pkg/post/repository"
+	postInstrumentedRepository "github.com/nickeskov/db_forum/internal/pkg/post/repository/instrumented"
+	postMemoryRepository "github.com/nickeskov/db_forum/internal/pkg/post/repository/memory"
+	postUseCase "github.com/nickeskov/db_forum/internal/pkg/post/usecase"
+	postInstrumentedUseCase "github.com/nickeskov/db_forum/internal/pkg/post/usecase/instrumented"
+	"github.com/nickeskov/db_forum/internal/pkg/search"
+	searchRepository "github.com/nickeskov/db_forum/internal/pkg/search/repository"
+	searchInstrumentedRepository "github.com/nickeskov/db_forum/internal/pkg/search/repository/instrumented"
+	searchMemoryRepository "github.com/nickeskov/db_forum/internal/pkg/search/repository/memory"
+	searchUseCase "github.com/nickeskov/db_forum/internal/pkg/search/usecase"
+	searchInstrumentedUseCase "github.com/nickeskov/db_forum/internal/pkg/search/usecase/instrumented"
+	"github.com/nickeskov/db_forum/internal/pkg/service"
+	serviceRepository "github.com/nickeskov/db_forum/internal/pkg/service/repository"
+	serviceInstrumentedRepository "github.com/nickeskov/db_forum/internal/pkg/service/repository/instrumented"
+	serviceMemoryRepository "github.com/nickeskov/db_forum/internal/pkg/service/repository/memory"
+	serviceUseCase "github.com/nickeskov/db_forum/internal/pkg/service/usecase"
+	serviceInstrumentedUseCase "github.com/nickeskov/db_forum/internal/pkg/service/usecase/instrumented"
+	"github.com/nickeskov/db_forum/internal/pkg/thread"
+	threadRepository "github.com/nickeskov/db_forum/internal/pkg/thread/repository"
+	threadInstrumentedRepository "github.com/nickeskov/db_forum/internal/pkg/thread/repository/instrumented"
+	threadMemoryRepository "github.com/nickeskov/db_forum/internal/pkg/thread/repository/memory"
+	threadUseCase "github.com/nickeskov/db_forum/internal/pkg/thread/usecase"
+	threadInstrumentedUseCase "github.com/nickeskov/db_forum/internal/pkg/thread/usecase/instrumented"
+	"github.com/nickeskov/db_forum/internal/pkg/user"
+	userRepository "github.com/nickeskov/db_forum/internal/pkg/user/repository"
+	userInstrumentedRepository "github.com/nickeskov/db_forum/internal/pkg/user/repository/instrumented"
+	userMemoryRepository "github.com/nickeskov/db_forum/internal/pkg/user/repository/memory"
+	userUseCase "github.com/nickeskov/db_forum/internal/pkg/user/usecase"
+	userInstrumentedUseCase "github.com/nickeskov/db_forum/internal/pkg/user/usecase/instrumented"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+	"github.com/nickeskov/db_forum/internal/pkg/webhook"
+	webhookRepository "github.com/nickeskov/db_forum/internal/pkg/webhook/repository"
+	webhookInstrumentedRepository "github.com/nickeskov/db_forum/internal/pkg/webhook/repository/instrumented"
+	webhookMemoryRepository "github.com/nickeskov/db_forum/internal/pkg/webhook/repository/memory"
+	webhookUseCase "github.com/nickeskov/db_forum/internal/pkg/webhook/usecase"
+	webhookInstrumentedUseCase "github.com/nickeskov/db_forum/internal/pkg/webhook/usecase/instrumented"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type repositories struct {
+	auth    auth.Repository
+	user    user.Repository
+	forum   forum.Repository
+	thread  thread.Repository
+	post    post.Repository
+	search  search.Repository
+	service service.Repository
+	webhook webhook.Repository
+}
+
+func newPgxRepositories(dbConnPool *pgxpool.Pool) repositories {
+	forumRepo := forumRepository.NewRepository(dbConnPool)
+
+	return repositories{
+		auth:    authRepository.NewRepository(dbConnPool),
+		user:    userRepository.NewRepository(dbConnPool),
+		forum:   forumRepo,
+		thread:  threadRepository.NewRepository(dbConnPool, forumRepo),
+		post:    postRepository.NewRepository(dbConnPool),
+		search:  searchRepository.NewRepository(dbConnPool),
+		service: serviceRepository.NewRepository(dbConnPool),
+		webhook: webhookRepository.NewRepository(dbConnPool),
+	}
+}
+
+func newMemoryRepositories(db *memoryDB.DB) repositories {
+	return repositories{
+		auth:    authMemoryRepository.NewRepository(db),
+		user:    userMemoryRepository.NewRepository(db),
+		forum:   forumMemoryRepository.NewRepository(db),
+		thread:  threadMemoryRepository.NewRepository(db),
+		post:    postMemoryRepository.NewRepository(db),
+		search:  searchMemoryRepository.NewRepository(db),
+		service: serviceMemoryRepository.NewRepository(db),
+		webhook: webhookMemoryRepository.NewRepository(db),
+	}
+}
+
+// instrumentRepositories wraps repositories, so every method is traced and its latency is observed.
+// Durations and tracer may be nil.
+func instrumentRepositories(repos repositories, durations *prometheus.HistogramVec,
+	tracer trace.Tracer) repositories {
+
+	return repositories{
+		auth:    authInstrumentedRepository.NewRepository(repos.auth, durations, tracer),
+		user:    userInstrumentedRepository.NewRepository(repos.user, durations, tracer),
+		forum:   forumInstrumentedRepository.NewRepository(repos.forum, durations, tracer),
+		thread:  threadInstrumentedRepository.NewRepository(repos.thread, durations, tracer),
+		post:    postInstrumentedRepository.NewRepository(repos.post, durations, tracer),
+		search:  searchInstrumentedRepository.NewRepository(repos.search, durations, tracer),
+		service: serviceInstrumentedRepository.NewRepository(repos.service, durations, tracer),
+		webhook: webhookInstrumentedRepository.NewRepository(repos.webhook, durations, tracer),
+	}
+}
+
+type useCases struct {
+	auth    auth.UseCase
+	user    user.UseCase
+	forum   forum.UseCase
+	thread  thread.UseCase
+	post    post.UseCase
+	search  search.UseCase
+	service service.UseCase
+	webhook webhook.UseCase
+}
+
+func newUseCases(repos repositories, cfg config.Config, publisher events.Publisher) useCases {
+	return useCases{
+		auth:    authUseCase.NewUseCase(repos.auth, cfg.Auth.SessionTTL.Duration),
+		user:    userUseCase.NewUseCase(repos.user),
+		forum:   forumUseCase.NewUseCase(repos.forum),
+		thread:  threadUseCase.NewUseCase(repos.thread, repos.forum, publisher),
+		post:    postUseCase.NewUseCase(repos.post, repos.user, repos.forum, repos.thread, publisher),
+		search:  searchUseCase.NewUseCase(repos.search),
+		service: serviceUseCase.NewUseCase(repos.service),
+		webhook: webhookUseCase.NewUseCase(repos.webhook),
+	}
+}
+
+// instrumentUseCases wraps usecases, so every method is traced
+func instrumentUseCases(cases useCases, tracer trace.Tracer) useCases {
+	return useCases{
+		auth:    authInstrumentedUseCase.NewUseCase(cases.auth, tracer),
+		user:    userInstrumentedUseCase.NewUseCase(cases.user, tracer),
+		forum:   forumInstrumentedUseCase.NewUseCase(cases.forum, tracer),
+		thread:  threadInstrumentedUseCase.NewUseCase(cases.thread, tracer),
+		post:    postInstrumentedUseCase.NewUseCase(cases.post, tracer),
+		search:  searchInstrumentedUseCase.NewUseCase(cases.search, tracer),
+		service: serviceInstrumentedUseCase.NewUseCase(cases.service, tracer),
+		webhook: webhookInstrumentedUseCase.NewUseCase(cases.webhook, tracer),
+	}
+}
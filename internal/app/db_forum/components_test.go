@@ -0,0 +1,282 @@
+package db_forum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nickeskov/db_forum/internal/pkg/config"
+	"github.com/nickeskov/db_forum/internal/pkg/events"
+	"github.com/nickeskov/db_forum/internal/pkg/migrations"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/database/contract"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/nickeskov/db_forum/pkg/middleware"
+	"github.com/nickeskov/db_forum/pkg/requestid"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testDSNEnv is postgres connection string for pgx repositories tests, tests are skipped without it.
+// All data in this database is dropped by tests.
+const testDSNEnv = "DB_FORUM_TEST_DSN"
+
+func TestMemoryRepositoriesContract(t *testing.T) {
+	contract.Run(t, func(t *testing.T) contract.Repositories {
+		return toContractRepositories(newMemoryRepositories(memoryDB.NewDB()))
+	})
+}
+
+func TestPgxRepositoriesContract(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	ctx := context.Background()
+
+	dbConnPool, err := pgxpool.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("cannot connect to postgres: %+v", err)
+	}
+	defer dbConnPool.Close()
+
+	allMigrations, err := migrations.Load()
+	if err != nil {
+		t.Fatalf("cannot load migrations: %+v", err)
+	}
+	if _, err := migrations.NewMigrator(dbConnPool, allMigrations).Up(ctx); err != nil {
+		t.Fatalf("cannot apply migrations: %+v", err)
+	}
+
+	repos := newPgxRepositories(dbConnPool)
+
+	contract.Run(t, func(t *testing.T) contract.Repositories {
+		if err := repos.service.DropAllData(ctx); err != nil {
+			t.Fatalf("cannot drop data: %+v", err)
+		}
+		return toContractRepositories(repos)
+	})
+}
+
+func toContractRepositories(repos repositories) contract.Repositories {
+	return contract.Repositories{
+		Auth:    repos.auth,
+		User:    repos.user,
+		Forum:   repos.forum,
+		Thread:  repos.thread,
+		Post:    repos.post,
+		Search:  repos.search,
+		Webhook: repos.webhook,
+	}
+}
+
+func TestTracingSpansOfInstrumentedComponents(t *testing.T) {
+	ctx := context.Background()
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := tracerProvider.Tracer(tracerName)
+
+	repos := instrumentRepositories(newMemoryRepositories(memoryDB.NewDB()), nil, tracer)
+	cases := instrumentUseCases(newUseCases(repos, config.Default(), events.NewBroker(0, 1)), tracer)
+
+	// repositories are called outside of request, so these calls are not traced
+	if err := repos.user.Create(ctx, models.User{Nickname: "author", Email: "author@example.com"}); err != nil {
+		t.Fatalf("cannot create user: %+v", err)
+	}
+	if _, err := repos.forum.Create(ctx, models.Forum{Slug: "forum", Title: "forum", User: "author"}); err != nil {
+		t.Fatalf("cannot create forum: %+v", err)
+	}
+	threadModel, err := repos.thread.Create(ctx, models.Thread{
+		Title: "thread", Author: "author", Forum: "forum", Message: "message", Created: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("cannot create thread: %+v", err)
+	}
+	posts, err := repos.post.CreatePostsInThread(ctx, threadModel, models.Posts{{Author: "author", Message: "post"}})
+	if err != nil {
+		t.Fatalf("cannot create post: %+v", err)
+	}
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	remoteCtx := propagation.TraceContext{}.Extract(ctx, propagation.HeaderCarrier(header))
+	remote := trace.SpanContextFromContext(remoteCtx)
+	requestCtx, requestSpan := tracer.Start(remoteCtx, "request", trace.WithSpanKind(trace.SpanKindServer))
+
+	if _, err := cases.post.GetPostInfoByID(requestCtx, posts[0].ID, []string{"user", "forum", "thread"}); err != nil {
+		t.Fatalf("cannot get post info: %+v", err)
+	}
+	requestSpan.End()
+
+	// shutdown of in-memory exporter drops its spans, so they are only flushed
+	if err := tracerProvider.ForceFlush(ctx); err != nil {
+		t.Fatalf("cannot flush spans: %+v", err)
+	}
+
+	byName := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		if span.SpanContext.TraceID() != remote.TraceID() {
+			t.Fatalf("span %s has trace id %s, expected %s", span.Name, span.SpanContext.TraceID(), remote.TraceID())
+		}
+		byName[span.Name] = span
+	}
+	if len(byName) != 6 {
+		t.Fatalf("expected request, usecase and 4 repository spans, got %v", exporter.GetSpans())
+	}
+
+	useCaseSpan := byName["post.usecase/GetPostInfoByID"]
+	expectedParents := map[string]trace.SpanID{
+		"request":                       remote.SpanID(),
+		"post.usecase/GetPostInfoByID":  requestSpan.SpanContext().SpanID(),
+		"post.repository/GetPostByID":   useCaseSpan.SpanContext.SpanID(),
+		"user.repository/GetByNickname": useCaseSpan.SpanContext.SpanID(),
+		"forum.repository/GetBySlug":    useCaseSpan.SpanContext.SpanID(),
+		"thread.repository/GetByID":     useCaseSpan.SpanContext.SpanID(),
+	}
+	for name, parent := range expectedParents {
+		span, ok := byName[name]
+		if !ok {
+			t.Fatalf("span %s is not exported", name)
+		}
+		if span.Parent.SpanID() != parent {
+			t.Fatalf("span %s has parent %s, expected %s", name, span.Parent.SpanID(), parent)
+		}
+	}
+}
+
+func TestAccessLogOfRequests(t *testing.T) {
+	var output bytes.Buffer
+	customLogger := NewLogger(config.LoggerConfig{Format: config.JsonLoggerFormat, Level: config.InfoLoggerLevel},
+		&output)
+
+	trustedProxies, err := httpUtils.ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("cannot parse trusted proxies: %+v", err)
+	}
+
+	router := mux.NewRouter()
+	// only the first successful request is logged in every second
+	router.Use(middleware.CreateAccessLogMiddleware(customLogger, trustedProxies, 1, 0))
+	router.HandleFunc("/user/{nickname}/profile", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["nickname"] == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte("{}"))
+	})
+
+	for _, nickname := range []string{"missing", "author", "author", "missing"} {
+		request := httptest.NewRequest(http.MethodGet, "/user/"+nickname+"/profile", nil)
+		request.RemoteAddr = "10.0.0.1:4321"
+		request.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7, 192.0.2.1")
+		request.Header.Set("User-Agent", "tester")
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	var statuses []int
+	decoder := json.NewDecoder(&output)
+	for decoder.More() {
+		var entry struct {
+			Msg       string `json:"msg"`
+			ID        string `json:"id"`
+			Method    string `json:"method"`
+			Route     string `json:"route"`
+			Status    int    `json:"status"`
+			Bytes     int    `json:"bytes"`
+			RemoteIP  string `json:"usr_addr"`
+			UserAgent string `json:"user_agent"`
+		}
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatalf("cannot decode log entry: %+v", err)
+		}
+		if entry.Msg != "request ended" {
+			t.Fatalf("unexpected log entry %+v", entry)
+		}
+
+		if entry.ID == "" || entry.Method != http.MethodGet || entry.Route != "/user/{nickname}/profile" ||
+			entry.Bytes != 2 || entry.RemoteIP != "203.0.113.7" || entry.UserAgent != "tester" {
+			t.Fatalf("unexpected access log entry %+v", entry)
+		}
+		statuses = append(statuses, entry.Status)
+	}
+
+	if !reflect.DeepEqual(statuses, []int{http.StatusNotFound, http.StatusOK, http.StatusNotFound}) {
+		t.Fatalf("expected both failed and one sampled successful request, got statuses %v", statuses)
+	}
+}
+
+func TestAccessLogOfTracedRequests(t *testing.T) {
+	var output bytes.Buffer
+	loggerCfg := config.LoggerConfig{Format: config.JsonLoggerFormat, Level: config.InfoLoggerLevel}
+	customLogger := NewLogger(loggerCfg, &output)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	router := mux.NewRouter()
+	useObservingMiddlewares(router, loggerCfg, customLogger, nil, nil, tracerProvider.Tracer(tracerName))
+	router.HandleFunc("/service/status", func(w http.ResponseWriter, r *http.Request) {
+		customLogger.HttpLogInfo(r.Context(), "status requested")
+		_, _ = w.Write([]byte("{}"))
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	for _, requestID := range []string{"", "client-request"} {
+		request := httptest.NewRequest(http.MethodGet, "/service/status", nil)
+		request.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+		if requestID != "" {
+			request.Header.Set(requestid.Header, requestID)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		expectedID := requestID
+		if expectedID == "" {
+			expectedID = traceID // request without request id gets trace id
+		}
+		if responseID := recorder.Header().Get(requestid.Header); responseID != expectedID {
+			t.Fatalf("expected request id %s in response, got %s", expectedID, responseID)
+		}
+	}
+
+	var accessEntries []map[string]interface{}
+	decoder := json.NewDecoder(&output)
+	for decoder.More() {
+		var entry map[string]interface{}
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatalf("cannot decode log entry: %+v", err)
+		}
+		// entries of handler and access log of the same request have the same ids
+		if entry["trace_id"] != traceID {
+			t.Fatalf("log entry has no trace id %s: %v", traceID, entry)
+		}
+		if entry["msg"] == "request ended" {
+			accessEntries = append(accessEntries, entry)
+		}
+	}
+	if len(accessEntries) != 2 || accessEntries[0]["id"] != traceID || accessEntries[1]["id"] != "client-request" {
+		t.Fatalf("unexpected access log entries %v", accessEntries)
+	}
+
+	var requestIDs []string
+	for _, span := range exporter.GetSpans() {
+		for _, attribute := range span.Attributes {
+			if attribute.Key == "request.id" {
+				requestIDs = append(requestIDs, attribute.Value.AsString())
+			}
+		}
+	}
+	if !reflect.DeepEqual(requestIDs, []string{traceID, "client-request"}) {
+		t.Fatalf("expected request ids in attributes of server spans, got %v", requestIDs)
+	}
+}
@@ -0,0 +1,122 @@
+package db_forum
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nickeskov/db_forum/internal/pkg/events"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const metricsNamespace = "db_forum"
+
+// newRegistry creates registry with process and go runtime metrics
+func newRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewGoCollector(),
+	)
+	return registry
+}
+
+// newRepositoryDurations registers histogram of repository methods latency
+func newRepositoryDurations(registerer prometheus.Registerer) *prometheus.HistogramVec {
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "repository_duration_seconds",
+		Help:      "Latency of repository methods.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"repository", "method"})
+	registerer.MustRegister(durations)
+	return durations
+}
+
+// poolCollector exposes stats of database connection pool, they are read on every scrape
+type poolCollector struct {
+	dbConnPool *pgxpool.Pool
+
+	acquiredConns      *prometheus.Desc
+	idleConns          *prometheus.Desc
+	totalConns         *prometheus.Desc
+	maxConns           *prometheus.Desc
+	acquires           *prometheus.Desc
+	emptyAcquires      *prometheus.Desc
+	canceledAcquires   *prometheus.Desc
+	acquireWaitSeconds *prometheus.Desc
+}
+
+func newPoolCollector(dbConnPool *pgxpool.Pool) poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db_pool", name), help, nil, nil)
+	}
+
+	return poolCollector{
+		dbConnPool:    dbConnPool,
+		acquiredConns: desc("acquired_conns", "Connections currently acquired from pool."),
+		idleConns:     desc("idle_conns", "Idle connections in pool."),
+		totalConns:    desc("total_conns", "All connections in pool."),
+		maxConns:      desc("max_conns", "Max size of pool."),
+		acquires:      desc("acquires_total", "Count of successful acquires."),
+		emptyAcquires: desc("empty_acquires_total",
+			"Count of acquires which waited for connection because pool was empty."),
+		canceledAcquires:   desc("canceled_acquires_total", "Count of acquires cancelled by context."),
+		acquireWaitSeconds: desc("acquire_wait_seconds_total", "Total time spent by successful acquires."),
+	}
+}
+
+func (collector poolCollector) Describe(descs chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(collector, descs)
+}
+
+func (collector poolCollector) Collect(metrics chan<- prometheus.Metric) {
+	stat := collector.dbConnPool.Stat()
+
+	for desc, value := range map[*prometheus.Desc]float64{
+		collector.acquiredConns: float64(stat.AcquiredConns()),
+		collector.idleConns:     float64(stat.IdleConns()),
+		collector.totalConns:    float64(stat.TotalConns()),
+		collector.maxConns:      float64(stat.MaxConns()),
+	} {
+		metrics <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+
+	for desc, value := range map[*prometheus.Desc]float64{
+		collector.acquires:           float64(stat.AcquireCount()),
+		collector.emptyAcquires:      float64(stat.EmptyAcquireCount()),
+		collector.canceledAcquires:   float64(stat.CanceledAcquireCount()),
+		collector.acquireWaitSeconds: stat.AcquireDuration().Seconds(),
+	} {
+		metrics <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+}
+
+// countingPublisher counts published events as business metrics and passes them to publisher
+type countingPublisher struct {
+	publisher events.Publisher
+	counters  map[string]prometheus.Counter
+}
+
+func newCountingPublisher(publisher events.Publisher, registerer prometheus.Registerer) countingPublisher {
+	counter := func(name, help string) prometheus.Counter {
+		counter := prometheus.NewCounter(prometheus.CounterOpts{Namespace: metricsNamespace, Name: name, Help: help})
+		registerer.MustRegister(counter)
+		return counter
+	}
+
+	return countingPublisher{
+		publisher: publisher,
+		counters: map[string]prometheus.Counter{
+			events.ThreadCreatedEvent: counter("threads_created_total", "Count of created threads."),
+			events.PostCreatedEvent:   counter("posts_created_total", "Count of created posts."),
+			events.PostUpdatedEvent:   counter("posts_edited_total", "Count of post edits."),
+			events.VoteEvent:          counter("votes_total", "Count of votes, changed votes are counted again."),
+		},
+	}
+}
+
+func (publisher countingPublisher) Publish(eventType string, payload interface{}, topics ...string) {
+	if counter, ok := publisher.counters[eventType]; ok {
+		counter.Inc()
+	}
+	publisher.publisher.Publish(eventType, payload, topics...)
+}
@@ -0,0 +1,101 @@
+package db_forum
+
+import (
+	"context"
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/config"
+	"github.com/nickeskov/db_forum/internal/pkg/migrations"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+	MigrateRedo   = "redo"
+)
+
+func migrate(cfg config.Config, customLogger logger.SimpleLogger, action string) error {
+	switch action {
+	case MigrateUp, MigrateDown, MigrateStatus, MigrateRedo:
+	default:
+		return errors.Errorf("unknown migrate action %q, expected one of: %s, %s, %s, %s",
+			action, MigrateUp, MigrateDown, MigrateStatus, MigrateRedo)
+	}
+
+	if err := requirePostgresStorage(cfg); err != nil {
+		return err
+	}
+
+	allMigrations, err := migrations.Load()
+	if err != nil {
+		return err
+	}
+
+	dbConnPool, err := ConnectToDB(cfg.Database, nil)
+	if err != nil {
+		return errors.Wrap(err, "cannot connect to postgres")
+	}
+	defer dbConnPool.Close()
+
+	migrator := migrations.NewMigrator(dbConnPool, allMigrations)
+	ctx := context.Background()
+
+	switch action {
+	case MigrateUp:
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			customLogger.Printf("applied migration %d_%s", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			customLogger.Println("schema is up to date")
+		}
+		return err
+
+	case MigrateDown, MigrateRedo:
+		var migration *migrations.Migration
+		if action == MigrateDown {
+			migration, err = migrator.Down(ctx)
+		} else {
+			migration, err = migrator.Redo(ctx)
+		}
+		switch {
+		case err != nil:
+			return err
+		case migration == nil:
+			customLogger.Println("there are no applied migrations")
+		default:
+			customLogger.Printf("%s migration %d_%s done", action, migration.Version, migration.Name)
+		}
+		return nil
+
+	case MigrateStatus:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printMigrationStatuses(os.Stdout, statuses)
+	}
+
+	return nil
+}
+
+func printMigrationStatuses(writer io.Writer, statuses migrations.Statuses) error {
+	tabWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tabWriter, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(tabWriter, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return tabWriter.Flush()
+}
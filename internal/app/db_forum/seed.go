@@ -0,0 +1,151 @@
+package db_forum
+
+import (
+	"context"
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/pkg/errors"
+	"math/rand"
+	"strconv"
+)
+
+const seedPostsBatchSize = 100
+
+type seedConfig struct {
+	Users           int
+	Forums          int
+	ThreadsPerForum int
+	PostsPerThread  int
+	RandomSeed      int64
+}
+
+func defaultSeedConfig() seedConfig {
+	return seedConfig{
+		Users:           100,
+		Forums:          10,
+		ThreadsPerForum: 10,
+		PostsPerThread:  20,
+		RandomSeed:      1,
+	}
+}
+
+// runSeed creates users, forums, threads, posts and votes through use cases.
+// Entity names depend only on seed config, so already existing entities are skipped on repeated run.
+func runSeed(env commandEnv, seedCfg seedConfig) error {
+	if seedCfg.Users <= 0 || seedCfg.Forums < 0 || seedCfg.ThreadsPerForum < 0 || seedCfg.PostsPerThread < 0 {
+		return errors.Errorf("bad seed config %+v: users must be positive, other counts not negative", seedCfg)
+	}
+
+	return withUseCases(env, func(ctx context.Context, useCases useCases) error {
+		random := rand.New(rand.NewSource(seedCfg.RandomSeed))
+		prefix := "seed" + strconv.FormatInt(seedCfg.RandomSeed, 10)
+
+		nicknames := make([]string, 0, seedCfg.Users)
+		for i := 0; i < seedCfg.Users; i++ {
+			nickname := fmt.Sprintf("%s_user_%d", prefix, i)
+			err := useCases.user.Create(ctx, models.User{
+				Nickname: nickname,
+				Email:    nickname + "@seed.example.com",
+				Fullname: fmt.Sprintf("Seed User %d", i),
+				About:    "generated by seed command",
+			})
+			if err != nil && !errors.Is(err, models.ErrAlreadyExist) {
+				return errors.Wrapf(err, "cannot create user %s", nickname)
+			}
+			nicknames = append(nicknames, nickname)
+		}
+
+		var threadsCount, postsCount int
+		for i := 0; i < seedCfg.Forums; i++ {
+			forumSlug := fmt.Sprintf("%s-forum-%d", prefix, i)
+			_, err := useCases.forum.Create(ctx, models.Forum{
+				Slug:  forumSlug,
+				Title: fmt.Sprintf("Seed forum %d", i),
+				User:  nicknames[random.Intn(len(nicknames))],
+			})
+			if err != nil && !errors.Is(err, models.ErrConflict) {
+				return errors.Wrapf(err, "cannot create forum %s", forumSlug)
+			}
+
+			for j := 0; j < seedCfg.ThreadsPerForum; j++ {
+				created, err := seedThread(ctx, useCases, random, seedCfg, nicknames,
+					forumSlug, fmt.Sprintf("%s-thread-%d", forumSlug, j))
+				if err != nil {
+					return err
+				}
+				if created {
+					threadsCount++
+					postsCount += seedCfg.PostsPerThread
+				}
+			}
+		}
+
+		env.logger.Printf("seed done: %d users, %d forums, %d new threads, %d new posts",
+			seedCfg.Users, seedCfg.Forums, threadsCount, postsCount)
+
+		return nil
+	})
+}
+
+func seedThread(ctx context.Context, useCases useCases, random *rand.Rand, seedCfg seedConfig,
+	nicknames []string, forumSlug, threadSlug string) (bool, error) {
+
+	createdThread, err := useCases.thread.Create(ctx, models.Thread{
+		Slug:    threadSlug,
+		Forum:   forumSlug,
+		Author:  nicknames[random.Intn(len(nicknames))],
+		Title:   "Seed thread " + threadSlug,
+		Message: "generated by seed command",
+	})
+	switch {
+	case errors.Is(err, models.ErrConflict):
+		return false, nil
+	case err != nil:
+		return false, errors.Wrapf(err, "cannot create thread %s", threadSlug)
+	}
+
+	threadID := strconv.Itoa(int(createdThread.ID))
+
+	postIDs := make([]int64, 0, seedCfg.PostsPerThread)
+	for created := 0; created < seedCfg.PostsPerThread; {
+		batchSize := seedCfg.PostsPerThread - created
+		if batchSize > seedPostsBatchSize {
+			batchSize = seedPostsBatchSize
+		}
+
+		posts := make(models.Posts, 0, batchSize)
+		for k := 0; k < batchSize; k++ {
+			var parent int64
+			if len(postIDs) > 0 && random.Intn(2) == 0 {
+				parent = postIDs[random.Intn(len(postIDs))]
+			}
+			posts = append(posts, models.Post{
+				Author:  nicknames[random.Intn(len(nicknames))],
+				Message: fmt.Sprintf("seed post %d", created+k),
+				Parent:  parent,
+			})
+		}
+
+		createdPosts, err := useCases.post.CreatePostsByThreadSlugOrID(ctx, threadID, posts)
+		if err != nil {
+			return false, errors.Wrapf(err, "cannot create posts in thread %s", threadSlug)
+		}
+		for _, createdPost := range createdPosts {
+			postIDs = append(postIDs, createdPost.ID)
+		}
+
+		created += batchSize
+	}
+
+	for _, nickname := range nicknames {
+		if random.Intn(4) != 0 {
+			continue
+		}
+		vote := models.Vote{Nickname: nickname, Voice: int16(1 - 2*random.Intn(2))}
+		if _, err := useCases.thread.VoteBySlugOrID(ctx, threadID, vote); err != nil {
+			return false, errors.Wrapf(err, "cannot vote for thread %s", threadSlug)
+		}
+	}
+
+	return true, nil
+}
@@ -2,15 +2,101 @@ package db_forum
 
 import (
 	"context"
-	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nickeskov/db_forum/internal/pkg/config"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"github.com/nickeskov/db_forum/pkg/middleware"
+	"github.com/nickeskov/db_forum/pkg/requestid"
+	pgx4Helpers "github.com/nickeskov/db_forum/pkg/sql/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 )
 
-func ConnectToDB(dbHost, dbName, dbUser, dbPassword string, dbMaxConns int) (*pgxpool.Pool, error) {
-	connStr := fmt.Sprintf(
-		"host=%s dbname=%s user=%s password=%s pool_max_conns=%d",
-		dbHost, dbName, dbUser, dbPassword, dbMaxConns,
-	)
+// ConnectToDB creates pool, queries are traced when tracer is not nil
+func ConnectToDB(cfg config.DatabaseConfig, tracer trace.Tracer) (*pgxpool.Pool, error) {
+	connURL := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   net.JoinHostPort(cfg.Host, strconv.Itoa(int(cfg.Port))),
+		Path:   cfg.Name,
+	}
 
-	return pgxpool.Connect(context.Background(), connStr)
+	poolConfig, err := pgxpool.ParseConfig(connURL.String())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod.Duration
+
+	if cfg.StatementTimeout.Duration > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] =
+			strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	poolConfig.ConnConfig.RuntimeParams["application_name"] = cfg.ApplicationName
+	if cfg.TagQueries {
+		poolConfig.BeforeAcquire = pgx4Helpers.TagSessionWithRequestID(cfg.ApplicationName)
+	}
+
+	if tracer != nil {
+		poolConfig.ConnConfig.Logger = pgx4Helpers.NewTracingLogger(tracer)
+		poolConfig.ConnConfig.LogLevel = pgx.LogLevelInfo
+	}
+
+	return pgxpool.ConnectConfig(context.Background(), poolConfig)
+}
+
+func NewLogger(cfg config.LoggerConfig, writer io.Writer) logger.SimpleLogger {
+	var customLogger logger.SimpleLogger
+	if cfg.Format == config.JsonLoggerFormat {
+		customLogger = logger.NewJsonFormatSimpleLogger(writer, requestid.ContextKey{})
+	} else {
+		customLogger = logger.NewTextFormatSimpleLogger(writer, requestid.ContextKey{})
+	}
+
+	// level is validated by config
+	if level, err := logrus.ParseLevel(cfg.Level); err == nil {
+		customLogger.SetLevel(level)
+	}
+	return customLogger
+}
+
+func checkRouteKeys(router *mux.Router, routeSettings map[string]time.Duration) error {
+	registeredKeys := make(map[string]bool)
+
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		pathTemplate, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			registeredKeys[middleware.RouteKey(method, pathTemplate)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for key := range routeSettings {
+		if !registeredKeys[key] {
+			return errors.Errorf("route %q is not registered", key)
+		}
+	}
+
+	return nil
 }
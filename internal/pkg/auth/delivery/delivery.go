@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"github.com/nickeskov/db_forum/pkg/middleware"
+	"net/http"
+)
+
+type Delivery struct {
+	useCase auth.UseCase
+	utils   httpUtils.Utils
+}
+
+func NewDelivery(useCase auth.UseCase, logger logger.Logger) Delivery {
+	return Delivery{
+		useCase: useCase,
+		utils:   httpUtils.NewDeliveryUtils(logger),
+	}
+}
+
+func (delivery Delivery) Login(w http.ResponseWriter, r *http.Request) {
+	data, err := delivery.utils.ReadAllDataFromBody(w, r)
+	if err != nil {
+		return
+	}
+
+	var credentials models.Credentials
+
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	session, err := delivery.useCase.Login(r.Context(), credentials)
+	switch {
+	case errors.Is(err, models.ErrAccessDenied):
+		delivery.utils.WriteResponseError(w, r, http.StatusUnauthorized, "invalid nickname or password")
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(session)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+func (delivery Delivery) Logout(w http.ResponseWriter, r *http.Request) {
+	token, ok := middleware.BearerToken(r)
+	if !ok {
+		delivery.utils.WriteResponseError(w, r, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	err := delivery.useCase.Logout(r.Context(), token)
+	switch {
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		delivery.utils.WriteResponse(w, r, http.StatusOK, nil)
+	}
+}
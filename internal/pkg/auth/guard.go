@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/nickeskov/db_forum/pkg/middleware"
+	"net/http"
+	"strings"
+)
+
+// ForumRoleGetter is satisfied by forum.UseCase
+type ForumRoleGetter interface {
+	GetRole(ctx context.Context, slug, nickname string) (models.ForumRole, error)
+}
+
+// ContentAction is kind of change of forum content checked by CheckContentAction
+type ContentAction int
+
+const (
+	// EditAction is allowed to author and moderators, disabled guard allows it to anyone
+	EditAction ContentAction = iota
+	// DeleteAction is allowed to author and moderators with session even if guard is disabled
+	DeleteAction
+	// ModerateAction is allowed only to moderators with session even if guard is disabled
+	ModerateAction
+)
+
+// Guard checks that caller authenticated by auth middleware acts on behalf of himself
+// or has forum role which allows the action.
+// Disabled guard allows actions which API had before authentication was added, so old clients keep working.
+// Moderation, role management and webhooks came with authentication and always require session.
+type Guard struct {
+	enabled bool
+	roles   ForumRoleGetter
+}
+
+func NewGuard(enabled bool, roles ForumRoleGetter) Guard {
+	return Guard{
+		enabled: enabled,
+		roles:   roles,
+	}
+}
+
+func (guard Guard) Enabled() bool {
+	return guard.enabled
+}
+
+// CheckCaller returns ErrUnauthorized for anonymous caller and ErrAccessDenied if caller is not nickname.
+// Nicknames are compared case insensitive like citext columns do.
+func (guard Guard) CheckCaller(ctx context.Context, nickname string) error {
+	if !guard.enabled {
+		return nil
+	}
+
+	return guard.RequireCaller(ctx, nickname)
+}
+
+// RequireCaller is CheckCaller which is enforced by disabled guard too,
+// it protects actions which were never allowed to anonymous callers, e.g. password change
+func (guard Guard) RequireCaller(ctx context.Context, nickname string) error {
+	caller, ok := middleware.AuthenticatedNickname(ctx)
+	switch {
+	case !ok:
+		return models.ErrUnauthorized
+	case !strings.EqualFold(caller, nickname):
+		return models.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// CheckForumEditor allows caller to edit content of author if caller is author himself
+// or owner or moderator of forum
+func (guard Guard) CheckForumEditor(ctx context.Context, forumSlug, author string) error {
+	if !guard.enabled {
+		return nil
+	}
+
+	return guard.RequireForumEditor(ctx, forumSlug, author)
+}
+
+// RequireForumEditor is CheckForumEditor which is enforced by disabled guard too
+func (guard Guard) RequireForumEditor(ctx context.Context, forumSlug, author string) error {
+	err := guard.RequireCaller(ctx, author)
+	if !errors.Is(err, models.ErrAccessDenied) {
+		return err
+	}
+
+	callerRole, err := guard.callerForumRole(ctx, forumSlug)
+	switch {
+	case err != nil:
+		return err
+	case !callerRole.IsModerator():
+		return models.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// CheckForumModerator allows action only to owner or moderators of forum, it is enforced by disabled guard too
+func (guard Guard) CheckForumModerator(ctx context.Context, forumSlug string) error {
+	callerRole, err := guard.callerForumRole(ctx, forumSlug)
+	switch {
+	case err != nil:
+		return err
+	case !callerRole.IsModerator():
+		return models.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// CheckForumOwner allows action only to owner of forum, it is enforced by disabled guard too
+func (guard Guard) CheckForumOwner(ctx context.Context, forumSlug string) error {
+	callerRole, err := guard.callerForumRole(ctx, forumSlug)
+	switch {
+	case err != nil:
+		return err
+	case callerRole.Role != models.OwnerForumRole:
+		return models.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// CheckRoleManager allows caller to grant or revoke roles if his forum role can manage all of them,
+// it is enforced by disabled guard too
+func (guard Guard) CheckRoleManager(ctx context.Context, forumSlug string, roles ...string) error {
+	callerRole, err := guard.callerForumRole(ctx, forumSlug)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		if !callerRole.CanManage(role) {
+			return models.ErrAccessDenied
+		}
+	}
+
+	return nil
+}
+
+// ChecksAction reports whether action is checked, disabled guard checks only actions added with authentication
+func (guard Guard) ChecksAction(action ContentAction) bool {
+	return guard.enabled || action != EditAction
+}
+
+// CheckContentAction checks that caller may do action with content of author in forum
+func (guard Guard) CheckContentAction(ctx context.Context, action ContentAction, forumSlug, author string) error {
+	switch action {
+	case EditAction:
+		return guard.CheckForumEditor(ctx, forumSlug, author)
+	case DeleteAction:
+		return guard.RequireForumEditor(ctx, forumSlug, author)
+	default:
+		return guard.CheckForumModerator(ctx, forumSlug)
+	}
+}
+
+// callerForumRole returns role of caller in forum, role is empty if caller has no role
+func (guard Guard) callerForumRole(ctx context.Context, forumSlug string) (models.ForumRole, error) {
+	caller, ok := middleware.AuthenticatedNickname(ctx)
+	if !ok {
+		return models.ForumRole{}, models.ErrUnauthorized
+	}
+
+	role, err := guard.roles.GetRole(ctx, forumSlug, caller)
+	if err != nil && !errors.Is(err, models.ErrDoesNotExist) {
+		return models.ForumRole{}, err
+	}
+
+	return role, nil
+}
+
+// WriteAccessError writes response for error returned by Guard checks
+func WriteAccessError(utils httpUtils.Utils, w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, models.ErrUnauthorized):
+		utils.WriteResponseError(w, r, http.StatusUnauthorized, "authentication required")
+
+	case errors.Is(err, models.ErrAccessDenied):
+		caller, _ := middleware.AuthenticatedNickname(r.Context())
+		utils.WriteResponseError(w, r, http.StatusForbidden,
+			fmt.Sprintf("access denied for user with nickname=%s", caller))
+
+	default:
+		utils.WriteResponseError(w, r, http.StatusInternalServerError, fmt.Sprintf("%+v", err))
+	}
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/pkg/middleware"
+	"strings"
+	"testing"
+)
+
+var errRolesUnavailable = errors.New("roles are unavailable")
+
+// testRoles are roles of forum "go", forum "broken" fails role lookup
+type testRoles map[string]string
+
+func (roles testRoles) GetRole(_ context.Context, slug, nickname string) (models.ForumRole, error) {
+	if slug == "broken" {
+		return models.ForumRole{}, errRolesUnavailable
+	}
+
+	role, ok := roles[strings.ToLower(nickname)]
+	if !ok || !strings.EqualFold(slug, "go") {
+		return models.ForumRole{}, models.ErrDoesNotExist
+	}
+	return models.ForumRole{Forum: slug, Nickname: nickname, Role: role}, nil
+}
+
+func TestGuard(t *testing.T) {
+	roles := testRoles{
+		"owner":     models.OwnerForumRole,
+		"moderator": models.ModeratorForumRole,
+		"member":    models.MemberForumRole,
+	}
+
+	callerCheck := func(guard Guard, ctx context.Context) error {
+		return guard.CheckCaller(ctx, "john")
+	}
+	requireCaller := func(guard Guard, ctx context.Context) error {
+		return guard.RequireCaller(ctx, "john")
+	}
+	contentAction := func(action ContentAction, forum string) func(Guard, context.Context) error {
+		return func(guard Guard, ctx context.Context) error {
+			return guard.CheckContentAction(ctx, action, forum, "john")
+		}
+	}
+	roleManager := func(roles ...string) func(Guard, context.Context) error {
+		return func(guard Guard, ctx context.Context) error {
+			return guard.CheckRoleManager(ctx, "go", roles...)
+		}
+	}
+	forumOwner := func(guard Guard, ctx context.Context) error {
+		return guard.CheckForumOwner(ctx, "go")
+	}
+
+	cases := []struct {
+		name     string
+		enabled  bool
+		caller   string // empty caller is anonymous
+		check    func(guard Guard, ctx context.Context) error
+		expected error
+	}{
+		{"CallerIsSelf", true, "JOHN", callerCheck, nil},
+		{"CallerIsOther", true, "bob", callerCheck, models.ErrAccessDenied},
+		{"CallerIsAnonymous", true, "", callerCheck, models.ErrUnauthorized},
+		{"DisabledCallerIsAnonymous", false, "", callerCheck, nil},
+		{"DisabledCallerIsOther", false, "bob", callerCheck, nil},
+		{"DisabledRequireCallerIsAnonymous", false, "", requireCaller, models.ErrUnauthorized},
+		{"DisabledRequireCallerIsOther", false, "bob", requireCaller, models.ErrAccessDenied},
+		{"DisabledRequireCallerIsSelf", false, "john", requireCaller, nil},
+
+		{"EditByAuthor", true, "john", contentAction(EditAction, "go"), nil},
+		{"EditByModerator", true, "moderator", contentAction(EditAction, "go"), nil},
+		{"EditByOwner", true, "owner", contentAction(EditAction, "go"), nil},
+		{"EditByMember", true, "member", contentAction(EditAction, "go"), models.ErrAccessDenied},
+		{"EditByModeratorOfOtherForum", true, "moderator", contentAction(EditAction, "rust"),
+			models.ErrAccessDenied},
+		{"EditByAnonymous", true, "", contentAction(EditAction, "go"), models.ErrUnauthorized},
+		{"EditWithBrokenRoles", true, "bob", contentAction(EditAction, "broken"), errRolesUnavailable},
+		{"DisabledEditByAnonymous", false, "", contentAction(EditAction, "go"), nil},
+
+		{"DisabledDeleteByAnonymous", false, "", contentAction(DeleteAction, "go"), models.ErrUnauthorized},
+		{"DisabledDeleteByMember", false, "member", contentAction(DeleteAction, "go"), models.ErrAccessDenied},
+		{"DisabledDeleteByAuthor", false, "john", contentAction(DeleteAction, "go"), nil},
+		{"DisabledDeleteByModerator", false, "moderator", contentAction(DeleteAction, "go"), nil},
+
+		// author without moderator role can't moderate own content
+		{"ModerateByAuthor", true, "john", contentAction(ModerateAction, "go"), models.ErrAccessDenied},
+		{"ModerateByModerator", true, "moderator", contentAction(ModerateAction, "go"), nil},
+		{"DisabledModerateByAnonymous", false, "", contentAction(ModerateAction, "go"), models.ErrUnauthorized},
+		{"DisabledModerateByModerator", false, "moderator", contentAction(ModerateAction, "go"), nil},
+
+		{"OwnerCheckByOwner", true, "owner", forumOwner, nil},
+		{"OwnerCheckByModerator", true, "moderator", forumOwner, models.ErrAccessDenied},
+		{"DisabledOwnerCheckByAnonymous", false, "", forumOwner, models.ErrUnauthorized},
+
+		{"OwnerManagesModerators", true, "owner", roleManager(models.ModeratorForumRole), nil},
+		{"ModeratorManagesMembers", true, "moderator",
+			roleManager(models.MemberForumRole, models.BannedForumRole), nil},
+		{"ModeratorDoesNotManageModerators", true, "moderator",
+			roleManager(models.MemberForumRole, models.ModeratorForumRole), models.ErrAccessDenied},
+		{"NobodyManagesOwners", true, "owner", roleManager(models.OwnerForumRole), models.ErrAccessDenied},
+		{"MemberDoesNotManageRoles", true, "member", roleManager(models.BannedForumRole), models.ErrAccessDenied},
+		{"DisabledManagerIsAnonymous", false, "", roleManager(models.MemberForumRole), models.ErrUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.caller != "" {
+				ctx = middleware.WithAuthenticatedNickname(ctx, tc.caller)
+			}
+
+			err := tc.check(NewGuard(tc.enabled, roles), ctx)
+			switch {
+			case tc.expected == nil && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tc.expected != nil && !errors.Is(err, tc.expected):
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestGuardChecksAction(t *testing.T) {
+	cases := []struct {
+		enabled bool
+		action  ContentAction
+		checked bool
+	}{
+		{true, EditAction, true},
+		{true, DeleteAction, true},
+		{true, ModerateAction, true},
+		{false, EditAction, false},
+		{false, DeleteAction, true},
+		{false, ModerateAction, true},
+	}
+
+	for _, tc := range cases {
+		if checked := NewGuard(tc.enabled, testRoles{}).ChecksAction(tc.action); checked != tc.checked {
+			t.Errorf("guard enabled %v, action %d: expected checked %v, got %v",
+				tc.enabled, tc.action, tc.checked, checked)
+		}
+	}
+}
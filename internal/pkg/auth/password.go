@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(hash), nil
+}
+
+// dummyHash is bcrypt hash with default cost of password which is never used
+const dummyHash = "$2a$10$LOpGutwQcHuEx9E8IVKQW.nBDi4uJyyd/W8oG5PkobEPd77oYu7tu"
+
+// CheckPassword reports whether password matches hash, empty hash matches nothing.
+// Empty hash costs the same time as a real one, so response time doesn't tell whether user has password.
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		RejectPassword(password)
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// RejectPassword spends the same time as CheckPassword, it is called for unknown user,
+// so response time doesn't tell whether user exists
+func RejectPassword(password string) {
+	_ = bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(password))
+}
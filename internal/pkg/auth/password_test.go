@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("cannot hash password: %+v", err)
+	}
+	if hash == "correct horse" || !strings.HasPrefix(hash, "$2a$") {
+		t.Fatalf("password is not hashed by bcrypt: %s", hash)
+	}
+
+	otherHash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("cannot hash password: %+v", err)
+	}
+	if otherHash == hash {
+		t.Fatalf("hashes of the same password are equal, salt is not used")
+	}
+
+	cases := []struct {
+		name     string
+		hash     string
+		password string
+		matches  bool
+	}{
+		{"Correct", hash, "correct horse", true},
+		{"Wrong", hash, "battery staple", false},
+		{"CaseSensitive", hash, "Correct horse", false},
+		{"Empty", hash, "", false},
+		// user registered without password can't login with any password
+		{"EmptyHash", "", "", false},
+		{"EmptyHashWithPassword", "", "correct horse", false},
+		{"DummyHash", dummyHash, "correct horse", false},
+	}
+
+	for _, tc := range cases {
+		if matches := CheckPassword(tc.hash, tc.password); matches != tc.matches {
+			t.Errorf("%s: expected match %v, got %v", tc.name, tc.matches, matches)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	// GetPasswordHash returns canonical nickname and password hash of user,
+	// hash is empty if user was registered without password
+	GetPasswordHash(ctx context.Context, nickname string) (storedNickname, passwordHash string, err error)
+	// CreateSession stores session and removes expired sessions of the same user
+	CreateSession(ctx context.Context, tokenHash []byte, nickname string, expires time.Time) error
+	// GetSessionNickname returns nickname of session which is not expired at now
+	GetSessionNickname(ctx context.Context, tokenHash []byte, now time.Time) (string, error)
+	DeleteSession(ctx context.Context, tokenHash []byte) error
+}
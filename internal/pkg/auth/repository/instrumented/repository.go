@@ -0,0 +1,59 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const repositoryName = "auth"
+
+// Repository traces every method of wrapped repository and observes its latency
+type Repository struct {
+	repository auth.Repository
+	observer   instrumentation.Observer
+}
+
+// NewRepository wraps repository, durations must have repository and method labels.
+// Durations and tracer may be nil.
+func NewRepository(repository auth.Repository, durations *prometheus.HistogramVec,
+	tracer trace.Tracer) Repository {
+
+	return Repository{
+		repository: repository,
+		observer:   instrumentation.NewObserver(repositoryName, "repository", durations, tracer),
+	}
+}
+
+func (repo Repository) GetPasswordHash(ctx context.Context,
+	nickname string) (storedNickname, passwordHash string, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetPasswordHash")
+	defer func() { done(err) }()
+	return repo.repository.GetPasswordHash(ctx, nickname)
+}
+
+func (repo Repository) CreateSession(ctx context.Context,
+	tokenHash []byte, nickname string, expires time.Time) (err error) {
+
+	ctx, done := repo.observer.Start(ctx, "CreateSession")
+	defer func() { done(err) }()
+	return repo.repository.CreateSession(ctx, tokenHash, nickname, expires)
+}
+
+func (repo Repository) GetSessionNickname(ctx context.Context,
+	tokenHash []byte, now time.Time) (_ string, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetSessionNickname")
+	defer func() { done(err) }()
+	return repo.repository.GetSessionNickname(ctx, tokenHash, now)
+}
+
+func (repo Repository) DeleteSession(ctx context.Context, tokenHash []byte) (err error) {
+	ctx, done := repo.observer.Start(ctx, "DeleteSession")
+	defer func() { done(err) }()
+	return repo.repository.DeleteSession(ctx, tokenHash)
+}
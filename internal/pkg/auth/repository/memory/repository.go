@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+	"time"
+)
+
+type Repository struct {
+	db *memoryDB.DB
+}
+
+func NewRepository(db *memoryDB.DB) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) GetPasswordHash(ctx context.Context,
+	nickname string) (storedNickname, passwordHash string, err error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return "", "", err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	nicknameKey := memoryDB.CIKey(nickname)
+
+	stored, ok := repo.db.UsersByNickname[nicknameKey]
+	if !ok {
+		return "", "", models.ErrDoesNotExist
+	}
+
+	return stored.Nickname, repo.db.PasswordHashes[nicknameKey], nil
+}
+
+func (repo Repository) CreateSession(ctx context.Context, tokenHash []byte,
+	nickname string, expires time.Time) error {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	stored, ok := repo.db.UsersByNickname[memoryDB.CIKey(nickname)]
+	if !ok {
+		return models.ErrDoesNotExist
+	}
+
+	now := time.Now()
+	for key, session := range repo.db.Sessions {
+		if session.Nickname == stored.Nickname && !session.Expires.After(now) {
+			delete(repo.db.Sessions, key)
+		}
+	}
+
+	repo.db.Sessions[string(tokenHash)] = memoryDB.Session{
+		Nickname: stored.Nickname,
+		Expires:  memoryDB.Timestamp(expires),
+	}
+
+	return nil
+}
+
+func (repo Repository) GetSessionNickname(ctx context.Context, tokenHash []byte, now time.Time) (string, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return "", err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	session, ok := repo.db.Sessions[string(tokenHash)]
+	if !ok || !session.Expires.After(now) {
+		return "", models.ErrDoesNotExist
+	}
+
+	return session.Nickname, nil
+}
+
+func (repo Repository) DeleteSession(ctx context.Context, tokenHash []byte) error {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	if _, ok := repo.db.Sessions[string(tokenHash)]; !ok {
+		return models.ErrDoesNotExist
+	}
+
+	delete(repo.db.Sessions, string(tokenHash))
+
+	return nil
+}
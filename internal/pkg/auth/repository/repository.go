@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/database/driver/pgx/codes"
+	pgx4Helpers "github.com/nickeskov/db_forum/pkg/sql/pgx/v4"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) GetPasswordHash(ctx context.Context,
+	nickname string) (storedNickname, passwordHash string, err error) {
+
+	err = repo.db.QueryRow(ctx, `
+			SELECT nickname, COALESCE(password_hash, '')
+			FROM users
+			WHERE nickname = $1`,
+		nickname,
+	).Scan(
+		&storedNickname,
+		&passwordHash,
+	)
+
+	switch {
+	case err == pgx.ErrNoRows:
+		return "", "", models.ErrDoesNotExist
+	case err != nil:
+		return "", "", errors.Wrapf(err,
+			"some error while getting password hash of user with nickname=%s", nickname)
+	}
+
+	return storedNickname, passwordHash, nil
+}
+
+func (repo Repository) CreateSession(ctx context.Context, tokenHash []byte,
+	nickname string, expires time.Time) (err error) {
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		err = pgx4Helpers.FinishPgx4Transaction(ctx, tx, err)
+	}()
+
+	_, err = tx.Exec(ctx, `
+			DELETE FROM sessions
+			WHERE user_nickname = $1 AND expires <= CURRENT_TIMESTAMP`,
+		nickname,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "some error while removing expired sessions of user with nickname=%s", nickname)
+	}
+
+	_, err = tx.Exec(ctx, `
+			INSERT INTO sessions (token_hash, user_nickname, expires)
+			VALUES ($1, $2, $3)`,
+		tokenHash,
+		nickname,
+		expires,
+	)
+
+	if pgxErr := codes.ExtractPgx4ErrorCode(err); pgxErr != nil {
+		switch pgxErr.Error() {
+		case codes.ErrCodeForeignKey:
+			return models.ErrDoesNotExist // user does not exist
+		default:
+			return errors.Wrapf(err, "some error while creating session of user with nickname=%s", nickname)
+		}
+	}
+
+	return errors.WithStack(err)
+}
+
+func (repo Repository) GetSessionNickname(ctx context.Context, tokenHash []byte, now time.Time) (string, error) {
+	var nickname string
+
+	err := repo.db.QueryRow(ctx, `
+			SELECT user_nickname
+			FROM sessions
+			WHERE token_hash = $1 AND expires > $2`,
+		tokenHash,
+		now,
+	).Scan(
+		&nickname,
+	)
+
+	switch {
+	case err == pgx.ErrNoRows:
+		return "", models.ErrDoesNotExist
+	case err != nil:
+		return "", errors.Wrap(err, "some error while getting session")
+	}
+
+	return nickname, nil
+}
+
+func (repo Repository) DeleteSession(ctx context.Context, tokenHash []byte) error {
+	tag, err := repo.db.Exec(ctx, `DELETE FROM sessions WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return errors.Wrap(err, "some error while deleting session")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return models.ErrDoesNotExist
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/pkg/errors"
+)
+
+const tokenSize = 32
+
+// NewToken returns random session token and its hash, only hash is stored in repository
+func NewToken() (token string, tokenHash []byte, err error) {
+	data := make([]byte, tokenSize)
+	if _, err := rand.Read(data); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(data)
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestNewToken(t *testing.T) {
+	tokens := make(map[string]bool)
+
+	for i := 0; i < 100; i++ {
+		token, tokenHash, err := NewToken()
+		if err != nil {
+			t.Fatalf("cannot create token: %+v", err)
+		}
+
+		data, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil || len(data) != tokenSize {
+			t.Fatalf("token %q is not %d random bytes in base64: %v", token, tokenSize, err)
+		}
+		if !bytes.Equal(tokenHash, HashToken(token)) {
+			t.Fatalf("hash of token %q differs from HashToken", token)
+		}
+		if tokens[token] {
+			t.Fatalf("token %q is repeated", token)
+		}
+		tokens[token] = true
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	cases := []struct {
+		name  string
+		token string
+		other string
+		equal bool
+	}{
+		{"Same", "token", "token", true},
+		{"Different", "token", "token2", false},
+		{"CaseSensitive", "token", "Token", false},
+		{"Empty", "", "token", false},
+	}
+
+	for _, tc := range cases {
+		hash := HashToken(tc.token)
+		if len(hash) != 32 {
+			t.Errorf("%s: expected sha256 hash, got %d bytes", tc.name, len(hash))
+		}
+		if equal := bytes.Equal(hash, HashToken(tc.other)); equal != tc.equal {
+			t.Errorf("%s: expected equal hashes %v, got %v", tc.name, tc.equal, equal)
+		}
+		if bytes.Equal(hash, []byte(tc.token)) {
+			t.Errorf("%s: token is stored as is", tc.name)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package auth
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+)
+
+type UseCase interface {
+	Login(ctx context.Context, credentials models.Credentials) (models.Session, error)
+	// Logout ends session, unknown or expired token is treated as already logged out
+	Logout(ctx context.Context, token string) error
+	// Authenticate returns nickname of session owner or ErrUnauthorized for unknown or expired token
+	Authenticate(ctx context.Context, token string) (string, error)
+}
@@ -0,0 +1,42 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const useCaseName = "auth"
+
+// UseCase traces every method of wrapped usecase
+type UseCase struct {
+	useCase  auth.UseCase
+	observer instrumentation.Observer
+}
+
+func NewUseCase(useCase auth.UseCase, tracer trace.Tracer) UseCase {
+	return UseCase{
+		useCase:  useCase,
+		observer: instrumentation.NewObserver(useCaseName, "usecase", nil, tracer),
+	}
+}
+
+func (useCase UseCase) Login(ctx context.Context, credentials models.Credentials) (_ models.Session, err error) {
+	ctx, done := useCase.observer.Start(ctx, "Login")
+	defer func() { done(err) }()
+	return useCase.useCase.Login(ctx, credentials)
+}
+
+func (useCase UseCase) Logout(ctx context.Context, token string) (err error) {
+	ctx, done := useCase.observer.Start(ctx, "Logout")
+	defer func() { done(err) }()
+	return useCase.useCase.Logout(ctx, token)
+}
+
+func (useCase UseCase) Authenticate(ctx context.Context, token string) (_ string, err error) {
+	ctx, done := useCase.observer.Start(ctx, "Authenticate")
+	defer func() { done(err) }()
+	return useCase.useCase.Authenticate(ctx, token)
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type UseCase struct {
+	repository auth.Repository
+	sessionTTL time.Duration
+}
+
+func NewUseCase(repository auth.Repository, sessionTTL time.Duration) UseCase {
+	return UseCase{
+		repository: repository,
+		sessionTTL: sessionTTL,
+	}
+}
+
+func (useCase UseCase) Login(ctx context.Context, credentials models.Credentials) (models.Session, error) {
+	nickname, passwordHash, err := useCase.repository.GetPasswordHash(ctx, credentials.Nickname)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		auth.RejectPassword(credentials.Password)
+		return models.Session{}, models.ErrAccessDenied // don't tell whether user exists
+	case err != nil:
+		return models.Session{}, err
+	}
+
+	if !auth.CheckPassword(passwordHash, credentials.Password) {
+		return models.Session{}, models.ErrAccessDenied
+	}
+
+	token, tokenHash, err := auth.NewToken()
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	session := models.Session{
+		Token:    token,
+		Nickname: nickname,
+		Expires:  time.Now().Add(useCase.sessionTTL).UTC().Round(time.Millisecond),
+	}
+
+	if err := useCase.repository.CreateSession(ctx, tokenHash, session.Nickname, session.Expires); err != nil {
+		return models.Session{}, err
+	}
+
+	return session, nil
+}
+
+func (useCase UseCase) Logout(ctx context.Context, token string) error {
+	err := useCase.repository.DeleteSession(ctx, auth.HashToken(token))
+	if errors.Is(err, models.ErrDoesNotExist) {
+		return nil // unknown or expired session is already ended
+	}
+	return err
+}
+
+func (useCase UseCase) Authenticate(ctx context.Context, token string) (string, error) {
+	nickname, err := useCase.repository.GetSessionNickname(ctx, auth.HashToken(token), time.Now())
+	if errors.Is(err, models.ErrDoesNotExist) {
+		return "", models.ErrUnauthorized
+	}
+	return nickname, err
+}
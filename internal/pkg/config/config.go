@@ -0,0 +1,379 @@
+package config
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	TextLoggerFormat = "text"
+	JsonLoggerFormat = "json"
+
+	DebugLoggerLevel = "debug"
+	InfoLoggerLevel  = "info"
+	WarnLoggerLevel  = "warn"
+	ErrorLoggerLevel = "error"
+
+	PostgresStorage = "postgres"
+	MemoryStorage   = "memory"
+
+	NoneTracingExporter   = "none"
+	StdoutTracingExporter = "stdout"
+	OTLPTracingExporter   = "otlp"
+)
+
+type Config struct {
+	Server   ServerConfig   `yaml:"server" toml:"server"`
+	Storage  StorageConfig  `yaml:"storage" toml:"storage"`
+	Database DatabaseConfig `yaml:"database" toml:"database"`
+	Logger   LoggerConfig   `yaml:"logger" toml:"logger"`
+	Admin    AdminConfig    `yaml:"admin" toml:"admin"`
+	Auth     AuthConfig     `yaml:"auth" toml:"auth"`
+
+	Pagination PaginationConfig `yaml:"pagination" toml:"pagination"`
+	Events     EventsConfig     `yaml:"events" toml:"events"`
+	Webhooks   WebhooksConfig   `yaml:"webhooks" toml:"webhooks"`
+	Metrics    MetricsConfig    `yaml:"metrics" toml:"metrics"`
+	Health     HealthConfig     `yaml:"health" toml:"health"`
+	Tracing    TracingConfig    `yaml:"tracing" toml:"tracing"`
+}
+
+type ServerConfig struct {
+	Address           string   `yaml:"address" toml:"address"`
+	ReadTimeout       Duration `yaml:"read_timeout" toml:"read_timeout"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	WriteTimeout      Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	MaxHeaderBytes    int32    `yaml:"max_header_bytes" toml:"max_header_bytes"`
+	ShutdownTimeout   Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+
+	// QueryTimeout is deadline for database queries of every request, 0 disables it
+	QueryTimeout Duration `yaml:"query_timeout" toml:"query_timeout"`
+	// RouteQueryTimeouts overrides QueryTimeout for routes, key is "METHOD /path/template"
+	RouteQueryTimeouts map[string]Duration `yaml:"route_query_timeouts" toml:"route_query_timeouts"`
+	// TrustedProxies are IPs or CIDRs of reverse proxies, client address is taken from X-Forwarded-For
+	// only for requests from them
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+}
+
+type StorageConfig struct {
+	// Type selects repositories backend: postgres or memory, memory data is lost on restart
+	Type string `yaml:"type" toml:"type"`
+}
+
+type DatabaseConfig struct {
+	Host              string   `yaml:"host" toml:"host"`
+	Port              uint16   `yaml:"port" toml:"port"`
+	Name              string   `yaml:"name" toml:"name"`
+	User              string   `yaml:"user" toml:"user"`
+	Password          string   `yaml:"password" toml:"password"`
+	MaxConns          int32    `yaml:"max_conns" toml:"max_conns"`
+	MinConns          int32    `yaml:"min_conns" toml:"min_conns"`
+	HealthCheckPeriod Duration `yaml:"health_check_period" toml:"health_check_period"`
+	StatementTimeout  Duration `yaml:"statement_timeout" toml:"statement_timeout"`
+	ApplicationName   string   `yaml:"application_name" toml:"application_name"`
+	// TagQueries appends request id to application_name of connection while it is used by request,
+	// it costs extra round trip per connection acquire
+	TagQueries bool `yaml:"tag_queries" toml:"tag_queries"`
+}
+
+type LoggerConfig struct {
+	Format string `yaml:"format" toml:"format"`
+	Level  string `yaml:"level" toml:"level"`
+	// AccessSampleInitial is number of successful requests logged every second before sampling, 0 logs all of them
+	AccessSampleInitial int32 `yaml:"access_sample_initial" toml:"access_sample_initial"`
+	// AccessSampleThereafter is period of logged successful requests after AccessSampleInitial, 0 drops all of them
+	AccessSampleThereafter int32 `yaml:"access_sample_thereafter" toml:"access_sample_thereafter"`
+}
+
+type AdminConfig struct {
+	// Token is required in X-Admin-Token header of admin routes, empty token disables them
+	Token string `yaml:"token" toml:"token"`
+}
+
+type AuthConfig struct {
+	// Enabled requires bearer session token for writes on behalf of user, it is off by default for old clients
+	Enabled    bool     `yaml:"enabled" toml:"enabled"`
+	SessionTTL Duration `yaml:"session_ttl" toml:"session_ttl"`
+}
+
+type PaginationConfig struct {
+	// CursorSecret signs cursor tokens of list endpoints, random secret is generated on start if it is empty
+	CursorSecret string `yaml:"cursor_secret" toml:"cursor_secret"`
+}
+
+type EventsConfig struct {
+	// HistorySize is number of recent events of all threads kept for Last-Event-ID resume
+	HistorySize int32 `yaml:"history_size" toml:"history_size"`
+	// SubscriberBuffer is number of unsent events after which slow subscriber is disconnected
+	SubscriberBuffer int32    `yaml:"subscriber_buffer" toml:"subscriber_buffer"`
+	Heartbeat        Duration `yaml:"heartbeat" toml:"heartbeat"`
+}
+
+type WebhooksConfig struct {
+	// PollPeriod is period of due deliveries lookup, 0 disables sending of webhooks by this instance
+	PollPeriod     Duration `yaml:"poll_period" toml:"poll_period"`
+	BatchSize      int32    `yaml:"batch_size" toml:"batch_size"`
+	RequestTimeout Duration `yaml:"request_timeout" toml:"request_timeout"`
+	// MaxAttempts is number of attempts after which delivery is failed
+	MaxAttempts int32 `yaml:"max_attempts" toml:"max_attempts"`
+	// BackoffBase is delay after the first failed attempt, it doubles after every next one up to BackoffMax
+	BackoffBase Duration `yaml:"backoff_base" toml:"backoff_base"`
+	BackoffMax  Duration `yaml:"backoff_max" toml:"backoff_max"`
+}
+
+type MetricsConfig struct {
+	// Enabled exposes GET /metrics in Prometheus text format and instruments routes and repositories
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+}
+
+type HealthConfig struct {
+	// CheckTimeout limits every dependency check of readiness probe
+	CheckTimeout Duration `yaml:"check_timeout" toml:"check_timeout"`
+}
+
+type TracingConfig struct {
+	// Exporter is none, stdout or otlp, none disables tracing
+	Exporter string `yaml:"exporter" toml:"exporter"`
+	// OTLPEndpoint is URL of OTLP/HTTP traces receiver of collector
+	OTLPEndpoint string `yaml:"otlp_endpoint" toml:"otlp_endpoint"`
+	ServiceName  string `yaml:"service_name" toml:"service_name"`
+	// SampleRatio is share of recorded traces, requests with sampled traceparent are always recorded
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+// Duration is time.Duration which can be decoded from strings like "1m30s"
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) Set(value string) error {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	return d.Set(string(text))
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Address:           ":5000",
+			ReadTimeout:       Duration{10 * time.Second},
+			ReadHeaderTimeout: Duration{5 * time.Second},
+			WriteTimeout:      Duration{30 * time.Second},
+			IdleTimeout:       Duration{2 * time.Minute},
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   Duration{15 * time.Second},
+			QueryTimeout:      Duration{10 * time.Second},
+		},
+		Storage: StorageConfig{
+			Type: PostgresStorage,
+		},
+		Database: DatabaseConfig{
+			Host:              "localhost",
+			Port:              5432,
+			Name:              "my_db_forum",
+			User:              "my_db_forum",
+			Password:          "my_db_forum",
+			MaxConns:          10,
+			MinConns:          0,
+			HealthCheckPeriod: Duration{time.Minute},
+			StatementTimeout:  Duration{0},
+			ApplicationName:   "db_forum",
+		},
+		Logger: LoggerConfig{
+			Format:                 TextLoggerFormat,
+			Level:                  InfoLoggerLevel,
+			AccessSampleInitial:    100,
+			AccessSampleThereafter: 10,
+		},
+		Auth: AuthConfig{
+			Enabled:    false,
+			SessionTTL: Duration{30 * 24 * time.Hour},
+		},
+		Events: EventsConfig{
+			HistorySize:      1024,
+			SubscriberBuffer: 64,
+			Heartbeat:        Duration{15 * time.Second},
+		},
+		Webhooks: WebhooksConfig{
+			PollPeriod:     Duration{time.Second},
+			BatchSize:      100,
+			RequestTimeout: Duration{10 * time.Second},
+			MaxAttempts:    8,
+			BackoffBase:    Duration{10 * time.Second},
+			BackoffMax:     Duration{time.Hour},
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+		},
+		Health: HealthConfig{
+			CheckTimeout: Duration{2 * time.Second},
+		},
+		Tracing: TracingConfig{
+			Exporter:     NoneTracingExporter,
+			OTLPEndpoint: "http://localhost:4318/v1/traces",
+			ServiceName:  "db_forum",
+			SampleRatio:  1,
+		},
+	}
+}
+
+func (cfg Config) Validate() error {
+	switch {
+	case cfg.Server.Address == "":
+		return errors.New("server.address must not be empty")
+	case cfg.Server.ReadTimeout.Duration < 0,
+		cfg.Server.ReadHeaderTimeout.Duration < 0,
+		cfg.Server.WriteTimeout.Duration < 0,
+		cfg.Server.IdleTimeout.Duration < 0:
+		return errors.New("server timeouts must not be negative")
+	case cfg.Server.MaxHeaderBytes <= 0:
+		return errors.Errorf("server.max_header_bytes must be positive, got %d", cfg.Server.MaxHeaderBytes)
+	case cfg.Server.ShutdownTimeout.Duration <= 0:
+		return errors.Errorf("server.shutdown_timeout must be positive, got %s", cfg.Server.ShutdownTimeout)
+	case cfg.Server.QueryTimeout.Duration < 0:
+		return errors.Errorf("server.query_timeout must not be negative, got %s", cfg.Server.QueryTimeout)
+	case cfg.Database.Host == "":
+		return errors.New("database.host must not be empty")
+	case cfg.Database.Port == 0:
+		return errors.New("database.port must not be zero")
+	case cfg.Database.Name == "":
+		return errors.New("database.name must not be empty")
+	case cfg.Database.User == "":
+		return errors.New("database.user must not be empty")
+	case cfg.Database.MaxConns <= 0:
+		return errors.Errorf("database.max_conns must be positive, got %d", cfg.Database.MaxConns)
+	case cfg.Database.MinConns < 0 || cfg.Database.MinConns > cfg.Database.MaxConns:
+		return errors.Errorf("database.min_conns must be in range [0, %d], got %d",
+			cfg.Database.MaxConns, cfg.Database.MinConns)
+	case cfg.Database.HealthCheckPeriod.Duration <= 0:
+		return errors.Errorf("database.health_check_period must be positive, got %s",
+			cfg.Database.HealthCheckPeriod)
+	case cfg.Database.StatementTimeout.Duration < 0:
+		return errors.Errorf("database.statement_timeout must not be negative, got %s",
+			cfg.Database.StatementTimeout)
+	case cfg.Database.ApplicationName == "":
+		return errors.New("database.application_name must not be empty")
+	case cfg.Logger.AccessSampleInitial < 0:
+		return errors.Errorf("logger.access_sample_initial must not be negative, got %d",
+			cfg.Logger.AccessSampleInitial)
+	case cfg.Logger.AccessSampleThereafter < 0:
+		return errors.Errorf("logger.access_sample_thereafter must not be negative, got %d",
+			cfg.Logger.AccessSampleThereafter)
+	case cfg.Auth.SessionTTL.Duration <= 0:
+		return errors.Errorf("auth.session_ttl must be positive, got %s", cfg.Auth.SessionTTL)
+	case cfg.Events.HistorySize < 0:
+		return errors.Errorf("events.history_size must not be negative, got %d", cfg.Events.HistorySize)
+	case cfg.Events.SubscriberBuffer <= 0:
+		return errors.Errorf("events.subscriber_buffer must be positive, got %d", cfg.Events.SubscriberBuffer)
+	case cfg.Events.Heartbeat.Duration <= 0:
+		return errors.Errorf("events.heartbeat must be positive, got %s", cfg.Events.Heartbeat)
+	case cfg.Webhooks.PollPeriod.Duration < 0:
+		return errors.Errorf("webhooks.poll_period must not be negative, got %s", cfg.Webhooks.PollPeriod)
+	case cfg.Webhooks.BatchSize <= 0:
+		return errors.Errorf("webhooks.batch_size must be positive, got %d", cfg.Webhooks.BatchSize)
+	case cfg.Webhooks.RequestTimeout.Duration <= 0:
+		return errors.Errorf("webhooks.request_timeout must be positive, got %s", cfg.Webhooks.RequestTimeout)
+	case cfg.Webhooks.MaxAttempts <= 0:
+		return errors.Errorf("webhooks.max_attempts must be positive, got %d", cfg.Webhooks.MaxAttempts)
+	case cfg.Webhooks.BackoffBase.Duration <= 0:
+		return errors.Errorf("webhooks.backoff_base must be positive, got %s", cfg.Webhooks.BackoffBase)
+	case cfg.Webhooks.BackoffMax.Duration < cfg.Webhooks.BackoffBase.Duration:
+		return errors.Errorf("webhooks.backoff_max must not be less than webhooks.backoff_base, got %s",
+			cfg.Webhooks.BackoffMax)
+	case cfg.Health.CheckTimeout.Duration <= 0:
+		return errors.Errorf("health.check_timeout must be positive, got %s", cfg.Health.CheckTimeout)
+	case cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1:
+		return errors.Errorf("tracing.sample_ratio must be in range [0, 1], got %g", cfg.Tracing.SampleRatio)
+	case cfg.Tracing.ServiceName == "":
+		return errors.New("tracing.service_name must not be empty")
+	}
+
+	for route, timeout := range cfg.Server.RouteQueryTimeouts {
+		if timeout.Duration < 0 {
+			return errors.Errorf("server.route_query_timeouts[%s] must not be negative, got %s", route, timeout)
+		}
+	}
+
+	for _, proxy := range cfg.Server.TrustedProxies {
+		if net.ParseIP(proxy) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			return errors.Errorf("server.trusted_proxies must contain IPs or CIDRs, got %q", proxy)
+		}
+	}
+
+	switch cfg.Storage.Type {
+	case PostgresStorage, MemoryStorage:
+	default:
+		return errors.Errorf("storage.type must be %q or %q, got %q",
+			PostgresStorage, MemoryStorage, cfg.Storage.Type)
+	}
+
+	switch cfg.Tracing.Exporter {
+	case NoneTracingExporter, StdoutTracingExporter:
+	case OTLPTracingExporter:
+		if cfg.Tracing.OTLPEndpoint == "" {
+			return errors.New("tracing.otlp_endpoint must not be empty with otlp exporter")
+		}
+	default:
+		return errors.Errorf("tracing.exporter must be %q, %q or %q, got %q",
+			NoneTracingExporter, StdoutTracingExporter, OTLPTracingExporter, cfg.Tracing.Exporter)
+	}
+
+	switch cfg.Logger.Format {
+	case TextLoggerFormat, JsonLoggerFormat:
+	default:
+		return errors.Errorf("logger.format must be %q or %q, got %q",
+			TextLoggerFormat, JsonLoggerFormat, cfg.Logger.Format)
+	}
+
+	switch cfg.Logger.Level {
+	case DebugLoggerLevel, InfoLoggerLevel, WarnLoggerLevel, ErrorLoggerLevel:
+	default:
+		return errors.Errorf("logger.level must be %q, %q, %q or %q, got %q",
+			DebugLoggerLevel, InfoLoggerLevel, WarnLoggerLevel, ErrorLoggerLevel, cfg.Logger.Level)
+	}
+
+	return nil
+}
+
+// String returns effective config in "key=value" lines with secrets redacted
+func (cfg Config) String() string {
+	var builder strings.Builder
+	for _, opt := range cfg.options() {
+		value := opt.value.String()
+		if opt.secret && value != "" {
+			value = redactedValue
+		}
+		_, _ = fmt.Fprintf(&builder, "%s=%s\n", opt.name, value)
+	}
+
+	routes := make([]string, 0, len(cfg.Server.RouteQueryTimeouts))
+	for route := range cfg.Server.RouteQueryTimeouts {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	for _, route := range routes {
+		_, _ = fmt.Fprintf(&builder, "server.route_query_timeouts[%s]=%s\n",
+			route, cfg.Server.RouteQueryTimeouts[route])
+	}
+	return builder.String()
+}
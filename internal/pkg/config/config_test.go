@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{"Default", func(cfg *Config) {}, ""},
+		{"MemoryStorage", func(cfg *Config) { cfg.Storage.Type = MemoryStorage }, ""},
+		{"EmptyAddress", func(cfg *Config) { cfg.Server.Address = "" }, "server.address"},
+		{"NegativeTimeout", func(cfg *Config) { cfg.Server.WriteTimeout = Duration{-time.Second} }, "server timeouts"},
+		{"ZeroShutdownTimeout", func(cfg *Config) { cfg.Server.ShutdownTimeout = Duration{} }, "server.shutdown_timeout"},
+		{"NegativeRouteQueryTimeout", func(cfg *Config) {
+			cfg.Server.RouteQueryTimeouts = map[string]Duration{"GET /api/service/status": {-time.Second}}
+		}, "server.route_query_timeouts"},
+		{"TrustedProxies", func(cfg *Config) { cfg.Server.TrustedProxies = []string{"10.0.0.1", "fd00::/8"} }, ""},
+		{"InvalidTrustedProxy", func(cfg *Config) { cfg.Server.TrustedProxies = []string{"proxy.local"} },
+			"server.trusted_proxies"},
+		{"MinConnsOverMax", func(cfg *Config) { cfg.Database.MinConns = cfg.Database.MaxConns + 1 },
+			"database.min_conns"},
+		{"ZeroPort", func(cfg *Config) { cfg.Database.Port = 0 }, "database.port"},
+		{"UnknownStorage", func(cfg *Config) { cfg.Storage.Type = "mysql" }, "storage.type"},
+		{"UnknownLoggerFormat", func(cfg *Config) { cfg.Logger.Format = "xml" }, "logger.format"},
+		{"UnknownLoggerLevel", func(cfg *Config) { cfg.Logger.Level = "trace" }, "logger.level"},
+		{"BackoffMaxUnderBase", func(cfg *Config) { cfg.Webhooks.BackoffMax = Duration{time.Second} },
+			"webhooks.backoff_max"},
+		{"DisabledWebhooks", func(cfg *Config) { cfg.Webhooks.PollPeriod = Duration{} }, ""},
+		{"SampleRatioOverOne", func(cfg *Config) { cfg.Tracing.SampleRatio = 1.5 }, "tracing.sample_ratio"},
+		{"OTLPWithoutEndpoint", func(cfg *Config) {
+			cfg.Tracing.Exporter = OTLPTracingExporter
+			cfg.Tracing.OTLPEndpoint = ""
+		}, "tracing.otlp_endpoint"},
+		{"UnknownTracingExporter", func(cfg *Config) { cfg.Tracing.Exporter = "jaeger" }, "tracing.exporter"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Default()
+			tc.modify(&cfg)
+
+			err := cfg.Validate()
+			switch {
+			case tc.err == "" && err != nil:
+				t.Errorf("unexpected error: %+v", err)
+			case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+				t.Errorf("expected error with %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
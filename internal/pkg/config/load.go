@@ -0,0 +1,305 @@
+package config
+
+import (
+	"flag"
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	EnvPrefix     = "DB_FORUM_"
+	ConfigFileEnv = EnvPrefix + "CONFIG"
+
+	configFileFlag = "config"
+	redactedValue  = "******"
+)
+
+type option struct {
+	name   string
+	usage  string
+	secret bool
+	value  flag.Value
+}
+
+func (cfg *Config) options() []option {
+	return []option{
+		{name: "server.address", usage: "http server listen address", value: (*stringValue)(&cfg.Server.Address)},
+		{name: "server.read_timeout", usage: "max duration for reading entire request, 0 disables it",
+			value: &cfg.Server.ReadTimeout},
+		{name: "server.read_header_timeout", usage: "max duration for reading request headers, 0 disables it",
+			value: &cfg.Server.ReadHeaderTimeout},
+		{name: "server.write_timeout", usage: "max duration before timing out writes of response, 0 disables it",
+			value: &cfg.Server.WriteTimeout},
+		{name: "server.idle_timeout", usage: "max duration to wait for the next request on keep-alive connection",
+			value: &cfg.Server.IdleTimeout},
+		{name: "server.max_header_bytes", usage: "max size of request headers in bytes",
+			value: (*int32Value)(&cfg.Server.MaxHeaderBytes)},
+		{name: "server.query_timeout", usage: "deadline for database queries of request, 0 disables it",
+			value: &cfg.Server.QueryTimeout},
+		{name: "server.shutdown_timeout", usage: "deadline for draining in-flight requests on shutdown",
+			value: &cfg.Server.ShutdownTimeout},
+		{name: "server.trusted_proxies", usage: "comma separated IPs or CIDRs of proxies trusted to set X-Forwarded-For",
+			value: (*stringListValue)(&cfg.Server.TrustedProxies)},
+
+		{name: "storage.type", usage: "repositories backend: postgres or memory",
+			value: (*stringValue)(&cfg.Storage.Type)},
+
+		{name: "database.host", usage: "postgres host", value: (*stringValue)(&cfg.Database.Host)},
+		{name: "database.port", usage: "postgres port", value: (*uint16Value)(&cfg.Database.Port)},
+		{name: "database.name", usage: "postgres database name", value: (*stringValue)(&cfg.Database.Name)},
+		{name: "database.user", usage: "postgres user", value: (*stringValue)(&cfg.Database.User)},
+		{name: "database.password", usage: "postgres password", secret: true,
+			value: (*stringValue)(&cfg.Database.Password)},
+		{name: "database.max_conns", usage: "max connections in pool",
+			value: (*int32Value)(&cfg.Database.MaxConns)},
+		{name: "database.min_conns", usage: "min connections in pool",
+			value: (*int32Value)(&cfg.Database.MinConns)},
+		{name: "database.health_check_period", usage: "period of idle connections health check",
+			value: &cfg.Database.HealthCheckPeriod},
+		{name: "database.statement_timeout", usage: "postgres statement_timeout, 0 disables it",
+			value: &cfg.Database.StatementTimeout},
+		{name: "database.application_name", usage: "application_name of database connections",
+			value: (*stringValue)(&cfg.Database.ApplicationName)},
+		{name: "database.tag_queries", usage: "append request id to application_name while connection is used",
+			value: (*boolValue)(&cfg.Database.TagQueries)},
+
+		{name: "logger.format", usage: "logger format: text or json", value: (*stringValue)(&cfg.Logger.Format)},
+		{name: "logger.level", usage: "min logged level: debug, info, warn or error",
+			value: (*stringValue)(&cfg.Logger.Level)},
+		{name: "logger.access_sample_initial", usage: "successful requests logged every second before sampling, 0 logs all",
+			value: (*int32Value)(&cfg.Logger.AccessSampleInitial)},
+		{name: "logger.access_sample_thereafter", usage: "log every n-th successful request after initial ones, 0 logs none",
+			value: (*int32Value)(&cfg.Logger.AccessSampleThereafter)},
+
+		{name: "admin.token", usage: "token of admin routes, empty token disables them", secret: true,
+			value: (*stringValue)(&cfg.Admin.Token)},
+
+		{name: "auth.enabled", usage: "require session token for writes on behalf of user",
+			value: (*boolValue)(&cfg.Auth.Enabled)},
+		{name: "auth.session_ttl", usage: "lifetime of session token issued by login",
+			value: &cfg.Auth.SessionTTL},
+
+		{name: "pagination.cursor_secret", usage: "secret of cursor tokens, random if empty", secret: true,
+			value: (*stringValue)(&cfg.Pagination.CursorSecret)},
+
+		{name: "events.history_size", usage: "number of recent thread events kept for stream resume",
+			value: (*int32Value)(&cfg.Events.HistorySize)},
+		{name: "events.subscriber_buffer", usage: "unsent events after which slow stream is closed",
+			value: (*int32Value)(&cfg.Events.SubscriberBuffer)},
+		{name: "events.heartbeat", usage: "period of keep-alive comments in thread streams",
+			value: &cfg.Events.Heartbeat},
+
+		{name: "webhooks.poll_period", usage: "period of due webhook deliveries lookup, 0 disables sending",
+			value: &cfg.Webhooks.PollPeriod},
+		{name: "webhooks.batch_size", usage: "max webhook deliveries sent per poll",
+			value: (*int32Value)(&cfg.Webhooks.BatchSize)},
+		{name: "webhooks.request_timeout", usage: "timeout of webhook delivery request",
+			value: &cfg.Webhooks.RequestTimeout},
+		{name: "webhooks.max_attempts", usage: "attempts after which webhook delivery is failed",
+			value: (*int32Value)(&cfg.Webhooks.MaxAttempts)},
+		{name: "webhooks.backoff_base", usage: "retry delay after the first failed webhook attempt",
+			value: &cfg.Webhooks.BackoffBase},
+		{name: "webhooks.backoff_max", usage: "max retry delay of webhook delivery",
+			value: &cfg.Webhooks.BackoffMax},
+
+		{name: "metrics.enabled", usage: "expose prometheus metrics on /metrics",
+			value: (*boolValue)(&cfg.Metrics.Enabled)},
+
+		{name: "health.check_timeout", usage: "timeout of every dependency check of /readyz",
+			value: &cfg.Health.CheckTimeout},
+
+		{name: "tracing.exporter", usage: "traces exporter: none, stdout or otlp",
+			value: (*stringValue)(&cfg.Tracing.Exporter)},
+		{name: "tracing.otlp_endpoint", usage: "URL of OTLP/HTTP traces receiver",
+			value: (*stringValue)(&cfg.Tracing.OTLPEndpoint)},
+		{name: "tracing.service_name", usage: "service name of exported spans",
+			value: (*stringValue)(&cfg.Tracing.ServiceName)},
+		{name: "tracing.sample_ratio", usage: "share of recorded traces in range [0, 1]",
+			value: (*float64Value)(&cfg.Tracing.SampleRatio)},
+	}
+}
+
+// EnvName returns environment variable name for option name, e.g. database.max_conns -> DB_FORUM_DATABASE_MAX_CONNS
+func EnvName(optionName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(optionName, ".", "_"))
+}
+
+// Load builds config from defaults, config file, environment variables and command line flags.
+// Every next source overrides previous one. Config file path is taken from -config flag or DB_FORUM_CONFIG env.
+// Config flags are registered in flagSet, so caller can add its own flags and use positional arguments.
+func Load(flagSet *flag.FlagSet, args []string) (Config, error) {
+	cfg := Default()
+
+	configFile := flagSet.String(configFileFlag, os.Getenv(ConfigFileEnv), "path to yaml or toml config file")
+
+	options := cfg.options()
+	flagValues := make(map[string]string, len(options))
+	for _, opt := range options {
+		usage := opt.usage + " (env " + EnvName(opt.name)
+		if !opt.secret {
+			usage += ", default " + strconv.Quote(opt.value.String())
+		}
+		flagSet.Var(&recordedValue{name: opt.name, values: flagValues}, opt.name, usage+")")
+	}
+
+	if err := flagSet.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, opt := range options {
+		if envValue, ok := os.LookupEnv(EnvName(opt.name)); ok {
+			if err := opt.value.Set(envValue); err != nil {
+				return Config{}, errors.Wrapf(err, "invalid value of env %s", EnvName(opt.name))
+			}
+		}
+	}
+
+	for _, opt := range options {
+		if flagValue, ok := flagValues[opt.name]; ok {
+			if err := opt.value.Set(flagValue); err != nil {
+				return Config{}, errors.Wrapf(err, "invalid value of flag -%s", opt.name)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, errors.Wrap(err, "invalid config")
+	}
+
+	return cfg, nil
+}
+
+func (cfg *Config) loadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "cannot read config file %s", path)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, cfg)
+	case ".toml":
+		_, err = toml.Decode(string(data), cfg)
+	default:
+		return errors.Errorf("unsupported config file extension %q", ext)
+	}
+
+	return errors.Wrapf(err, "cannot parse config file %s", path)
+}
+
+type recordedValue struct {
+	name   string
+	values map[string]string
+}
+
+func (v *recordedValue) String() string {
+	if v.values == nil {
+		return ""
+	}
+	return v.values[v.name]
+}
+
+func (v *recordedValue) Set(value string) error {
+	v.values[v.name] = value
+	return nil
+}
+
+type stringValue string
+
+func (v *stringValue) String() string {
+	return string(*v)
+}
+
+func (v *stringValue) Set(value string) error {
+	*v = stringValue(value)
+	return nil
+}
+
+type int32Value int32
+
+func (v *int32Value) String() string {
+	return strconv.FormatInt(int64(*v), 10)
+}
+
+func (v *int32Value) Set(value string) error {
+	parsed, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return err
+	}
+	*v = int32Value(parsed)
+	return nil
+}
+
+type uint16Value uint16
+
+func (v *uint16Value) String() string {
+	return strconv.FormatUint(uint64(*v), 10)
+}
+
+func (v *uint16Value) Set(value string) error {
+	parsed, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return err
+	}
+	*v = uint16Value(parsed)
+	return nil
+}
+
+type boolValue bool
+
+func (v *boolValue) String() string {
+	return strconv.FormatBool(bool(*v))
+}
+
+func (v *boolValue) Set(value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*v = boolValue(parsed)
+	return nil
+}
+
+type float64Value float64
+
+func (v *float64Value) String() string {
+	return strconv.FormatFloat(float64(*v), 'g', -1, 64)
+}
+
+func (v *float64Value) Set(value string) error {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	*v = float64Value(parsed)
+	return nil
+}
+
+// stringListValue is comma separated list, empty string is empty list
+type stringListValue []string
+
+func (v *stringListValue) String() string {
+	return strings.Join(*v, ",")
+}
+
+func (v *stringListValue) Set(value string) error {
+	*v = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*v = append(*v, item)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("cannot write config file: %+v", err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlFile := writeConfigFile(t, "config.yaml", `
+server:
+  address: ":6000"
+database:
+  host: file-host
+  port: 6432
+  name: file-name
+`)
+	tomlFile := writeConfigFile(t, "config.toml", `
+[server]
+address = ":7000"
+[database]
+host = "toml-host"
+`)
+
+	cases := []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		address  string
+		host     string
+		port     uint16
+		dbName   string
+		maxConns int32
+	}{
+		{"Defaults", nil, nil, ":5000", "localhost", 5432, "my_db_forum", 10},
+		{"FileOverridesDefaults", nil, []string{"-config", yamlFile},
+			":6000", "file-host", 6432, "file-name", 10},
+		{"FileFromEnv", map[string]string{ConfigFileEnv: tomlFile}, nil,
+			":7000", "toml-host", 5432, "my_db_forum", 10},
+		{"FlagOverridesFileOfEnv", map[string]string{ConfigFileEnv: tomlFile}, []string{"-config", yamlFile},
+			":6000", "file-host", 6432, "file-name", 10},
+		{"EnvOverridesFile", map[string]string{"DB_FORUM_DATABASE_HOST": "env-host", "DB_FORUM_DATABASE_MAX_CONNS": "20"},
+			[]string{"-config", yamlFile}, ":6000", "env-host", 6432, "file-name", 20},
+		{"FlagsOverrideEnv", map[string]string{"DB_FORUM_DATABASE_HOST": "env-host", "DB_FORUM_SERVER_ADDRESS": ":8000"},
+			[]string{"-config", yamlFile, "-database.host", "flag-host", "-database.max_conns=30"},
+			":8000", "flag-host", 6432, "file-name", 30},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(ConfigFileEnv, "")
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), tc.args)
+			if err != nil {
+				t.Fatalf("cannot load config: %+v", err)
+			}
+
+			if cfg.Server.Address != tc.address || cfg.Database.Host != tc.host || cfg.Database.Port != tc.port ||
+				cfg.Database.Name != tc.dbName || cfg.Database.MaxConns != tc.maxConns {
+				t.Errorf("expected address %s, host %s, port %d, name %s, max conns %d, got %+v",
+					tc.address, tc.host, tc.port, tc.dbName, tc.maxConns, cfg)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	unknownKeyFile := writeConfigFile(t, "unknown.yaml", "server:\n  adress: \":6000\"\n")
+	jsonFile := writeConfigFile(t, "config.json", "{}")
+
+	cases := []struct {
+		name string
+		env  map[string]string
+		args []string
+		err  string
+	}{
+		{"UnknownFileKey", nil, []string{"-config", unknownKeyFile}, "cannot parse config file"},
+		{"UnsupportedFileExtension", nil, []string{"-config", jsonFile}, "unsupported config file extension"},
+		{"MissingFile", nil, []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")},
+			"cannot read config file"},
+		{"InvalidEnvValue", map[string]string{"DB_FORUM_DATABASE_PORT": "port"}, nil,
+			"invalid value of env DB_FORUM_DATABASE_PORT"},
+		{"InvalidFlagValue", nil, []string{"-server.read_timeout", "10"}, "invalid value of flag -server.read_timeout"},
+		{"UnknownFlag", nil, []string{"-server.adress", ":6000"}, "flag provided but not defined"},
+		{"InvalidConfig", map[string]string{"DB_FORUM_STORAGE_TYPE": "mysql"}, nil, "invalid config"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(ConfigFileEnv, "")
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+
+			flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+			flagSet.SetOutput(ioutil.Discard)
+
+			_, err := Load(flagSet, tc.args)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expected error with %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Password = "db-secret"
+	cfg.Admin.Token = "admin-secret"
+	cfg.Server.RouteQueryTimeouts = map[string]Duration{"GET /api/service/status": {time.Minute}}
+
+	dump := cfg.String()
+
+	for _, expected := range []string{
+		"database.password=" + redactedValue + "\n",
+		"admin.token=" + redactedValue + "\n",
+		"pagination.cursor_secret=\n",
+		"server.route_query_timeouts[GET /api/service/status]=1m0s\n",
+	} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("config dump doesn't contain %q:\n%s", expected, dump)
+		}
+	}
+	if strings.Contains(dump, "secret\n") {
+		t.Errorf("config dump contains secret:\n%s", dump)
+	}
+}
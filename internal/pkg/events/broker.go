@@ -0,0 +1,311 @@
+// Package events fans out forum changes from usecases to live subscribers of this process.
+package events
+
+import (
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	ThreadCreatedEvent = "thread"
+	PostCreatedEvent   = "post"
+	PostUpdatedEvent   = "post_update"
+	VoteEvent          = "vote"
+)
+
+// GlobalTopic receives all events
+const GlobalTopic = "global"
+
+var (
+	ErrSlowSubscriber = errors.New("subscriber is too slow")
+	ErrBrokerClosed   = errors.New("events broker is closed")
+	ErrUnsubscribed   = errors.New("unsubscribed")
+)
+
+// Event ids are assigned by broker in publish order, they start from 1 on every process start
+type Event struct {
+	ID     uint64
+	Type   string
+	Topics []string
+	// Payload is model which is marshalled to json by transport
+	Payload interface{}
+}
+
+// Publisher is used by usecases to report changes after they are stored
+type Publisher interface {
+	Publish(eventType string, payload interface{}, topics ...string)
+}
+
+// NopPublisher drops events, it is used by commands which run without http server
+type NopPublisher struct{}
+
+func (NopPublisher) Publish(string, interface{}, ...string) {}
+
+func ThreadTopic(id int32) string {
+	return "thread:" + strconv.FormatInt(int64(id), 10)
+}
+
+// ForumTopic and UserTopic are case insensitive like forum slugs and nicknames
+func ForumTopic(slug string) string {
+	return "forum:" + strings.ToLower(slug)
+}
+
+func UserTopic(nickname string) string {
+	return "user:" + strings.ToLower(nickname)
+}
+
+// ParseTopic validates topic of subscriber and returns it in the form used by publishers
+func ParseTopic(topic string) (string, error) {
+	if topic == GlobalTopic {
+		return topic, nil
+	}
+
+	kind := strings.SplitN(topic, ":", 2)
+	if len(kind) != 2 || kind[1] == "" {
+		return "", errors.Errorf("invalid topic %q", topic)
+	}
+
+	switch kind[0] {
+	case "thread":
+		id, err := strconv.ParseInt(kind[1], 10, 32)
+		if err != nil {
+			return "", errors.Errorf("invalid thread id in topic %q", topic)
+		}
+		return ThreadTopic(int32(id)), nil
+	case "forum":
+		return ForumTopic(kind[1]), nil
+	case "user":
+		return UserTopic(kind[1]), nil
+	default:
+		return "", errors.Errorf("unknown topic %q", topic)
+	}
+}
+
+// ActivityTopics returns topics of change in thread made by user
+func ActivityTopics(threadID int32, forumSlug, nickname string) []string {
+	return []string{ThreadTopic(threadID), ForumTopic(forumSlug), UserTopic(nickname), GlobalTopic}
+}
+
+// Subscription receives events of its topics, event with several topics is received once.
+// Events channel is closed when subscriber is too slow to read them, on unsubscribe and on broker close.
+type Subscription struct {
+	Events <-chan Event
+
+	events chan Event
+	topics map[string]bool
+	err    error
+}
+
+// Broker keeps recent events in ring buffer for resume and sends new events to subscribers.
+// Publish never blocks: subscriber with full buffer is dropped and has to resubscribe from the last received id.
+type Broker struct {
+	mu sync.Mutex
+
+	lastID  uint64
+	history []Event
+	next    int
+
+	subscribers map[string]map[*Subscription]bool
+	// subscriptions are all active subscriptions including ones without topics
+	subscriptions map[*Subscription]bool
+	bufferSize    int
+	closed        bool
+}
+
+func NewBroker(historySize, bufferSize int) *Broker {
+	return &Broker{
+		history:       make([]Event, 0, historySize),
+		subscribers:   make(map[string]map[*Subscription]bool),
+		subscriptions: make(map[*Subscription]bool),
+		bufferSize:    bufferSize,
+	}
+}
+
+func (broker *Broker) Publish(eventType string, payload interface{}, topics ...string) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	if broker.closed {
+		return
+	}
+
+	broker.lastID++
+	event := Event{
+		ID:      broker.lastID,
+		Type:    eventType,
+		Topics:  topics,
+		Payload: payload,
+	}
+
+	switch {
+	case cap(broker.history) == 0:
+	case len(broker.history) < cap(broker.history):
+		broker.history = append(broker.history, event)
+	default:
+		broker.history[broker.next] = event
+		broker.next = (broker.next + 1) % len(broker.history)
+	}
+
+	received := make(map[*Subscription]bool)
+	for _, topic := range topics {
+		for subscription := range broker.subscribers[topic] {
+			if received[subscription] {
+				continue
+			}
+			received[subscription] = true
+
+			select {
+			case subscription.events <- event:
+			default:
+				broker.remove(subscription, ErrSlowSubscriber)
+			}
+		}
+	}
+}
+
+// Subscribe returns subscription to topics and their events published after lastEventID.
+// Zero lastEventID means new subscriber without replay. Resumed is false when some events
+// after lastEventID are lost (they were evicted from history or published before restart).
+func (broker *Broker) Subscribe(lastEventID uint64, topics ...string) (subscription *Subscription,
+	replay []Event, resumed bool) {
+
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	events := make(chan Event, broker.bufferSize)
+	subscription = &Subscription{
+		Events: events,
+		events: events,
+	}
+
+	if broker.closed {
+		subscription.err = ErrBrokerClosed
+		close(events)
+		return subscription, nil, false
+	}
+
+	subscription.topics = make(map[string]bool)
+	broker.subscriptions[subscription] = true
+
+	broker.add(subscription, topics)
+
+	if lastEventID == 0 {
+		return subscription, nil, true
+	}
+	if lastEventID > broker.lastID {
+		return subscription, nil, false
+	}
+
+	resumed = lastEventID == broker.lastID
+	for i := range broker.history {
+		event := broker.history[(broker.next+i)%len(broker.history)]
+		if event.ID == lastEventID+1 {
+			resumed = true
+		}
+		if event.ID > lastEventID && subscription.matches(event) {
+			replay = append(replay, event)
+		}
+	}
+
+	return subscription, replay, resumed
+}
+
+// AddTopics subscribes to more topics, it returns false if subscription is already ended
+func (broker *Broker) AddTopics(subscription *Subscription, topics ...string) bool {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	if !broker.isActive(subscription) {
+		return false
+	}
+
+	broker.add(subscription, topics)
+	return true
+}
+
+// RemoveTopics unsubscribes from topics, subscription without topics stays active
+func (broker *Broker) RemoveTopics(subscription *Subscription, topics ...string) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for _, topic := range topics {
+		if !subscription.topics[topic] {
+			continue
+		}
+		delete(subscription.topics, topic)
+		broker.removeFromTopic(subscription, topic)
+	}
+}
+
+func (broker *Broker) Unsubscribe(subscription *Subscription) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	broker.remove(subscription, ErrUnsubscribed)
+}
+
+// Close ends all subscriptions, it is called on shutdown so streams don't hold server drain
+func (broker *Broker) Close() {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	// subscriptions without topics are not in subscribers, but they hold their streams too
+	for subscription := range broker.subscriptions {
+		broker.remove(subscription, ErrBrokerClosed)
+	}
+	broker.closed = true
+}
+
+func (broker *Broker) add(subscription *Subscription, topics []string) {
+	for _, topic := range topics {
+		topicSubscribers, ok := broker.subscribers[topic]
+		if !ok {
+			topicSubscribers = make(map[*Subscription]bool)
+			broker.subscribers[topic] = topicSubscribers
+		}
+		topicSubscribers[subscription] = true
+		subscription.topics[topic] = true
+	}
+}
+
+func (broker *Broker) remove(subscription *Subscription, err error) {
+	if subscription.topics == nil {
+		return // already removed
+	}
+
+	for topic := range subscription.topics {
+		broker.removeFromTopic(subscription, topic)
+	}
+	delete(broker.subscriptions, subscription)
+	subscription.topics = nil
+	subscription.err = err
+	close(subscription.events)
+}
+
+func (broker *Broker) removeFromTopic(subscription *Subscription, topic string) {
+	topicSubscribers := broker.subscribers[topic]
+	delete(topicSubscribers, subscription)
+	if len(topicSubscribers) == 0 {
+		delete(broker.subscribers, topic)
+	}
+}
+
+func (broker *Broker) isActive(subscription *Subscription) bool {
+	return subscription.topics != nil
+}
+
+// Err returns reason of subscription end, it must be called only after Events channel is closed
+func (subscription *Subscription) Err() error {
+	return subscription.err
+}
+
+func (subscription *Subscription) matches(event Event) bool {
+	for _, topic := range event.Topics {
+		if subscription.topics[topic] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package events
+
+import (
+	"errors"
+	"testing"
+)
+
+// receive returns events which are already sent to subscription
+func receive(subscription *Subscription) (received []Event, closed bool) {
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return received, true
+			}
+			received = append(received, event)
+		default:
+			return received, false
+		}
+	}
+}
+
+func eventIDs(events []Event) []uint64 {
+	ids := make([]uint64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func expectIDs(t *testing.T, events []Event, expected []uint64, what string) {
+	t.Helper()
+
+	ids := eventIDs(events)
+	if len(ids) != len(expected) {
+		t.Fatalf("%s: expected events %v, got %v", what, expected, ids)
+	}
+	for i := range ids {
+		if ids[i] != expected[i] {
+			t.Fatalf("%s: expected events %v, got %v", what, expected, ids)
+		}
+	}
+}
+
+func expectEnded(t *testing.T, subscription *Subscription, expectedErr error, what string) {
+	t.Helper()
+
+	if _, closed := receive(subscription); !closed {
+		t.Fatalf("%s: subscription is not ended", what)
+	}
+	if !errors.Is(subscription.Err(), expectedErr) {
+		t.Fatalf("%s: expected error %v, got %v", what, expectedErr, subscription.Err())
+	}
+}
+
+func TestBrokerFanOut(t *testing.T) {
+	broker := NewBroker(0, 8)
+
+	thread, _, _ := broker.Subscribe(0, ThreadTopic(1))
+	forum, _, _ := broker.Subscribe(0, ForumTopic("go"))
+	both, _, _ := broker.Subscribe(0, ThreadTopic(1), ForumTopic("go"))
+	other, _, _ := broker.Subscribe(0, ThreadTopic(2))
+
+	broker.Publish(PostCreatedEvent, nil, ActivityTopics(1, "Go", "john")...)
+	broker.Publish(PostCreatedEvent, nil, ThreadTopic(2))
+
+	for subscription, expected := range map[*Subscription][]uint64{
+		thread: {1},
+		forum:  {1},
+		both:   {1}, // event of several topics is received once
+		other:  {2},
+	} {
+		received, closed := receive(subscription)
+		if closed {
+			t.Fatalf("subscription is ended: %v", subscription.Err())
+		}
+		expectIDs(t, received, expected, "received events")
+	}
+
+	broker.Unsubscribe(thread)
+	broker.Publish(PostCreatedEvent, nil, ThreadTopic(1))
+	expectEnded(t, thread, ErrUnsubscribed, "unsubscribed")
+
+	received, _ := receive(both)
+	expectIDs(t, received, []uint64{3}, "events of other subscriber of topic")
+}
+
+func TestBrokerHistoryReplay(t *testing.T) {
+	broker := NewBroker(3, 8)
+
+	for i := 0; i < 5; i++ {
+		broker.Publish(PostCreatedEvent, nil, ThreadTopic(int32(i%2)))
+	}
+
+	// history keeps events 3, 4 and 5
+	_, replay, resumed := broker.Subscribe(3, ThreadTopic(0))
+	if !resumed {
+		t.Fatalf("subscription after event in history is not resumed")
+	}
+	expectIDs(t, replay, []uint64{5}, "replay of topic")
+
+	_, replay, resumed = broker.Subscribe(2, ThreadTopic(0), ThreadTopic(1))
+	if !resumed {
+		t.Fatalf("subscription after the last evicted event is not resumed")
+	}
+	expectIDs(t, replay, []uint64{3, 4, 5}, "replay of all topics")
+
+	_, replay, resumed = broker.Subscribe(1, ThreadTopic(0))
+	if resumed {
+		t.Fatalf("subscription after evicted events is resumed")
+	}
+	expectIDs(t, replay, []uint64{3, 5}, "replay after evicted events")
+
+	_, replay, resumed = broker.Subscribe(5, ThreadTopic(0))
+	if !resumed || len(replay) != 0 {
+		t.Fatalf("subscription after the last event: resumed=%v, replay=%v", resumed, eventIDs(replay))
+	}
+
+	// ids of other process
+	if _, _, resumed = broker.Subscribe(10, ThreadTopic(0)); resumed {
+		t.Fatalf("subscription after unknown event is resumed")
+	}
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	broker := NewBroker(0, 2)
+
+	slow, _, _ := broker.Subscribe(0, GlobalTopic)
+	fast, _, _ := broker.Subscribe(0, GlobalTopic)
+
+	for i := 0; i < 3; i++ {
+		broker.Publish(VoteEvent, nil, GlobalTopic)
+		if i == 1 {
+			received, _ := receive(fast)
+			expectIDs(t, received, []uint64{1, 2}, "events of fast subscriber")
+		}
+	}
+
+	received, closed := receive(slow)
+	expectIDs(t, received, []uint64{1, 2}, "buffered events of slow subscriber")
+	if !closed || !errors.Is(slow.Err(), ErrSlowSubscriber) {
+		t.Fatalf("slow subscriber is not dropped: closed=%v, err=%v", closed, slow.Err())
+	}
+
+	received, closed = receive(fast)
+	if closed {
+		t.Fatalf("fast subscriber is dropped: %v", fast.Err())
+	}
+	expectIDs(t, received, []uint64{3}, "events of fast subscriber")
+
+	if broker.AddTopics(slow, ThreadTopic(1)) {
+		t.Fatalf("topics are added to dropped subscription")
+	}
+}
+
+func TestBrokerCloseEndsAllSubscriptions(t *testing.T) {
+	broker := NewBroker(8, 8)
+
+	withTopics, _, _ := broker.Subscribe(0, GlobalTopic)
+	withoutTopics, _, _ := broker.Subscribe(0)
+	emptied, _, _ := broker.Subscribe(0, ThreadTopic(1), ForumTopic("go"))
+	broker.RemoveTopics(emptied, ThreadTopic(1), ForumTopic("go"))
+
+	broker.Close()
+
+	expectEnded(t, withTopics, ErrBrokerClosed, "subscription with topics")
+	expectEnded(t, withoutTopics, ErrBrokerClosed, "subscription without topics")
+	expectEnded(t, emptied, ErrBrokerClosed, "subscription without removed topics")
+
+	late, _, _ := broker.Subscribe(0, GlobalTopic)
+	expectEnded(t, late, ErrBrokerClosed, "subscription after close")
+
+	broker.Publish(VoteEvent, nil, GlobalTopic) // must not panic on closed channels
+}
@@ -0,0 +1,193 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/nickeskov/db_forum/internal/pkg/events"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/thread"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// resetEvent tells client that some events are lost and thread must be reloaded
+	resetEvent = "reset"
+	// retryMillis is reconnection delay for EventSource clients
+	retryMillis = 3000
+)
+
+type Delivery struct {
+	threadUseCase thread.UseCase
+	broker        *events.Broker
+	heartbeat     time.Duration
+	lifetime      time.Duration
+	upgrader      websocket.Upgrader
+	connections   *sync.WaitGroup
+	utils         httpUtils.Utils
+}
+
+// NewDelivery creates streams which send comment every heartbeat and are closed after lifetime,
+// so they end before server write timeout and clients reconnect with Last-Event-ID. Zero lifetime is unlimited.
+// Websocket connections are pinged every heartbeat and are not limited by lifetime.
+func NewDelivery(threadUseCase thread.UseCase, broker *events.Broker,
+	heartbeat, lifetime time.Duration, logger logger.Logger) Delivery {
+
+	return Delivery{
+		threadUseCase: threadUseCase,
+		broker:        broker,
+		heartbeat:     heartbeat,
+		lifetime:      lifetime,
+		upgrader: websocket.Upgrader{
+			HandshakeTimeout: gatewayWriteWait,
+		},
+		connections: new(sync.WaitGroup),
+		utils:       httpUtils.NewDeliveryUtils(logger),
+	}
+}
+
+// WaitConnections waits for websocket connections which are hijacked from http server,
+// they end after broker is closed. It returns error if connections are not ended before ctx is done.
+func (delivery Delivery) WaitConnections(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		delivery.connections.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("websocket connections are not ended: %w", ctx.Err())
+	}
+}
+
+func (delivery Delivery) StreamThread(w http.ResponseWriter, r *http.Request) {
+	slugOrID := mux.Vars(r)["slug_or_id"]
+
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, "invalid Last-Event-ID")
+		return
+	}
+
+	threadModel, err := delivery.threadUseCase.GetBySlugOrID(r.Context(), slugOrID)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("thread with slug_or_id=%s does not exits", slugOrID))
+		return
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+		return
+	}
+
+	flusher, ok := tryFlusher(w)
+	if !ok {
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	subscription, replay, resumed := delivery.broker.Subscribe(lastEventID, events.ThreadTopic(threadModel.ID))
+	defer delivery.broker.Unsubscribe(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryMillis); err != nil {
+		return
+	}
+	if !resumed {
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: {}\n\n", resetEvent); err != nil {
+			return
+		}
+	}
+	for _, event := range replay {
+		if err := delivery.writeEvent(w, r, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(delivery.heartbeat)
+	defer heartbeat.Stop()
+
+	var expired <-chan time.Time
+	if delivery.lifetime > 0 {
+		timer := time.NewTimer(delivery.lifetime)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return // too slow subscriber or shutdown, client resumes from the last id
+			}
+			if err := delivery.writeEvent(w, r, event); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+
+		case <-expired:
+			return
+
+		case <-r.Context().Done():
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+func (delivery Delivery) writeEvent(w io.Writer, r *http.Request, event events.Event) error {
+	data, err := json.Marshal(event.Payload)
+	if err != nil {
+		delivery.utils.GetLogger().HttpLogCallerError(r.Context(), delivery, err)
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// parseLastEventID reads id from header which is sent by EventSource on reconnect,
+// last_event_id query param is used by clients which can't set headers
+func parseLastEventID(r *http.Request) (uint64, error) {
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	if lastEventID == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseUint(lastEventID, 10, 64)
+}
+
+func tryFlusher(w http.ResponseWriter) (http.Flusher, bool) {
+	if writer, ok := w.(interface{ TryFlusher() (http.Flusher, bool) }); ok {
+		return writer.TryFlusher()
+	}
+	flusher, ok := w.(http.Flusher)
+	return flusher, ok
+}
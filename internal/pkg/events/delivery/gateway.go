@@ -0,0 +1,211 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/nickeskov/db_forum/internal/pkg/events"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/pkg/requestid"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	gatewayMaxTopics      = 100
+	gatewayMaxMessageSize = 4096
+	gatewayWriteWait      = 10 * time.Second
+	// gatewayRepliesSize is number of unsent replies to client requests, client is dropped when it is exceeded
+	gatewayRepliesSize = 16
+)
+
+var errRepliesOverflow = errors.New("client doesn't read replies")
+
+// Gateway is websocket endpoint for subscriptions to forum, user, thread and global topics.
+// Client sends GatewayRequest messages and receives events as GatewayMessage, slow clients are disconnected.
+// Connection gets request id like other requests, it is reused if it is already set by middleware.
+func (delivery Delivery) Gateway(w http.ResponseWriter, r *http.Request) {
+	log := delivery.utils.GetLogger()
+
+	ctx := r.Context()
+	if _, ok := requestid.FromContext(ctx); !ok {
+		ctx = requestid.NewContext(ctx, requestid.New())
+	}
+
+	delivery.connections.Add(1)
+	defer delivery.connections.Done()
+
+	conn, err := delivery.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // upgrader has already written error response
+	}
+	defer conn.Close()
+
+	subscription, _, _ := delivery.broker.Subscribe(0)
+	defer delivery.broker.Unsubscribe(subscription)
+
+	replies := make(chan models.GatewayMessage, gatewayRepliesSize)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- delivery.readGatewayRequests(ctx, conn, subscription, replies)
+	}()
+
+	heartbeat := time.NewTicker(delivery.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var message models.GatewayMessage
+
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				closeCode := websocket.CloseTryAgainLater
+				if errors.Is(subscription.Err(), events.ErrBrokerClosed) {
+					closeCode = websocket.CloseGoingAway
+				}
+				log.HttpLogInfo(ctx, fmt.Sprintf("websocket closed: %v", subscription.Err()))
+				writeClose(conn, closeCode, subscription.Err().Error())
+				return
+			}
+			message = models.GatewayMessage{
+				Type:   event.Type,
+				ID:     event.ID,
+				Topics: event.Topics,
+				Data:   event.Payload,
+			}
+
+		case message = <-replies:
+
+		case err := <-readErr:
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.HttpLogInfo(ctx, fmt.Sprintf("websocket closed: %v", err))
+			}
+			if errors.Is(err, errRepliesOverflow) {
+				writeClose(conn, websocket.CloseTryAgainLater, err.Error())
+			}
+			return
+
+		case <-heartbeat.C:
+			deadline := time.Now().Add(gatewayWriteWait)
+			if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				return
+			}
+			continue
+		}
+
+		data, err := message.MarshalJSON()
+		if err != nil {
+			log.HttpLogCallerError(ctx, delivery, err)
+			return
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(gatewayWriteWait))
+		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			return
+		}
+	}
+}
+
+// readGatewayRequests applies client requests until connection is broken,
+// client which misses two heartbeats or doesn't read replies is disconnected
+func (delivery Delivery) readGatewayRequests(ctx context.Context, conn *websocket.Conn,
+	subscription *events.Subscription, replies chan<- models.GatewayMessage) error {
+
+	log := delivery.utils.GetLogger()
+
+	conn.SetReadLimit(gatewayMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * delivery.heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * delivery.heartbeat))
+	})
+
+	topics := make(map[string]bool)
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var request models.GatewayRequest
+		reply := models.GatewayMessage{Type: models.SubscribedMessage}
+
+		if err := request.UnmarshalJSON(data); err != nil {
+			reply = models.GatewayMessage{Type: models.ErrorMessage, Message: "invalid message: " + err.Error()}
+		} else if err := applyGatewayRequest(request, topics); err != nil {
+			reply = models.GatewayMessage{Type: models.ErrorMessage, Message: err.Error()}
+		} else {
+			if request.Action == models.SubscribeAction {
+				if !delivery.broker.AddTopics(subscription, request.Topics...) {
+					return nil // subscription is ended, writer closes connection
+				}
+			} else {
+				delivery.broker.RemoveTopics(subscription, request.Topics...)
+			}
+
+			reply.Topics = sortedTopics(topics)
+			log.HttpLogInfo(ctx, fmt.Sprintf("websocket topics: %s", strings.Join(reply.Topics, ", ")))
+		}
+
+		select {
+		case replies <- reply:
+		default:
+			return errRepliesOverflow
+		}
+	}
+}
+
+// applyGatewayRequest validates request and updates set of connection topics,
+// request topics are replaced by their normalized form
+func applyGatewayRequest(request models.GatewayRequest, topics map[string]bool) error {
+	if request.Action != models.SubscribeAction && request.Action != models.UnsubscribeAction {
+		return fmt.Errorf("unknown action %q", request.Action)
+	}
+
+	for i, topic := range request.Topics {
+		parsed, err := events.ParseTopic(topic)
+		if err != nil {
+			return err
+		}
+		request.Topics[i] = parsed
+	}
+
+	if request.Action == models.UnsubscribeAction {
+		for _, topic := range request.Topics {
+			delete(topics, topic)
+		}
+		return nil
+	}
+
+	added := make(map[string]bool)
+	for _, topic := range request.Topics {
+		if !topics[topic] {
+			added[topic] = true
+		}
+	}
+	if len(topics)+len(added) > gatewayMaxTopics {
+		return fmt.Errorf("too many topics, max is %d", gatewayMaxTopics)
+	}
+	for topic := range added {
+		topics[topic] = true
+	}
+
+	return nil
+}
+
+func sortedTopics(topics map[string]bool) []string {
+	sorted := make([]string, 0, len(topics))
+	for topic := range topics {
+		sorted = append(sorted, topic)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func writeClose(conn *websocket.Conn, code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	_ = conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(gatewayWriteWait))
+}
@@ -0,0 +1,280 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/nickeskov/db_forum/internal/pkg/events"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"github.com/nickeskov/db_forum/pkg/requestid"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGatewayConnectionsEndOnBrokerClose(t *testing.T) {
+	broker := events.NewBroker(8, 8)
+	delivery := NewDelivery(nil, broker, time.Minute, 0,
+		logger.NewTextFormatSimpleLogger(ioutil.Discard, requestid.ContextKey{}))
+
+	server := httptest.NewServer(http.HandlerFunc(delivery.Gateway))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("cannot connect to gateway: %+v", err)
+	}
+	defer conn.Close()
+
+	// client removes all its topics, so its subscription has no topics like one of new client
+	for _, action := range []string{models.SubscribeAction, models.UnsubscribeAction} {
+		request := models.GatewayRequest{Action: action, Topics: []string{events.GlobalTopic}}
+		if err := conn.WriteJSON(request); err != nil {
+			t.Fatalf("cannot send %s request: %+v", action, err)
+		}
+
+		var reply models.GatewayMessage
+		if err := conn.ReadJSON(&reply); err != nil || reply.Type != models.SubscribedMessage {
+			t.Fatalf("unexpected reply to %s request: %+v, %v", action, reply, err)
+		}
+	}
+
+	broker.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := delivery.WaitConnections(ctx); err != nil {
+		t.Fatalf("connection is not ended after broker close: %+v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("expected going away close, got %v", err)
+	}
+}
+
+func TestWaitConnectionsIsLimitedByContext(t *testing.T) {
+	delivery := NewDelivery(nil, events.NewBroker(8, 8), time.Minute, 0,
+		logger.NewTextFormatSimpleLogger(ioutil.Discard, requestid.ContextKey{}))
+
+	delivery.connections.Add(1) // connection which never ends
+	defer delivery.connections.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := delivery.WaitConnections(ctx); err == nil {
+		t.Fatalf("wait of connection which never ends is not limited by context")
+	}
+}
+
+func newGatewayServer(t *testing.T, broker *events.Broker, heartbeat time.Duration) (*websocket.Conn, func()) {
+	delivery := NewDelivery(nil, broker, heartbeat, 0,
+		logger.NewTextFormatSimpleLogger(ioutil.Discard, requestid.ContextKey{}))
+
+	server := httptest.NewServer(http.HandlerFunc(delivery.Gateway))
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		server.Close()
+		t.Fatalf("cannot connect to gateway: %+v", err)
+	}
+
+	return conn, func() {
+		_ = conn.Close()
+		broker.Close()
+		server.Close()
+	}
+}
+
+func readGatewayMessage(t *testing.T, conn *websocket.Conn) models.GatewayMessage {
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var message models.GatewayMessage
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("cannot read gateway message: %+v", err)
+	}
+	return message
+}
+
+func TestGatewayRequests(t *testing.T) {
+	conn, closeServer := newGatewayServer(t, events.NewBroker(8, 8), time.Minute)
+	defer closeServer()
+
+	// requests are applied in order, topics of reply are all topics of connection
+	cases := []struct {
+		name    string
+		request string
+		topics  []string
+		err     string
+	}{
+		{"SubscribeNormalizesTopics", `{"action": "subscribe", "topics": ["forum:Go", "user:JOHN", "thread:007", "global"]}`,
+			[]string{"forum:go", "global", "thread:7", "user:john"}, ""},
+		{"SubscribeExisting", `{"action": "subscribe", "topics": ["forum:GO"]}`,
+			[]string{"forum:go", "global", "thread:7", "user:john"}, ""},
+		{"UnknownTopicKind", `{"action": "unsubscribe", "topics": ["FORUM:go"]}`, nil, "unknown topic"},
+		{"UnsubscribeNormalizesTopics", `{"action": "unsubscribe", "topics": ["forum:GO", "thread:7"]}`,
+			[]string{"global", "user:john"}, ""},
+		{"UnsubscribeMissing", `{"action": "unsubscribe", "topics": ["user:bob"]}`,
+			[]string{"global", "user:john"}, ""},
+		{"InvalidThreadID", `{"action": "subscribe", "topics": ["thread:first"]}`, nil, "invalid thread id"},
+		{"EmptyTopicName", `{"action": "subscribe", "topics": ["forum:"]}`, nil, "invalid topic"},
+		{"UnknownAction", `{"action": "listen", "topics": ["global"]}`, nil, "unknown action"},
+		{"InvalidMessage", `{"action": `, nil, "invalid message"},
+	}
+
+	for _, tc := range cases {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(tc.request)); err != nil {
+			t.Fatalf("%s: cannot send request: %+v", tc.name, err)
+		}
+
+		reply := readGatewayMessage(t, conn)
+		switch {
+		case tc.err != "":
+			if reply.Type != models.ErrorMessage || !strings.Contains(reply.Message, tc.err) {
+				t.Errorf("%s: expected error with %q, got %+v", tc.name, tc.err, reply)
+			}
+		case reply.Type != models.SubscribedMessage ||
+			strings.Join(reply.Topics, ",") != strings.Join(tc.topics, ","):
+			t.Errorf("%s: expected topics %v, got %+v", tc.name, tc.topics, reply)
+		}
+	}
+}
+
+func TestGatewaySendsEventsOfTopics(t *testing.T) {
+	broker := events.NewBroker(8, 8)
+	conn, closeServer := newGatewayServer(t, broker, time.Minute)
+	defer closeServer()
+
+	request := models.GatewayRequest{Action: models.SubscribeAction, Topics: []string{"user:John"}}
+	if err := conn.WriteJSON(request); err != nil {
+		t.Fatalf("cannot send request: %+v", err)
+	}
+	if reply := readGatewayMessage(t, conn); reply.Type != models.SubscribedMessage {
+		t.Fatalf("unexpected reply: %+v", reply)
+	}
+
+	broker.Publish(events.ThreadCreatedEvent, "other", events.UserTopic("bob"))
+	broker.Publish(events.PostCreatedEvent, "post", events.ActivityTopics(1, "go", "JOHN")...)
+
+	message := readGatewayMessage(t, conn)
+	if message.Type != events.PostCreatedEvent || message.ID != 2 || message.Data != "post" {
+		t.Fatalf("expected post event with id 2, got %+v", message)
+	}
+}
+
+func TestGatewayMaxTopics(t *testing.T) {
+	conn, closeServer := newGatewayServer(t, events.NewBroker(8, 8), time.Minute)
+	defer closeServer()
+
+	topics := make([]string, 0, gatewayMaxTopics)
+	for i := 0; i < gatewayMaxTopics; i++ {
+		topics = append(topics, events.ThreadTopic(int32(i)))
+	}
+
+	cases := []struct {
+		name    string
+		topics  []string
+		isError bool
+	}{
+		{"UpToLimit", topics, false},
+		{"OverLimit", []string{events.GlobalTopic}, true},
+		// existing topics don't count twice
+		{"ExistingAtLimit", topics[:10], false},
+	}
+
+	for _, tc := range cases {
+		request := models.GatewayRequest{Action: models.SubscribeAction, Topics: tc.topics}
+		if err := conn.WriteJSON(request); err != nil {
+			t.Fatalf("%s: cannot send request: %+v", tc.name, err)
+		}
+
+		reply := readGatewayMessage(t, conn)
+		switch {
+		case tc.isError && reply.Type != models.ErrorMessage:
+			t.Errorf("%s: expected error, got %+v", tc.name, reply)
+		case !tc.isError && len(reply.Topics) != gatewayMaxTopics:
+			t.Errorf("%s: expected %d topics, got %+v", tc.name, gatewayMaxTopics, reply)
+		}
+	}
+}
+
+func TestGatewayPings(t *testing.T) {
+	const heartbeat = 50 * time.Millisecond
+
+	cases := []struct {
+		name       string
+		answerPing bool
+		closed     bool
+	}{
+		{"AnsweringClientStays", true, false},
+		{"SilentClientIsDisconnected", false, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, closeServer := newGatewayServer(t, events.NewBroker(8, 8), heartbeat)
+			defer closeServer()
+
+			pings := 0
+			conn.SetPingHandler(func(data string) error {
+				pings++
+				if !tc.answerPing {
+					return nil
+				}
+				return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+			})
+
+			// control messages are handled while message is read
+			_ = conn.SetReadDeadline(time.Now().Add(8 * heartbeat))
+			_, _, err := conn.ReadMessage()
+
+			var netErr net.Error
+			closed := !(errors.As(err, &netErr) && netErr.Timeout())
+			if closed != tc.closed {
+				t.Errorf("expected closed connection %v, read error is %v", tc.closed, err)
+			}
+			if pings < 2 {
+				t.Errorf("expected at least 2 pings, got %d", pings)
+			}
+		})
+	}
+}
+
+func TestGatewayDisconnectsSlowConsumer(t *testing.T) {
+	broker := events.NewBroker(0, 1)
+	conn, closeServer := newGatewayServer(t, broker, time.Minute)
+	defer closeServer()
+
+	request := models.GatewayRequest{Action: models.SubscribeAction, Topics: []string{events.GlobalTopic}}
+	if err := conn.WriteJSON(request); err != nil {
+		t.Fatalf("cannot send request: %+v", err)
+	}
+	if reply := readGatewayMessage(t, conn); reply.Type != models.SubscribedMessage {
+		t.Fatalf("unexpected reply: %+v", reply)
+	}
+
+	// client doesn't read, so socket buffers are filled and events are queued till buffer of subscription overflows
+	payload := strings.Repeat("x", 64*1024)
+	for i := 0; i < 512; i++ {
+		broker.Publish(events.PostCreatedEvent, payload, events.GlobalTopic)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		if !websocket.IsCloseError(err, websocket.CloseTryAgainLater) {
+			t.Fatalf("expected try again later close, got %v", err)
+		}
+		return
+	}
+}
@@ -2,10 +2,13 @@ package delivery
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gorilla/mux"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
 	"github.com/nickeskov/db_forum/internal/pkg/forum"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/pagination"
 	"github.com/nickeskov/db_forum/internal/pkg/utils"
 	httpUtils "github.com/nickeskov/db_forum/pkg/http"
 	"github.com/nickeskov/db_forum/pkg/logger"
@@ -14,12 +17,18 @@ import (
 
 type Delivery struct {
 	useCase forum.UseCase
+	guard   auth.Guard
+	signer  pagination.Signer
 	utils   httpUtils.Utils
 }
 
-func NewDelivery(useCase forum.UseCase, logger logger.Logger) Delivery {
+func NewDelivery(useCase forum.UseCase, guard auth.Guard, signer pagination.Signer,
+	logger logger.Logger) Delivery {
+
 	return Delivery{
 		useCase: useCase,
+		guard:   guard,
+		signer:  signer,
 		utils:   httpUtils.NewDeliveryUtils(logger),
 	}
 }
@@ -42,10 +51,15 @@ func (delivery Delivery) CreateForum(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createdForum, err := delivery.useCase.Create(newForum)
+	if err := delivery.guard.CheckCaller(r.Context(), newForum.User); err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return
+	}
+
+	createdForum, err := delivery.useCase.Create(r.Context(), newForum)
 	switch err {
 	case models.ErrConflict:
-		existingForum, err := delivery.useCase.GetBySlug(newForum.Slug)
+		existingForum, err := delivery.useCase.GetBySlug(r.Context(), newForum.Slug)
 		if err != nil {
 			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
 			return
@@ -82,7 +96,7 @@ func (delivery Delivery) CreateForum(w http.ResponseWriter, r *http.Request) {
 func (delivery Delivery) GetForumDetails(w http.ResponseWriter, r *http.Request) {
 	slug := mux.Vars(r)["slug"]
 
-	existingForum, err := delivery.useCase.GetBySlug(slug)
+	existingForum, err := delivery.useCase.GetBySlug(r.Context(), slug)
 	switch err {
 	case models.ErrDoesNotExist:
 		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
@@ -107,7 +121,18 @@ func (delivery Delivery) GetForumUsers(w http.ResponseWriter, r *http.Request) {
 
 	sinceNickname, desc, limit := utils.ParseSinceDescLimit(r.URL.Query())
 
-	users, err := delivery.useCase.GetForumUsersBySlug(slug, sinceNickname, desc, limit)
+	list := pagination.List("users", slug, desc)
+	cursor, err := delivery.signer.FromRequest(r, list)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, "invalid cursor")
+		return
+	}
+	if cursor != nil {
+		// nicknames are unique, so the last user of page is exact since of the next page
+		sinceNickname = cursor.Nickname
+	}
+
+	users, err := delivery.useCase.GetForumUsersBySlug(r.Context(), slug, sinceNickname, desc, limit)
 	switch err {
 	case models.ErrDoesNotExist:
 		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
@@ -117,7 +142,24 @@ func (delivery Delivery) GetForumUsers(w http.ResponseWriter, r *http.Request) {
 		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, models.ErrInvalid.Error())
 
 	case nil:
-		data, err := json.Marshal(users)
+		page := models.UsersPage{Items: users}
+		if pagination.IsFull(r, len(users)) {
+			page.NextCursor, err = delivery.signer.SetNextLink(w, r, pagination.Cursor{
+				List:     list,
+				Nickname: users[len(users)-1].Nickname,
+			})
+			if err != nil {
+				delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+
+		var data []byte
+		if pagination.Requested(r) {
+			data, err = json.Marshal(page)
+		} else {
+			data, err = json.Marshal(users)
+		}
 		if err != nil {
 			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
 			return
@@ -130,3 +172,142 @@ func (delivery Delivery) GetForumUsers(w http.ResponseWriter, r *http.Request) {
 			fmt.Sprintf("%+v", err))
 	}
 }
+
+func (delivery Delivery) GetForumRoles(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	roles, err := delivery.useCase.GetRoles(r.Context(), slug)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("forum with slug=%s does not exist", slug))
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(roles)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+func (delivery Delivery) GrantForumRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	data, err := delivery.utils.ReadAllDataFromBody(w, r)
+	if err != nil {
+		return
+	}
+
+	var newRole models.ForumRole
+
+	if err := json.Unmarshal(data, &newRole); err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	newRole.Forum, newRole.Nickname = vars["slug"], vars["nickname"]
+
+	if validationErr := newRole.Validate(); validationErr != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, validationErr.Error())
+		return
+	}
+
+	currentRole, ok := delivery.getCurrentRole(w, r, newRole.Forum, newRole.Nickname)
+	if !ok {
+		return
+	}
+
+	err = delivery.guard.CheckRoleManager(r.Context(), newRole.Forum, currentRole.Role, newRole.Role)
+	if err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return
+	}
+
+	grantedRole, err := delivery.useCase.GrantRole(r.Context(), newRole)
+	switch {
+	case errors.Is(err, models.ErrInvalid):
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest,
+			fmt.Sprintf("role=%s can't be granted", newRole.Role))
+
+	case errors.Is(err, models.ErrBadForeign):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("forum with slug=%s or user with nickname=%s does not exist",
+				newRole.Forum, newRole.Nickname))
+
+	case errors.Is(err, models.ErrConflict):
+		delivery.utils.WriteResponseError(w, r, http.StatusConflict,
+			fmt.Sprintf("user with nickname=%s is owner of forum with slug=%s", newRole.Nickname, newRole.Forum))
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(grantedRole)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+func (delivery Delivery) RevokeForumRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	slug, nickname := vars["slug"], vars["nickname"]
+
+	currentRole, ok := delivery.getCurrentRole(w, r, slug, nickname)
+	if !ok {
+		return
+	}
+
+	if currentRole.Role == "" {
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("user with nickname=%s has no role in forum with slug=%s", nickname, slug))
+		return
+	}
+
+	if err := delivery.guard.CheckRoleManager(r.Context(), slug, currentRole.Role); err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return
+	}
+
+	err := delivery.useCase.RevokeRole(r.Context(), slug, nickname)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("user with nickname=%s has no role in forum with slug=%s", nickname, slug))
+
+	case errors.Is(err, models.ErrConflict):
+		delivery.utils.WriteResponseError(w, r, http.StatusConflict,
+			fmt.Sprintf("user with nickname=%s is owner of forum with slug=%s", nickname, slug))
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		delivery.utils.WriteResponse(w, r, http.StatusOK, nil)
+	}
+}
+
+// getCurrentRole returns role of user in forum, role is empty if user has no role
+func (delivery Delivery) getCurrentRole(w http.ResponseWriter, r *http.Request,
+	slug, nickname string) (models.ForumRole, bool) {
+
+	role, err := delivery.useCase.GetRole(r.Context(), slug, nickname)
+	if err != nil && !errors.Is(err, models.ErrDoesNotExist) {
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+		return models.ForumRole{}, false
+	}
+
+	return role, true
+}
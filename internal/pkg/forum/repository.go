@@ -1,9 +1,19 @@
 package forum
 
-import "github.com/nickeskov/db_forum/internal/pkg/models"
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+)
 
 type Repository interface {
-	Create(forum models.Forum) (models.Forum, error)
-	GetBySlug(slug string) (models.Forum, error)
-	GetForumUsersBySlug(slug, sinceNickname string, desc bool, limit int32) (models.Users, error)
+	Create(ctx context.Context, forum models.Forum) (models.Forum, error)
+	GetBySlug(ctx context.Context, slug string) (models.Forum, error)
+	GetForumUsersBySlug(ctx context.Context, slug, sinceNickname string,
+		desc bool, limit int32) (models.Users, error)
+	GetRoles(ctx context.Context, slug string) (models.ForumRoles, error)
+	GetRole(ctx context.Context, slug, nickname string) (models.ForumRole, error)
+	// SetRole grants role to user, owner role can't be granted or replaced
+	SetRole(ctx context.Context, role models.ForumRole) (models.ForumRole, error)
+	// DeleteRole revokes role of user, owner role can't be revoked
+	DeleteRole(ctx context.Context, slug, nickname string) error
 }
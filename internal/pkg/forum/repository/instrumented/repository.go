@@ -0,0 +1,73 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/forum"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const repositoryName = "forum"
+
+// Repository traces every method of wrapped repository and observes its latency
+type Repository struct {
+	repository forum.Repository
+	observer   instrumentation.Observer
+}
+
+// NewRepository wraps repository, durations must have repository and method labels.
+// Durations and tracer may be nil.
+func NewRepository(repository forum.Repository, durations *prometheus.HistogramVec,
+	tracer trace.Tracer) Repository {
+
+	return Repository{
+		repository: repository,
+		observer:   instrumentation.NewObserver(repositoryName, "repository", durations, tracer),
+	}
+}
+
+func (repo Repository) Create(ctx context.Context, forum models.Forum) (_ models.Forum, err error) {
+	ctx, done := repo.observer.Start(ctx, "Create")
+	defer func() { done(err) }()
+	return repo.repository.Create(ctx, forum)
+}
+
+func (repo Repository) GetBySlug(ctx context.Context, slug string) (_ models.Forum, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetBySlug")
+	defer func() { done(err) }()
+	return repo.repository.GetBySlug(ctx, slug)
+}
+
+func (repo Repository) GetForumUsersBySlug(ctx context.Context,
+	slug, sinceNickname string, desc bool, limit int32) (_ models.Users, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetForumUsersBySlug")
+	defer func() { done(err) }()
+	return repo.repository.GetForumUsersBySlug(ctx, slug, sinceNickname, desc, limit)
+}
+
+func (repo Repository) GetRoles(ctx context.Context, slug string) (_ models.ForumRoles, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetRoles")
+	defer func() { done(err) }()
+	return repo.repository.GetRoles(ctx, slug)
+}
+
+func (repo Repository) GetRole(ctx context.Context, slug, nickname string) (_ models.ForumRole, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetRole")
+	defer func() { done(err) }()
+	return repo.repository.GetRole(ctx, slug, nickname)
+}
+
+func (repo Repository) SetRole(ctx context.Context, role models.ForumRole) (_ models.ForumRole, err error) {
+	ctx, done := repo.observer.Start(ctx, "SetRole")
+	defer func() { done(err) }()
+	return repo.repository.SetRole(ctx, role)
+}
+
+func (repo Repository) DeleteRole(ctx context.Context, slug, nickname string) (err error) {
+	ctx, done := repo.observer.Start(ctx, "DeleteRole")
+	defer func() { done(err) }()
+	return repo.repository.DeleteRole(ctx, slug, nickname)
+}
@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+	"sort"
+)
+
+type Repository struct {
+	db *memoryDB.DB
+}
+
+func NewRepository(db *memoryDB.DB) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) Create(ctx context.Context, forum models.Forum) (models.Forum, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Forum{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	owner, ok := repo.db.UsersByNickname[memoryDB.CIKey(forum.User)]
+	if !ok {
+		return models.Forum{}, models.ErrBadForeign
+	}
+
+	slugKey := memoryDB.CIKey(forum.Slug)
+	if _, ok := repo.db.Forums[slugKey]; ok {
+		return models.Forum{}, models.ErrConflict
+	}
+
+	forum.User = owner.Nickname
+
+	stored := forum
+	repo.db.Forums[slugKey] = &stored
+
+	// like add_forum_owner_role trigger
+	repo.db.ForumRoles[slugKey] = map[string]models.ForumRole{
+		memoryDB.CIKey(owner.Nickname): {Forum: forum.Slug, Nickname: owner.Nickname, Role: models.OwnerForumRole},
+	}
+
+	return forum, nil
+}
+
+func (repo Repository) GetBySlug(ctx context.Context, slug string) (models.Forum, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Forum{}, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	stored, ok := repo.db.Forums[memoryDB.CIKey(slug)]
+	if !ok {
+		return models.Forum{}, models.ErrDoesNotExist
+	}
+
+	return *stored, nil
+}
+
+func (repo Repository) GetForumUsersBySlug(ctx context.Context, slug, sinceNickname string,
+	desc bool, limit int32) (models.Users, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, models.ErrInvalid
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	slugKey := memoryDB.CIKey(slug)
+	if _, ok := repo.db.Forums[slugKey]; !ok {
+		return nil, models.ErrDoesNotExist
+	}
+
+	sinceKey := memoryDB.CIKey(sinceNickname)
+
+	nicknameKeys := make([]string, 0, len(repo.db.ForumUsers[slugKey]))
+	for nicknameKey := range repo.db.ForumUsers[slugKey] {
+		switch {
+		case sinceNickname == "":
+		case desc && nicknameKey >= sinceKey, !desc && nicknameKey <= sinceKey:
+			continue
+		}
+		nicknameKeys = append(nicknameKeys, nicknameKey)
+	}
+
+	sort.Slice(nicknameKeys, func(i, j int) bool {
+		if desc {
+			return nicknameKeys[i] > nicknameKeys[j]
+		}
+		return nicknameKeys[i] < nicknameKeys[j]
+	})
+
+	if int(limit) < len(nicknameKeys) {
+		nicknameKeys = nicknameKeys[:limit]
+	}
+
+	users := make(models.Users, 0, len(nicknameKeys))
+	for _, nicknameKey := range nicknameKeys {
+		users = append(users, *repo.db.UsersByNickname[nicknameKey])
+	}
+
+	return users, nil
+}
+
+func (repo Repository) GetRoles(ctx context.Context, slug string) (models.ForumRoles, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	forumRoles, ok := repo.db.ForumRoles[memoryDB.CIKey(slug)]
+	if !ok {
+		return nil, models.ErrDoesNotExist
+	}
+
+	roles := make(models.ForumRoles, 0, len(forumRoles))
+	for _, role := range forumRoles {
+		roles = append(roles, role)
+	}
+
+	sort.Slice(roles, func(i, j int) bool {
+		return memoryDB.CIKey(roles[i].Nickname) < memoryDB.CIKey(roles[j].Nickname)
+	})
+
+	return roles, nil
+}
+
+func (repo Repository) GetRole(ctx context.Context, slug, nickname string) (models.ForumRole, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.ForumRole{}, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	role, ok := repo.db.ForumRoles[memoryDB.CIKey(slug)][memoryDB.CIKey(nickname)]
+	if !ok {
+		return models.ForumRole{}, models.ErrDoesNotExist
+	}
+
+	return role, nil
+}
+
+func (repo Repository) SetRole(ctx context.Context, role models.ForumRole) (models.ForumRole, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.ForumRole{}, err
+	}
+	if role.Role == models.OwnerForumRole {
+		return models.ForumRole{}, models.ErrConflict
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	slugKey, nicknameKey := memoryDB.CIKey(role.Forum), memoryDB.CIKey(role.Nickname)
+
+	storedForum, ok := repo.db.Forums[slugKey]
+	if !ok {
+		return models.ForumRole{}, models.ErrBadForeign
+	}
+	storedUser, ok := repo.db.UsersByNickname[nicknameKey]
+	if !ok {
+		return models.ForumRole{}, models.ErrBadForeign
+	}
+
+	if stored, ok := repo.db.ForumRoles[slugKey][nicknameKey]; ok && stored.Role == models.OwnerForumRole {
+		return models.ForumRole{}, models.ErrConflict
+	}
+
+	role.Forum, role.Nickname = storedForum.Slug, storedUser.Nickname
+	repo.db.ForumRoles[slugKey][nicknameKey] = role
+
+	return role, nil
+}
+
+func (repo Repository) DeleteRole(ctx context.Context, slug, nickname string) error {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	slugKey, nicknameKey := memoryDB.CIKey(slug), memoryDB.CIKey(nickname)
+
+	stored, ok := repo.db.ForumRoles[slugKey][nicknameKey]
+	switch {
+	case !ok:
+		return models.ErrDoesNotExist
+	case stored.Role == models.OwnerForumRole:
+		return models.ErrConflict
+	}
+
+	delete(repo.db.ForumRoles[slugKey], nicknameKey)
+
+	return nil
+}
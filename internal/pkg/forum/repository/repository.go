@@ -6,6 +6,7 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
 	"github.com/nickeskov/db_forum/internal/pkg/utils/database/driver/pgx/codes"
+	sqlHelpers "github.com/nickeskov/db_forum/pkg/sql"
 	"github.com/pkg/errors"
 )
 
@@ -19,8 +20,8 @@ func NewRepository(db *pgxpool.Pool) Repository {
 	}
 }
 
-func (repo Repository) Create(forum models.Forum) (models.Forum, error) {
-	err := repo.db.QueryRow(context.Background(),
+func (repo Repository) Create(ctx context.Context, forum models.Forum) (models.Forum, error) {
+	err := repo.db.QueryRow(ctx,
 		`	INSERT INTO forums (slug, title, threads, posts, owner_nickname)
 				VALUES ($1, $2, $3, $4, (
 							SELECT nickname FROM users WHERE nickname = $5
@@ -46,9 +47,9 @@ func (repo Repository) Create(forum models.Forum) (models.Forum, error) {
 	return forum, errors.WithStack(err)
 }
 
-func (repo Repository) GetBySlug(slug string) (models.Forum, error) {
+func (repo Repository) GetBySlug(ctx context.Context, slug string) (models.Forum, error) {
 	var forum models.Forum
-	err := repo.db.QueryRow(context.Background(),
+	err := repo.db.QueryRow(ctx,
 		`	SELECT slug, title, threads, posts, owner_nickname
 				FROM forums
 				WHERE slug = $1`,
@@ -72,19 +73,21 @@ func (repo Repository) GetBySlug(slug string) (models.Forum, error) {
 	return forum, nil
 }
 
-func (repo Repository) GetForumUsersBySlug(slug, sinceNickname string, desc bool, limit int32) (models.Users, error) {
+func (repo Repository) GetForumUsersBySlug(ctx context.Context, slug, sinceNickname string,
+	desc bool, limit int32) (models.Users, error) {
+
 	var err error
 	var rows pgx.Rows
 
 	if sinceNickname != "" {
-		rows, err = repo.db.Query(context.Background(),
+		rows, err = repo.db.Query(ctx,
 			sqlGetForumUserWithSince[desc],
 			slug,
 			sinceNickname,
 			limit,
 		)
 	} else {
-		rows, err = repo.db.Query(context.Background(),
+		rows, err = repo.db.Query(ctx,
 			sqlGetForumUser[desc],
 			slug,
 			limit,
@@ -117,7 +120,7 @@ func (repo Repository) GetForumUsersBySlug(slug, sinceNickname string, desc bool
 
 	if len(users) == 0 {
 		var isExists bool
-		err := repo.db.QueryRow(context.Background(),
+		err := repo.db.QueryRow(ctx,
 			`	SELECT EXISTS(
                			SELECT 1
                			FROM forums
@@ -139,3 +142,132 @@ func (repo Repository) GetForumUsersBySlug(slug, sinceNickname string, desc bool
 
 	return users, nil
 }
+
+func (repo Repository) GetRoles(ctx context.Context, slug string) (models.ForumRoles, error) {
+	rows, err := repo.db.Query(ctx,
+		`	SELECT forum_slug, user_nickname, role
+				FROM forum_roles
+				WHERE forum_slug = $1
+				ORDER BY user_nickname`,
+		slug,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error in forum repository GetRoles with slug=%s", slug)
+	}
+
+	defer rows.Close()
+
+	roles := make(models.ForumRoles, 0)
+	for rows.Next() {
+		var role models.ForumRole
+
+		if err := scanForumRole(rows, &role); err != nil {
+			return nil, errors.Wrapf(err, "error in forum repository GetRoles with slug=%s "+
+				"while scanning rows", slug)
+		}
+
+		roles = append(roles, role)
+	}
+
+	// every forum has owner role, so empty result means that forum does not exist
+	if len(roles) == 0 {
+		return nil, models.ErrDoesNotExist
+	}
+
+	return roles, nil
+}
+
+func (repo Repository) GetRole(ctx context.Context, slug, nickname string) (models.ForumRole, error) {
+	var role models.ForumRole
+
+	row := repo.db.QueryRow(ctx,
+		`	SELECT forum_slug, user_nickname, role
+				FROM forum_roles
+				WHERE forum_slug = $1 AND user_nickname = $2`,
+		slug,
+		nickname,
+	)
+
+	err := scanForumRole(row, &role)
+
+	switch {
+	case err == pgx.ErrNoRows:
+		return models.ForumRole{}, models.ErrDoesNotExist
+	case err != nil:
+		return models.ForumRole{}, errors.Wrapf(err,
+			"error in forum repository GetRole with slug=%s, nickname=%s", slug, nickname)
+	}
+
+	return role, nil
+}
+
+func (repo Repository) SetRole(ctx context.Context, role models.ForumRole) (models.ForumRole, error) {
+	if role.Role == models.OwnerForumRole {
+		return models.ForumRole{}, models.ErrConflict
+	}
+
+	row := repo.db.QueryRow(ctx,
+		`	INSERT INTO forum_roles (forum_slug, user_nickname, role)
+				VALUES ((SELECT slug FROM forums WHERE slug = $1),
+						(SELECT nickname FROM users WHERE nickname = $2),
+						$3)
+				ON CONFLICT (forum_slug, user_nickname) DO UPDATE SET role = EXCLUDED.role
+				WHERE forum_roles.role <> 'owner'
+				RETURNING forum_slug, user_nickname, role`,
+		role.Forum,
+		role.Nickname,
+		role.Role,
+	)
+
+	err := scanForumRole(row, &role)
+
+	if pgxErr := codes.ExtractPgx4ErrorCode(err); pgxErr != nil {
+		switch pgxErr.Error() {
+		case codes.ErrCodeNotNull, codes.ErrCodeForeignKey:
+			return models.ForumRole{}, models.ErrBadForeign // forum or user does not exist
+		default:
+			return models.ForumRole{}, errors.Wrapf(err,
+				"error in forum repository SetRole with role=%+v", role)
+		}
+	}
+
+	switch {
+	case err == pgx.ErrNoRows:
+		return models.ForumRole{}, models.ErrConflict // user is owner of forum
+	case err != nil:
+		return models.ForumRole{}, errors.Wrapf(err,
+			"error in forum repository SetRole with role=%+v", role)
+	}
+
+	return role, nil
+}
+
+func (repo Repository) DeleteRole(ctx context.Context, slug, nickname string) error {
+	tag, err := repo.db.Exec(ctx,
+		`	DELETE FROM forum_roles
+				WHERE forum_slug = $1 AND user_nickname = $2 AND role <> 'owner'`,
+		slug,
+		nickname,
+	)
+	if err != nil {
+		return errors.Wrapf(err,
+			"error in forum repository DeleteRole with slug=%s, nickname=%s", slug, nickname)
+	}
+
+	if tag.RowsAffected() == 0 {
+		if _, err := repo.GetRole(ctx, slug, nickname); err != nil {
+			return err
+		}
+		return models.ErrConflict // user is owner of forum
+	}
+
+	return nil
+}
+
+func scanForumRole(scanner sqlHelpers.Scanner, roleDst *models.ForumRole) error {
+	return scanner.Scan(
+		&roleDst.Forum,
+		&roleDst.Nickname,
+		&roleDst.Role,
+	)
+}
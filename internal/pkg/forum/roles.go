@@ -0,0 +1,21 @@
+package forum
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/pkg/errors"
+)
+
+// CheckNotBanned returns ErrAccessDenied if user is banned in forum
+func CheckNotBanned(ctx context.Context, repository Repository, slug, nickname string) error {
+	role, err := repository.GetRole(ctx, slug, nickname)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		return nil
+	case err != nil:
+		return err
+	case role.Role == models.BannedForumRole:
+		return models.ErrAccessDenied
+	}
+	return nil
+}
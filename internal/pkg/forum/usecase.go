@@ -1,9 +1,17 @@
 package forum
 
-import "github.com/nickeskov/db_forum/internal/pkg/models"
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+)
 
 type UseCase interface {
-	Create(user models.Forum) (models.Forum, error)
-	GetBySlug(slug string) (models.Forum, error)
-	GetForumUsersBySlug(slug, sinceNickname, desc, limit string) (models.Users, error)
+	Create(ctx context.Context, user models.Forum) (models.Forum, error)
+	GetBySlug(ctx context.Context, slug string) (models.Forum, error)
+	GetForumUsersBySlug(ctx context.Context,
+		slug, sinceNickname, desc, limit string) (models.Users, error)
+	GetRoles(ctx context.Context, slug string) (models.ForumRoles, error)
+	GetRole(ctx context.Context, slug, nickname string) (models.ForumRole, error)
+	GrantRole(ctx context.Context, role models.ForumRole) (models.ForumRole, error)
+	RevokeRole(ctx context.Context, slug, nickname string) error
 }
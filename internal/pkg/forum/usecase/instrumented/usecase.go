@@ -0,0 +1,68 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/forum"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const useCaseName = "forum"
+
+// UseCase traces every method of wrapped usecase
+type UseCase struct {
+	useCase  forum.UseCase
+	observer instrumentation.Observer
+}
+
+func NewUseCase(useCase forum.UseCase, tracer trace.Tracer) UseCase {
+	return UseCase{
+		useCase:  useCase,
+		observer: instrumentation.NewObserver(useCaseName, "usecase", nil, tracer),
+	}
+}
+
+func (useCase UseCase) Create(ctx context.Context, user models.Forum) (_ models.Forum, err error) {
+	ctx, done := useCase.observer.Start(ctx, "Create")
+	defer func() { done(err) }()
+	return useCase.useCase.Create(ctx, user)
+}
+
+func (useCase UseCase) GetBySlug(ctx context.Context, slug string) (_ models.Forum, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GetBySlug")
+	defer func() { done(err) }()
+	return useCase.useCase.GetBySlug(ctx, slug)
+}
+
+func (useCase UseCase) GetForumUsersBySlug(ctx context.Context,
+	slug, sinceNickname, desc, limit string) (_ models.Users, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "GetForumUsersBySlug")
+	defer func() { done(err) }()
+	return useCase.useCase.GetForumUsersBySlug(ctx, slug, sinceNickname, desc, limit)
+}
+
+func (useCase UseCase) GetRoles(ctx context.Context, slug string) (_ models.ForumRoles, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GetRoles")
+	defer func() { done(err) }()
+	return useCase.useCase.GetRoles(ctx, slug)
+}
+
+func (useCase UseCase) GetRole(ctx context.Context, slug, nickname string) (_ models.ForumRole, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GetRole")
+	defer func() { done(err) }()
+	return useCase.useCase.GetRole(ctx, slug, nickname)
+}
+
+func (useCase UseCase) GrantRole(ctx context.Context, role models.ForumRole) (_ models.ForumRole, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GrantRole")
+	defer func() { done(err) }()
+	return useCase.useCase.GrantRole(ctx, role)
+}
+
+func (useCase UseCase) RevokeRole(ctx context.Context, slug, nickname string) (err error) {
+	ctx, done := useCase.observer.Start(ctx, "RevokeRole")
+	defer func() { done(err) }()
+	return useCase.useCase.RevokeRole(ctx, slug, nickname)
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"github.com/nickeskov/db_forum/internal/pkg/forum"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
 	"strconv"
@@ -16,20 +17,41 @@ func NewUseCase(repository forum.Repository) UseCase {
 	}
 }
 
-func (useCase UseCase) Create(user models.Forum) (models.Forum, error) {
-	return useCase.repository.Create(user)
+func (useCase UseCase) Create(ctx context.Context, user models.Forum) (models.Forum, error) {
+	return useCase.repository.Create(ctx, user)
 }
 
-func (useCase UseCase) GetBySlug(slug string) (models.Forum, error) {
-	return useCase.repository.GetBySlug(slug)
+func (useCase UseCase) GetBySlug(ctx context.Context, slug string) (models.Forum, error) {
+	return useCase.repository.GetBySlug(ctx, slug)
 }
 
-func (useCase UseCase) GetForumUsersBySlug(slug, sinceNickname, desc, limit string) (models.Users, error) {
+func (useCase UseCase) GetForumUsersBySlug(ctx context.Context,
+	slug, sinceNickname, desc, limit string) (models.Users, error) {
+
 	convertedDesc, boolErr := strconv.ParseBool(desc)
 	convertedLimit, intErr := strconv.Atoi(limit)
 	if boolErr != nil || intErr != nil {
 		return nil, models.ErrInvalid
 	}
 
-	return useCase.repository.GetForumUsersBySlug(slug, sinceNickname, convertedDesc, int32(convertedLimit))
+	return useCase.repository.GetForumUsersBySlug(ctx, slug, sinceNickname, convertedDesc, int32(convertedLimit))
+}
+
+func (useCase UseCase) GetRoles(ctx context.Context, slug string) (models.ForumRoles, error) {
+	return useCase.repository.GetRoles(ctx, slug)
+}
+
+func (useCase UseCase) GetRole(ctx context.Context, slug, nickname string) (models.ForumRole, error) {
+	return useCase.repository.GetRole(ctx, slug, nickname)
+}
+
+func (useCase UseCase) GrantRole(ctx context.Context, role models.ForumRole) (models.ForumRole, error) {
+	if err := role.Validate(); err != nil || role.Role == models.OwnerForumRole {
+		return models.ForumRole{}, models.ErrInvalid
+	}
+	return useCase.repository.SetRole(ctx, role)
+}
+
+func (useCase UseCase) RevokeRole(ctx context.Context, slug, nickname string) error {
+	return useCase.repository.DeleteRole(ctx, slug, nickname)
 }
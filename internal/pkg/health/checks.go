@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nickeskov/db_forum/internal/pkg/migrations"
+	"github.com/pkg/errors"
+)
+
+// Check is readiness check of one dependency, it returns error when dependency is not ready
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// DatabaseCheck acquires connection from pool and pings postgres with it
+func DatabaseCheck(dbConnPool *pgxpool.Pool) Check {
+	return Check{
+		Name: "database",
+		Run: func(ctx context.Context) error {
+			conn, err := dbConnPool.Acquire(ctx)
+			if err != nil {
+				return errors.Wrap(err, "cannot acquire connection")
+			}
+			defer conn.Release()
+
+			return errors.Wrap(conn.Conn().Ping(ctx), "cannot ping database")
+		},
+	}
+}
+
+// SchemaCheck compares version of applied migrations with version expected by binary
+func SchemaCheck(migrator migrations.Migrator) Check {
+	return Check{
+		Name: "schema",
+		Run: func(ctx context.Context) error {
+			current, err := migrator.CurrentVersion(ctx)
+			if err != nil {
+				return errors.Wrap(err, "cannot get schema version")
+			}
+			if expected := migrator.LatestVersion(); current != expected {
+				return errors.Errorf("schema version is %d, binary expects %d", current, expected)
+			}
+			return nil
+		},
+	}
+}
+
+// ServerCheck fails after shutdown is started, so balancer stops routing new requests to draining instance
+func ServerCheck(draining func() bool) Check {
+	return Check{
+		Name: "server",
+		Run: func(ctx context.Context) error {
+			if draining() {
+				return errors.New("server is draining")
+			}
+			return nil
+		},
+	}
+}
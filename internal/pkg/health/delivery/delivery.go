@@ -0,0 +1,49 @@
+package delivery
+
+import (
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/health"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"net/http"
+)
+
+type Delivery struct {
+	useCase health.UseCase
+	utils   httpUtils.Utils
+}
+
+func NewDelivery(useCase health.UseCase, logger logger.Logger) Delivery {
+	return Delivery{
+		useCase: useCase,
+		utils:   httpUtils.NewDeliveryUtils(logger),
+	}
+}
+
+func (delivery Delivery) Healthz(w http.ResponseWriter, r *http.Request) {
+	delivery.writeHealth(w, r, delivery.useCase.Liveness(r.Context()))
+}
+
+// Readyz responds with 503 status if any dependency is not ready
+func (delivery Delivery) Readyz(w http.ResponseWriter, r *http.Request) {
+	delivery.writeHealth(w, r, delivery.useCase.Readiness(r.Context()))
+}
+
+func (delivery Delivery) writeHealth(w http.ResponseWriter, r *http.Request, healthModel models.Health) {
+	code := http.StatusOK
+	if healthModel.Status != models.HealthUp {
+		code = http.StatusServiceUnavailable
+	}
+
+	// probes are served outside of /api, so they don't get headers of api middlewares
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if data, err := healthModel.MarshalJSON(); err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+	} else {
+		delivery.utils.WriteResponse(w, r, code, data)
+	}
+}
@@ -0,0 +1,13 @@
+package health
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+)
+
+type UseCase interface {
+	// Liveness reports that process is able to serve requests, it doesn't touch dependencies
+	Liveness(ctx context.Context) models.Health
+	// Readiness runs all checks, service is ready only when every check is passed
+	Readiness(ctx context.Context) models.Health
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/health"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"sync"
+	"time"
+)
+
+type UseCase struct {
+	checks       []health.Check
+	checkTimeout time.Duration
+}
+
+func NewUseCase(checkTimeout time.Duration, checks ...health.Check) UseCase {
+	return UseCase{
+		checks:       checks,
+		checkTimeout: checkTimeout,
+	}
+}
+
+func (useCase UseCase) Liveness(ctx context.Context) models.Health {
+	return models.Health{
+		Status: models.HealthUp,
+	}
+}
+
+// Readiness runs checks concurrently, every check is limited by check timeout
+func (useCase UseCase) Readiness(ctx context.Context) models.Health {
+	results := make([]models.HealthCheck, len(useCase.checks))
+
+	var wg sync.WaitGroup
+	wg.Add(len(useCase.checks))
+	for i, check := range useCase.checks {
+		go func(i int, check health.Check) {
+			defer wg.Done()
+			results[i] = useCase.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	result := models.Health{
+		Status: models.HealthUp,
+		Checks: make(map[string]models.HealthCheck, len(useCase.checks)),
+	}
+	for i, check := range useCase.checks {
+		if results[i].Status != models.HealthUp {
+			result.Status = models.HealthDown
+		}
+		result.Checks[check.Name] = results[i]
+	}
+
+	return result
+}
+
+func (useCase UseCase) runCheck(ctx context.Context, check health.Check) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, useCase.checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+
+	result := models.HealthCheck{
+		Status:   models.HealthUp,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = models.HealthDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/nickeskov/db_forum/internal/pkg/health"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"testing"
+	"time"
+)
+
+func passingCheck(name string) health.Check {
+	return health.Check{Name: name, Run: func(context.Context) error { return nil }}
+}
+
+func failingCheck(name string) health.Check {
+	return health.Check{Name: name, Run: func(context.Context) error { return errors.New(name + " is down") }}
+}
+
+// blockingCheck waits till its context is done
+func blockingCheck(name string) health.Check {
+	return health.Check{Name: name, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+}
+
+func TestReadiness(t *testing.T) {
+	cases := []struct {
+		name   string
+		checks []health.Check
+		status string
+		down   []string
+	}{
+		{"NoChecks", nil, models.HealthUp, nil},
+		{"AllPassed", []health.Check{passingCheck("server"), passingCheck("database")}, models.HealthUp, nil},
+		{"OneFailed", []health.Check{passingCheck("server"), failingCheck("database")},
+			models.HealthDown, []string{"database"}},
+		{"AllFailed", []health.Check{failingCheck("server"), failingCheck("database")},
+			models.HealthDown, []string{"server", "database"}},
+		{"TimedOut", []health.Check{passingCheck("server"), blockingCheck("database")},
+			models.HealthDown, []string{"database"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := NewUseCase(50*time.Millisecond, tc.checks...).Readiness(context.Background())
+
+			if result.Status != tc.status {
+				t.Errorf("expected status %s, got %+v", tc.status, result)
+			}
+			if len(result.Checks) != len(tc.checks) {
+				t.Fatalf("expected %d checks in breakdown, got %+v", len(tc.checks), result.Checks)
+			}
+
+			isDown := make(map[string]bool)
+			for _, name := range tc.down {
+				isDown[name] = true
+			}
+			for _, check := range tc.checks {
+				checkResult := result.Checks[check.Name]
+				switch {
+				case isDown[check.Name] && (checkResult.Status != models.HealthDown || checkResult.Error == ""):
+					t.Errorf("expected %s is down with error, got %+v", check.Name, checkResult)
+				case !isDown[check.Name] && (checkResult.Status != models.HealthUp || checkResult.Error != ""):
+					t.Errorf("expected %s is up, got %+v", check.Name, checkResult)
+				case checkResult.Duration == "":
+					t.Errorf("%s has no duration", check.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestReadinessRunsChecksConcurrently(t *testing.T) {
+	const checks = 4
+	started := make(chan struct{}, checks)
+	allStarted := make(chan struct{})
+
+	// every check waits till all checks are started, so sequential run times out
+	waitingCheck := func(name string) health.Check {
+		return health.Check{Name: name, Run: func(ctx context.Context) error {
+			started <- struct{}{}
+			select {
+			case <-allStarted:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}}
+	}
+
+	go func() {
+		for i := 0; i < checks; i++ {
+			<-started
+		}
+		close(allStarted)
+	}()
+
+	useCase := NewUseCase(5*time.Second, waitingCheck("a"), waitingCheck("b"), waitingCheck("c"), waitingCheck("d"))
+	if result := useCase.Readiness(context.Background()); result.Status != models.HealthUp {
+		t.Fatalf("expected concurrent checks are up, got %+v", result)
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	// liveness doesn't run checks of dependencies
+	result := NewUseCase(time.Second, failingCheck("database")).Liveness(context.Background())
+	if result.Status != models.HealthUp || len(result.Checks) != 0 {
+		t.Fatalf("expected up status without checks, got %+v", result)
+	}
+}
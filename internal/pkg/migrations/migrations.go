@@ -0,0 +1,96 @@
+package migrations
+
+import (
+	"embed"
+	"github.com/pkg/errors"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+// migration file name format is NNNN_some_name.up.sql or NNNN_some_name.down.sql
+var reMigrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Migrations []Migration
+
+// Load returns all migrations embedded in binary sorted by version
+func Load() (Migrations, error) {
+	return load(sqlFiles)
+}
+
+// load reads migrations from sql directory of files
+func load(files fs.FS) (Migrations, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		matches := reMigrationFileName.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, errors.Errorf("bad migration file name %s", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad migration version in file name %s", entry.Name())
+		}
+
+		data, err := fs.ReadFile(files, "sql/"+entry.Name())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, errors.Errorf("migration version %d has different names: %s and %s",
+				version, migration.Name, matches[2])
+		}
+
+		switch matches[3] {
+		case "up":
+			migration.Up = string(data)
+		case "down":
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make(Migrations, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, errors.Errorf("migration %d_%s must have both up and down files",
+				migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// LatestVersion returns schema version which is expected by this binary
+func (migrations Migrations) LatestVersion() int64 {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
@@ -0,0 +1,108 @@
+package migrations
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(data string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(data)}
+	}
+
+	cases := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int64
+		err      string
+	}{
+		{"SortedByVersion", fstest.MapFS{
+			"sql/0010_second.up.sql":   file("up 10"),
+			"sql/0010_second.down.sql": file("down 10"),
+			"sql/0002_first.up.sql":    file("up 2"),
+			"sql/0002_first.down.sql":  file("down 2"),
+		}, []int64{2, 10}, ""},
+		{"Empty", fstest.MapFS{"sql": &fstest.MapFile{Mode: fs.ModeDir | 0755}}, []int64{}, ""},
+		{"BadFileName", fstest.MapFS{"sql/first.up.sql": file("up")}, nil, "bad migration file name"},
+		{"BadDirection", fstest.MapFS{"sql/0001_first.apply.sql": file("up")}, nil, "bad migration file name"},
+		{"MissingDown", fstest.MapFS{"sql/0001_first.up.sql": file("up")}, nil, "must have both up and down"},
+		{"EmptyUp", fstest.MapFS{
+			"sql/0001_first.up.sql":   file(""),
+			"sql/0001_first.down.sql": file("down"),
+		}, nil, "must have both up and down"},
+		{"DifferentNames", fstest.MapFS{
+			"sql/0001_first.up.sql":     file("up"),
+			"sql/0001_initial.down.sql": file("down"),
+		}, nil, "has different names"},
+		{"MissingDirectory", fstest.MapFS{}, nil, "sql"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			migrations, err := load(tc.files)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error with %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			versions := make([]int64, 0, len(migrations))
+			for _, migration := range migrations {
+				versions = append(versions, migration.Version)
+			}
+			if len(versions) != len(tc.versions) {
+				t.Fatalf("expected versions %v, got %v", tc.versions, versions)
+			}
+			for i := range versions {
+				if versions[i] != tc.versions[i] {
+					t.Fatalf("expected versions %v, got %v", tc.versions, versions)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("cannot load embedded migrations: %+v", err)
+	}
+
+	for i, migration := range migrations {
+		if migration.Version != int64(i+1) {
+			t.Errorf("migration %d_%s has version out of sequence, expected %d",
+				migration.Version, migration.Name, i+1)
+		}
+		if migration.Name == "" || strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			t.Errorf("migration %d_%s has empty name or file", migration.Version, migration.Name)
+		}
+	}
+
+	if latest := migrations.LatestVersion(); latest != int64(len(migrations)) {
+		t.Errorf("expected latest version %d, got %d", len(migrations), latest)
+	}
+}
+
+func TestRunsInTransaction(t *testing.T) {
+	cases := []struct {
+		sql           string
+		inTransaction bool
+	}{
+		{"CREATE INDEX posts_idx ON posts (id);", true},
+		{"-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY posts_idx ON posts (id);", false},
+		// marker must be the first line
+		{"-- comment\n-- migrate:no-transaction\nDROP INDEX posts_idx;", true},
+	}
+
+	for _, tc := range cases {
+		if got := runsInTransaction(tc.sql); got != tc.inTransaction {
+			t.Errorf("expected %v for %q, got %v", tc.inTransaction, tc.sql, got)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package migrations
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	pgx4Helpers "github.com/nickeskov/db_forum/pkg/sql/pgx/v4"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+// advisoryLockKey is pg_advisory_lock key which serializes migrations of concurrent instances
+const advisoryLockKey int64 = 0x64625f666f72756d // "db_forum" in hex
+
+// noTransactionMarker starts migration file which can't run in transaction, e.g. CREATE INDEX CONCURRENTLY
+// or batches committed by DO block. Such file must have single statement and should be safe to apply again,
+// because version is recorded after statement is done.
+const noTransactionMarker = "-- migrate:no-transaction"
+
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Statuses []Status
+
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations Migrations
+}
+
+func NewMigrator(db *pgxpool.Pool, migrations Migrations) Migrator {
+	return Migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// Up applies all pending migrations and returns them
+func (migrator Migrator) Up(ctx context.Context) (applied Migrations, err error) {
+	err = migrator.withLock(ctx, func(conn *pgxpool.Conn) error {
+		appliedVersions, err := getAppliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrator.migrations {
+			if _, ok := appliedVersions[migration.Version]; ok {
+				continue
+			}
+			if err := applyMigration(ctx, conn, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back last applied migration and returns it, nil is returned if there is nothing to roll back
+func (migrator Migrator) Down(ctx context.Context) (rolledBack *Migration, err error) {
+	err = migrator.withLock(ctx, func(conn *pgxpool.Conn) error {
+		rolledBack, err = migrator.downLast(ctx, conn)
+		return err
+	})
+
+	return rolledBack, err
+}
+
+// Redo rolls back last applied migration and applies it again
+func (migrator Migrator) Redo(ctx context.Context) (redone *Migration, err error) {
+	err = migrator.withLock(ctx, func(conn *pgxpool.Conn) error {
+		if redone, err = migrator.downLast(ctx, conn); err != nil || redone == nil {
+			return err
+		}
+		return applyMigration(ctx, conn, *redone)
+	})
+
+	return redone, err
+}
+
+func (migrator Migrator) Status(ctx context.Context) (statuses Statuses, err error) {
+	err = migrator.withLock(ctx, func(conn *pgxpool.Conn) error {
+		appliedVersions, err := getAppliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		statuses = make(Statuses, 0, len(migrator.migrations))
+		for _, migration := range migrator.migrations {
+			status := Status{Migration: migration}
+			if appliedAt, ok := appliedVersions[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// CurrentVersion returns version of last applied migration or 0 if there is no applied migrations
+func (migrator Migrator) CurrentVersion(ctx context.Context) (version int64, err error) {
+	var tableExists bool
+	err = migrator.db.QueryRow(ctx,
+		`SELECT to_regclass('schema_migrations') IS NOT NULL`,
+	).Scan(
+		&tableExists,
+	)
+	if err != nil || !tableExists {
+		return 0, errors.WithStack(err)
+	}
+
+	err = migrator.db.QueryRow(ctx,
+		`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`,
+	).Scan(
+		&version,
+	)
+
+	return version, errors.WithStack(err)
+}
+
+func (migrator Migrator) LatestVersion() int64 {
+	return migrator.migrations.LatestVersion()
+}
+
+func (migrator Migrator) downLast(ctx context.Context, conn *pgxpool.Conn) (*Migration, error) {
+	appliedVersions, err := getAppliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(migrator.migrations) - 1; i >= 0; i-- {
+		migration := migrator.migrations[i]
+		if _, ok := appliedVersions[migration.Version]; ok {
+			return &migration, rollbackMigration(ctx, conn, migration)
+		}
+	}
+
+	return nil, nil
+}
+
+func (migrator Migrator) withLock(ctx context.Context, action func(conn *pgxpool.Conn) error) (err error) {
+	conn, err := migrator.db.Acquire(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey); err != nil {
+		return errors.Wrap(err, "cannot acquire migrations lock")
+	}
+	defer func() {
+		if _, unlockErr := conn.Exec(context.Background(),
+			`SELECT pg_advisory_unlock($1)`, advisoryLockKey); unlockErr != nil && err == nil {
+			err = errors.Wrap(unlockErr, "cannot release migrations lock")
+		}
+	}()
+
+	_, err = conn.Exec(ctx, `
+			CREATE TABLE IF NOT EXISTS schema_migrations
+			(
+				version    BIGINT PRIMARY KEY                    NOT NULL,
+				name       TEXT                                  NOT NULL,
+				applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+			)`)
+	if err != nil {
+		return errors.Wrap(err, "cannot create schema_migrations table")
+	}
+
+	return action(conn)
+}
+
+func getAppliedVersions(ctx context.Context, querier pgx4Helpers.Querier) (map[int64]time.Time, error) {
+	rows, err := querier.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer rows.Close()
+
+	appliedVersions := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		appliedVersions[version] = appliedAt
+	}
+
+	return appliedVersions, errors.WithStack(rows.Err())
+}
+
+func applyMigration(ctx context.Context, conn *pgxpool.Conn, migration Migration) error {
+	return runMigration(ctx, conn, migration.Up, func(executer pgx4Helpers.Executer) error {
+		if _, err := executer.Exec(ctx, migration.Up); err != nil {
+			return errors.Wrapf(err, "cannot apply migration %d_%s", migration.Version, migration.Name)
+		}
+
+		_, err := executer.Exec(ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+			migration.Version,
+			migration.Name,
+		)
+		return errors.WithStack(err)
+	})
+}
+
+func rollbackMigration(ctx context.Context, conn *pgxpool.Conn, migration Migration) error {
+	return runMigration(ctx, conn, migration.Down, func(executer pgx4Helpers.Executer) error {
+		if _, err := executer.Exec(ctx, migration.Down); err != nil {
+			return errors.Wrapf(err, "cannot roll back migration %d_%s", migration.Version, migration.Name)
+		}
+
+		_, err := executer.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+		return errors.WithStack(err)
+	})
+}
+
+// runMigration runs action in transaction unless sql starts with noTransactionMarker
+func runMigration(ctx context.Context, conn *pgxpool.Conn, sql string,
+	action func(executer pgx4Helpers.Executer) error) error {
+
+	if runsInTransaction(sql) {
+		return inTransaction(ctx, conn, func(tx pgx.Tx) error {
+			return action(tx)
+		})
+	}
+
+	return action(conn)
+}
+
+// runsInTransaction reports whether migration file sql is run in transaction
+func runsInTransaction(sql string) bool {
+	return !strings.HasPrefix(sql, noTransactionMarker)
+}
+
+func inTransaction(ctx context.Context, conn *pgxpool.Conn, action func(tx pgx.Tx) error) (err error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		err = pgx4Helpers.FinishPgx4Transaction(ctx, tx, err)
+	}()
+
+	return action(tx)
+}
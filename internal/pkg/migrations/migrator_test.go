@@ -0,0 +1,214 @@
+package migrations
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testDSNEnv is postgres connection string for migrator tests, tests are skipped without it.
+// Migrations are applied in testSchema, which is dropped by tests.
+const (
+	testDSNEnv = "DB_FORUM_TEST_DSN"
+	testSchema = "migrator_test"
+)
+
+var testMigrations = Migrations{
+	{Version: 1, Name: "table", Up: `CREATE TABLE items (id INTEGER);`, Down: `DROP TABLE items;`},
+	{Version: 2, Name: "index",
+		Up:   noTransactionMarker + "\nCREATE INDEX CONCURRENTLY items_id_idx ON items (id);",
+		Down: noTransactionMarker + "\nDROP INDEX CONCURRENTLY IF EXISTS items_id_idx;"},
+}
+
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	ctx := context.Background()
+
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("cannot parse %s: %+v", testDSNEnv, err)
+	}
+	poolConfig.ConnConfig.RuntimeParams["search_path"] = testSchema
+
+	db, err := pgxpool.ConnectConfig(ctx, poolConfig)
+	if err != nil {
+		t.Fatalf("cannot connect to postgres: %+v", err)
+	}
+
+	dropSchema := func() {
+		if _, err := db.Exec(ctx, `DROP SCHEMA IF EXISTS `+testSchema+` CASCADE`); err != nil {
+			t.Fatalf("cannot drop test schema: %+v", err)
+		}
+	}
+	dropSchema()
+	if _, err := db.Exec(ctx, `CREATE SCHEMA `+testSchema); err != nil {
+		t.Fatalf("cannot create test schema: %+v", err)
+	}
+
+	t.Cleanup(func() {
+		dropSchema()
+		db.Close()
+	})
+
+	return db
+}
+
+func expectVersion(t *testing.T, migrator Migrator, expected int64) {
+	version, err := migrator.CurrentVersion(context.Background())
+	if err != nil {
+		t.Fatalf("cannot get current version: %+v", err)
+	}
+	if version != expected {
+		t.Fatalf("expected version %d, got %d", expected, version)
+	}
+}
+
+func expectRelation(t *testing.T, db *pgxpool.Pool, name string, exists bool) {
+	var found bool
+	if err := db.QueryRow(context.Background(),
+		`SELECT to_regclass($1) IS NOT NULL`, name).Scan(&found); err != nil {
+		t.Fatalf("cannot check relation %s: %+v", name, err)
+	}
+	if found != exists {
+		t.Fatalf("expected relation %s exists %v, got %v", name, exists, found)
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	db := newTestPool(t)
+	migrator := NewMigrator(db, testMigrations)
+	ctx := context.Background()
+
+	expectVersion(t, migrator, 0)
+
+	applied, err := migrator.Up(ctx)
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("expected 2 applied migrations, got %v, %+v", applied, err)
+	}
+	expectVersion(t, migrator, 2)
+	expectRelation(t, db, "items_id_idx", true)
+
+	if applied, err := migrator.Up(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("expected no applied migrations, got %v, %+v", applied, err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil || len(statuses) != 2 || statuses[0].AppliedAt == nil || statuses[1].AppliedAt == nil {
+		t.Fatalf("expected 2 applied statuses, got %+v, %+v", statuses, err)
+	}
+
+	if rolledBack, err := migrator.Down(ctx); err != nil || rolledBack == nil || rolledBack.Version != 2 {
+		t.Fatalf("expected rolled back migration 2, got %+v, %+v", rolledBack, err)
+	}
+	expectVersion(t, migrator, 1)
+	expectRelation(t, db, "items_id_idx", false)
+
+	if redone, err := migrator.Redo(ctx); err != nil || redone == nil || redone.Version != 1 {
+		t.Fatalf("expected redone migration 1, got %+v, %+v", redone, err)
+	}
+	expectVersion(t, migrator, 1)
+	expectRelation(t, db, "items", true)
+
+	if rolledBack, err := migrator.Down(ctx); err != nil || rolledBack == nil || rolledBack.Version != 1 {
+		t.Fatalf("expected rolled back migration 1, got %+v, %+v", rolledBack, err)
+	}
+	if rolledBack, err := migrator.Down(ctx); err != nil || rolledBack != nil {
+		t.Fatalf("expected nothing to roll back, got %+v, %+v", rolledBack, err)
+	}
+	expectVersion(t, migrator, 0)
+	expectRelation(t, db, "items", false)
+}
+
+func TestMigratorRollsBackFailedMigration(t *testing.T) {
+	db := newTestPool(t)
+	migrator := NewMigrator(db, Migrations{
+		testMigrations[0],
+		{Version: 2, Name: "broken", Up: `CREATE TABLE broken (id INTEGER); SELECT 1 / 0;`, Down: `DROP TABLE broken;`},
+	})
+
+	if _, err := migrator.Up(context.Background()); err == nil {
+		t.Fatalf("broken migration is applied")
+	}
+
+	expectVersion(t, migrator, 1)
+	expectRelation(t, db, "broken", false)
+}
+
+func TestMigratorWaitsForLock(t *testing.T) {
+	db := newTestPool(t)
+	migrator := NewMigrator(db, testMigrations)
+	ctx := context.Background()
+
+	// other instance is migrating
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("cannot acquire connection: %+v", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey); err != nil {
+		t.Fatalf("cannot acquire lock: %+v", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	if _, err := migrator.Up(timeoutCtx); err == nil {
+		t.Fatalf("migrations are applied while lock is held by other connection")
+	}
+	expectVersion(t, migrator, 0)
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, advisoryLockKey); err != nil {
+		t.Fatalf("cannot release lock: %+v", err)
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("cannot apply migrations after lock is released: %+v", err)
+	}
+	expectVersion(t, migrator, 2)
+}
+
+func TestMigratorConcurrentUp(t *testing.T) {
+	db := newTestPool(t)
+
+	const instances = 4
+
+	var wg sync.WaitGroup
+	applied := make(chan int, instances)
+	errs := make(chan error, instances)
+
+	for i := 0; i < instances; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			migrations, err := NewMigrator(db, testMigrations).Up(context.Background())
+			applied <- len(migrations)
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(applied)
+	close(errs)
+
+	total := 0
+	for count := range applied {
+		total += count
+	}
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent up failed: %+v", err)
+		}
+	}
+
+	if total != len(testMigrations) {
+		t.Fatalf("expected every migration applied once, applied %d of %d", total, len(testMigrations))
+	}
+}
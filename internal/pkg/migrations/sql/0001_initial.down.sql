@@ -0,0 +1,10 @@
+DROP VIEW IF EXISTS forums_users;
+
+DROP TABLE IF EXISTS posts, votes, threads, forums_users_nicknames, forums, users CASCADE;
+
+DROP FUNCTION IF EXISTS add_new_forum_user() CASCADE;
+DROP FUNCTION IF EXISTS increment_forum_threads() CASCADE;
+DROP FUNCTION IF EXISTS insert_vote() CASCADE;
+DROP FUNCTION IF EXISTS update_vote() CASCADE;
+DROP FUNCTION IF EXISTS increment_forum_posts() CASCADE;
+DROP FUNCTION IF EXISTS add_path_to_post() CASCADE;
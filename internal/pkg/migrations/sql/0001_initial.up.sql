@@ -1,5 +1,5 @@
-DROP SCHEMA IF EXISTS public CASCADE;
-CREATE SCHEMA public;
+-- Databases created by configs/database/sql/database.sql before migrations were added already have this schema
+-- without schema_migrations table, so every statement here must succeed on them too.
 
 CREATE EXTENSION IF NOT EXISTS CITEXT;
 
@@ -40,7 +40,7 @@ CREATE UNLOGGED TABLE IF NOT EXISTS forums_users_nicknames
     CONSTRAINT forums_users_nicknames_pk PRIMARY KEY (forum_slug, user_nickname)
 );
 
-CREATE VIEW forums_users AS
+CREATE OR REPLACE VIEW forums_users AS
 SELECT fu_nicknames.forum_slug, fu_nicknames.user_nickname, u.nickname, u.email, u.fullname, u.about
 FROM forums_users_nicknames AS fu_nicknames
          JOIN users AS u ON fu_nicknames.user_nickname = u.nickname;
@@ -118,12 +118,16 @@ BEGIN
 END;
 $add_forum_user$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS add_new_forum_user_after_insert_in_threads ON threads;
+
 CREATE TRIGGER add_new_forum_user_after_insert_in_threads
     AFTER INSERT
     ON threads
     FOR EACH ROW
 EXECUTE PROCEDURE add_new_forum_user();
 
+DROP TRIGGER IF EXISTS add_new_forum_user_after_insert_in_posts ON posts;
+
 CREATE TRIGGER add_new_forum_user_after_insert_in_posts
     AFTER INSERT
     ON posts
@@ -144,6 +148,8 @@ BEGIN
 END;
 $increment_forum_threads$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS increment_forum_threads_after_insert_on_threads ON threads;
+
 CREATE TRIGGER increment_forum_threads_after_insert_on_threads
     AFTER INSERT
     ON threads
@@ -164,6 +170,8 @@ BEGIN
 END;
 $insert_vote$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS insert_vote_after_insert_on_threads ON votes;
+
 CREATE TRIGGER insert_vote_after_insert_on_threads
     AFTER INSERT
     ON votes
@@ -184,6 +192,8 @@ BEGIN
 END;
 $update_vote$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS update_vote_after_insert_on_threads ON votes;
+
 CREATE TRIGGER update_vote_after_insert_on_threads
     AFTER UPDATE
     ON votes
@@ -204,6 +214,8 @@ BEGIN
 END;
 $increment_forum_posts$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS increment_forum_posts_after_insert_on_threads ON posts;
+
 CREATE TRIGGER increment_forum_posts_after_insert_on_threads
     BEFORE INSERT
     ON posts
@@ -293,4 +305,3 @@ CREATE INDEX IF NOT EXISTS users_nickname_email_include_other_idx ON users (nick
 --
 
 ANALYZE;
-VACUUM ANALYZE;
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS update_forum_posts_after_update_is_deleted_on_posts ON posts;
+
+DROP FUNCTION IF EXISTS update_forum_posts_on_soft_delete() CASCADE;
+
+-- deleted posts were not counted in forums
+UPDATE forums AS f
+SET posts = (SELECT COUNT(*) FROM posts AS p WHERE p.forum_slug = f.slug);
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS is_deleted;
@@ -0,0 +1,29 @@
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS is_deleted BOOLEAN DEFAULT FALSE NOT NULL;
+
+--
+
+DROP FUNCTION IF EXISTS update_forum_posts_on_soft_delete() CASCADE;
+CREATE OR REPLACE FUNCTION update_forum_posts_on_soft_delete() RETURNS TRIGGER AS
+$update_forum_posts_on_soft_delete$
+BEGIN
+    IF NEW.is_deleted THEN
+        UPDATE forums
+        SET posts = posts - 1
+        WHERE slug = NEW.forum_slug;
+    ELSE
+        UPDATE forums
+        SET posts = posts + 1
+        WHERE slug = NEW.forum_slug;
+    END IF;
+
+    RETURN NULL;
+END;
+$update_forum_posts_on_soft_delete$ LANGUAGE plpgsql;
+
+CREATE TRIGGER update_forum_posts_after_update_is_deleted_on_posts
+    AFTER UPDATE OF is_deleted
+    ON posts
+    FOR EACH ROW
+    WHEN (OLD.is_deleted IS DISTINCT FROM NEW.is_deleted)
+EXECUTE PROCEDURE update_forum_posts_on_soft_delete();
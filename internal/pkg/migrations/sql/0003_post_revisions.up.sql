@@ -0,0 +1,17 @@
+CREATE UNLOGGED TABLE IF NOT EXISTS post_revisions
+(
+    post_id         BIGINT                                                NOT NULL,
+    number          INTEGER                                               NOT NULL,
+    author_nickname CITEXT                                                NOT NULL,
+    message         TEXT                                                  NOT NULL,
+    created         TIMESTAMP(3) WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (post_id) REFERENCES posts (id)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE,
+    FOREIGN KEY (author_nickname) REFERENCES users (nickname)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE,
+
+    CONSTRAINT post_revisions_pk PRIMARY KEY (post_id, number)
+);
@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS set_post_search_vector ON posts;
+DROP TRIGGER IF EXISTS set_thread_search_vector ON threads;
+
+DROP FUNCTION IF EXISTS set_post_search_vector();
+DROP FUNCTION IF EXISTS set_thread_search_vector();
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE threads
+    DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,46 @@
+-- Columns without default are added without rewrite of tables, vectors of new and edited rows are set by triggers.
+-- Existing rows are filled in batches by 0010_search_backfill and indexes are built concurrently by next
+-- migrations, search misses old rows until then.
+
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Triggers and procedures
+
+CREATE OR REPLACE FUNCTION set_post_search_vector() RETURNS TRIGGER AS
+$set_post_search_vector$
+BEGIN
+    NEW.search_vector := to_tsvector('simple', NEW.message);
+    RETURN NEW;
+END;
+$set_post_search_vector$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_post_search_vector ON posts;
+
+CREATE TRIGGER set_post_search_vector
+    BEFORE INSERT OR UPDATE OF message
+    ON posts
+    FOR EACH ROW
+EXECUTE PROCEDURE set_post_search_vector();
+
+--
+
+CREATE OR REPLACE FUNCTION set_thread_search_vector() RETURNS TRIGGER AS
+$set_thread_search_vector$
+BEGIN
+    NEW.search_vector := setweight(to_tsvector('simple', NEW.title), 'A') ||
+                         setweight(to_tsvector('simple', NEW.message), 'B');
+    RETURN NEW;
+END;
+$set_thread_search_vector$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_thread_search_vector ON threads;
+
+CREATE TRIGGER set_thread_search_vector
+    BEFORE INSERT OR UPDATE OF title, message
+    ON threads
+    FOR EACH ROW
+EXECUTE PROCEDURE set_thread_search_vector();
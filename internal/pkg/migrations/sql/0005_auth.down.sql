@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS sessions;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS password_hash;
@@ -0,0 +1,19 @@
+-- users created before authentication have no password and can't log in
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS password_hash TEXT;
+
+CREATE UNLOGGED TABLE IF NOT EXISTS sessions
+(
+    token_hash    BYTEA PRIMARY KEY                                     NOT NULL,
+    user_nickname CITEXT                                                NOT NULL,
+    created       TIMESTAMP(3) WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    expires       TIMESTAMP(3) WITH TIME ZONE                           NOT NULL,
+
+    FOREIGN KEY (user_nickname) REFERENCES users (nickname)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE
+);
+
+-- Indexes
+
+CREATE INDEX IF NOT EXISTS sessions_user_nickname_expires_idx ON sessions (user_nickname, expires);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS forum_roles;
+
+DROP FUNCTION IF EXISTS add_forum_owner_role() CASCADE;
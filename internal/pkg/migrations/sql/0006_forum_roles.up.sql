@@ -0,0 +1,41 @@
+CREATE UNLOGGED TABLE IF NOT EXISTS forum_roles
+(
+    forum_slug    CITEXT                   NOT NULL,
+    user_nickname CITEXT COLLATE ucs_basic NOT NULL,
+    role          TEXT                     NOT NULL,
+
+    FOREIGN KEY (forum_slug) REFERENCES forums (slug)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE,
+    FOREIGN KEY (user_nickname) REFERENCES users (nickname)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE,
+
+    CONSTRAINT forum_roles_role_check CHECK (role IN ('owner', 'moderator', 'member', 'banned')),
+    CONSTRAINT forum_roles_pk PRIMARY KEY (forum_slug, user_nickname)
+);
+
+-- owner role is maintained by trigger, so every forum has exactly one owner from forums.owner_nickname
+
+DROP FUNCTION IF EXISTS add_forum_owner_role() CASCADE;
+CREATE OR REPLACE FUNCTION add_forum_owner_role() RETURNS TRIGGER AS
+$add_forum_owner_role$
+BEGIN
+    INSERT INTO forum_roles (forum_slug, user_nickname, role)
+    VALUES (NEW.slug, NEW.owner_nickname, 'owner')
+    ON CONFLICT (forum_slug, user_nickname) DO UPDATE SET role = 'owner';
+
+    RETURN NULL;
+END;
+$add_forum_owner_role$ LANGUAGE plpgsql;
+
+CREATE TRIGGER add_forum_owner_role_after_insert_on_forums
+    AFTER INSERT
+    ON forums
+    FOR EACH ROW
+EXECUTE PROCEDURE add_forum_owner_role();
+
+INSERT INTO forum_roles (forum_slug, user_nickname, role)
+SELECT slug, owner_nickname, 'owner'
+FROM forums
+ON CONFLICT DO NOTHING;
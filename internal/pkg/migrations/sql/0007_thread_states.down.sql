@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS threads_forum_slug_is_pinned_created_idx;
+DROP INDEX IF EXISTS threads_forum_slug_is_pinned_created_desc_idx;
+
+ALTER TABLE threads
+    DROP CONSTRAINT IF EXISTS threads_state_check,
+    DROP COLUMN IF EXISTS state,
+    DROP COLUMN IF EXISTS is_pinned;
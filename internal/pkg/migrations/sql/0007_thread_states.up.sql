@@ -0,0 +1,13 @@
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS state     TEXT    DEFAULT 'open' NOT NULL,
+    ADD COLUMN IF NOT EXISTS is_pinned BOOLEAN DEFAULT FALSE  NOT NULL,
+    ADD CONSTRAINT threads_state_check CHECK (state IN ('open', 'locked', 'archived'));
+
+-- Indexes
+
+-- pinned threads are listed first in both created orders
+CREATE INDEX IF NOT EXISTS threads_forum_slug_is_pinned_created_idx
+    ON threads (forum_slug, is_pinned DESC, created);
+
+CREATE INDEX IF NOT EXISTS threads_forum_slug_is_pinned_created_desc_idx
+    ON threads (forum_slug, is_pinned DESC, created DESC);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS threads_forum_slug_is_pinned_created_id_idx;
+DROP INDEX IF EXISTS threads_forum_slug_is_pinned_created_id_desc_idx;
+
+CREATE INDEX IF NOT EXISTS threads_forum_slug_is_pinned_created_idx
+    ON threads (forum_slug, is_pinned DESC, created);
+
+CREATE INDEX IF NOT EXISTS threads_forum_slug_is_pinned_created_desc_idx
+    ON threads (forum_slug, is_pinned DESC, created DESC);
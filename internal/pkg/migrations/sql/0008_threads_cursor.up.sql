@@ -0,0 +1,10 @@
+-- id breaks ties of threads with the same created time in cursor pagination
+
+DROP INDEX IF EXISTS threads_forum_slug_is_pinned_created_idx;
+DROP INDEX IF EXISTS threads_forum_slug_is_pinned_created_desc_idx;
+
+CREATE INDEX IF NOT EXISTS threads_forum_slug_is_pinned_created_id_idx
+    ON threads (forum_slug, is_pinned DESC, created, id);
+
+CREATE INDEX IF NOT EXISTS threads_forum_slug_is_pinned_created_id_desc_idx
+    ON threads (forum_slug, is_pinned DESC, created DESC, id DESC);
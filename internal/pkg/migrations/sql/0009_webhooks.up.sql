@@ -0,0 +1,45 @@
+CREATE UNLOGGED TABLE IF NOT EXISTS webhooks
+(
+    id         BIGSERIAL PRIMARY KEY                                 NOT NULL,
+    forum_slug CITEXT                                                NOT NULL,
+    url        TEXT                                                  NOT NULL,
+    secret     TEXT                                                  NOT NULL,
+    events     TEXT[]                                                NOT NULL,
+    created    TIMESTAMP(3) WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (forum_slug) REFERENCES forums (slug)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE
+);
+
+-- deliveries are outbox of webhook events: they are inserted in transaction of data change
+-- and are sent by dispatcher, which claims due pending deliveries by moving next_attempt forward
+
+CREATE UNLOGGED TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              BIGSERIAL PRIMARY KEY                                 NOT NULL,
+    webhook_id      BIGINT                                                NOT NULL,
+    event           TEXT                                                  NOT NULL,
+    payload         JSONB                                                 NOT NULL,
+    status          TEXT                        DEFAULT 'pending'         NOT NULL,
+    attempts        INTEGER                     DEFAULT 0                 NOT NULL,
+    next_attempt    TIMESTAMP(3) WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    response_status INTEGER,
+    error           TEXT,
+    created         TIMESTAMP(3) WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    delivered       TIMESTAMP(3) WITH TIME ZONE,
+
+    FOREIGN KEY (webhook_id) REFERENCES webhooks (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending', 'delivered', 'failed'))
+);
+
+-- Indexes
+
+CREATE INDEX IF NOT EXISTS webhooks_forum_slug_idx ON webhooks (forum_slug);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_id_idx ON webhook_deliveries (webhook_id, id);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_next_attempt_idx ON webhook_deliveries (next_attempt)
+    WHERE status = 'pending';
@@ -0,0 +1,2 @@
+-- vectors are dropped with their columns by 0004_search down migration
+SELECT 1;
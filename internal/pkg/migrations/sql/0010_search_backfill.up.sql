@@ -0,0 +1,38 @@
+-- migrate:no-transaction
+-- Every batch is committed, so rows are locked only while their batch is updated. Rows which are filled already,
+-- by trigger or by interrupted run of this migration, are skipped.
+DO
+$search_backfill$
+    DECLARE
+        batch_size CONSTANT BIGINT := 10000;
+        batch_start         BIGINT;
+        max_id              BIGINT;
+    BEGIN
+        SELECT COALESCE(MAX(id), 0) FROM posts INTO max_id;
+        batch_start := 0;
+        WHILE batch_start < max_id
+            LOOP
+                UPDATE posts
+                SET search_vector = to_tsvector('simple', message)
+                WHERE id > batch_start
+                  AND id <= batch_start + batch_size
+                  AND search_vector IS NULL;
+                COMMIT;
+                batch_start := batch_start + batch_size;
+            END LOOP;
+
+        SELECT COALESCE(MAX(id), 0) FROM threads INTO max_id;
+        batch_start := 0;
+        WHILE batch_start < max_id
+            LOOP
+                UPDATE threads
+                SET search_vector = setweight(to_tsvector('simple', title), 'A') ||
+                                    setweight(to_tsvector('simple', message), 'B')
+                WHERE id > batch_start
+                  AND id <= batch_start + batch_size
+                  AND search_vector IS NULL;
+                COMMIT;
+                batch_start := batch_start + batch_size;
+            END LOOP;
+    END
+$search_backfill$;
@@ -0,0 +1,3 @@
+-- migrate:no-transaction
+-- Failed concurrent build leaves invalid index, it must be dropped before migration is applied again.
+CREATE INDEX CONCURRENTLY threads_search_vector_idx ON threads USING GIN (search_vector);
@@ -3,6 +3,8 @@ package models
 //easyjson:json
 type Error struct {
 	Message string `json:"message"`
+	// RequestID is set in responses, so client can report id of failed request
+	RequestID string `json:"request_id,omitempty"`
 }
 
 //easyjson:json
@@ -24,6 +26,7 @@ var (
 	ErrInvalid      = NewError("entity is invalid")
 	ErrConflict     = NewError("entity conflicts with other entity")
 	ErrAccessDenied = NewError("access to entity denied")
+	ErrUnauthorized = NewError("authentication required")
 	ErrValidation   = NewError("entity validation failed")
 	ErrBadForeign   = NewError("entity have bad foreign relation")
 )
@@ -26,7 +26,7 @@ func easyjsonE34310f8DecodeGithubComNickeskovDbForumInternalPkgModels(in *jlexer
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(Errors, 0, 4)
+				*out = make(Errors, 0, 2)
 			} else {
 				*out = Errors{}
 			}
@@ -104,6 +104,8 @@ func easyjsonE34310f8DecodeGithubComNickeskovDbForumInternalPkgModels1(in *jlexe
 		switch key {
 		case "message":
 			out.Message = string(in.String())
+		case "request_id":
+			out.RequestID = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -123,6 +125,11 @@ func easyjsonE34310f8EncodeGithubComNickeskovDbForumInternalPkgModels1(out *jwri
 		out.RawString(prefix[1:])
 		out.String(string(in.Message))
 	}
+	if in.RequestID != "" {
+		const prefix string = ",\"request_id\":"
+		out.RawString(prefix)
+		out.String(string(in.RequestID))
+	}
 	out.RawByte('}')
 }
 
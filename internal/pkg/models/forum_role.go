@@ -0,0 +1,46 @@
+package models
+
+const (
+	OwnerForumRole     = "owner"
+	ModeratorForumRole = "moderator"
+	MemberForumRole    = "member"
+	BannedForumRole    = "banned"
+)
+
+//easyjson:json
+type ForumRole struct {
+	Forum    string `json:"forum"`
+	Nickname string `json:"nickname"`
+	Role     string `json:"role"`
+}
+
+//easyjson:json
+type ForumRoles []ForumRole
+
+func (role ForumRole) Validate() error {
+	switch role.Role {
+	case OwnerForumRole, ModeratorForumRole, MemberForumRole, BannedForumRole:
+		return nil
+	default:
+		return ErrValidation
+	}
+}
+
+// IsModerator reports whether role allows to edit posts and threads of other users in forum
+func (role ForumRole) IsModerator() bool {
+	return role.Role == OwnerForumRole || role.Role == ModeratorForumRole
+}
+
+// CanManage reports whether user with this role can grant or revoke other role in the same forum,
+// empty other role means user without role. Owner manages moderators, members and banned users,
+// moderators manage only members and banned users. Owner role is bound to forum and is never managed.
+func (role ForumRole) CanManage(other string) bool {
+	switch other {
+	case ModeratorForumRole:
+		return role.Role == OwnerForumRole
+	case MemberForumRole, BannedForumRole, "":
+		return role.IsModerator()
+	default:
+		return false
+	}
+}
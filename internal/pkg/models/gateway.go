@@ -0,0 +1,26 @@
+package models
+
+const (
+	SubscribeAction   = "subscribe"
+	UnsubscribeAction = "unsubscribe"
+
+	// SubscribedMessage lists all topics of connection after subscribe or unsubscribe
+	SubscribedMessage = "subscribed"
+	ErrorMessage      = "error"
+)
+
+//easyjson:json
+type GatewayRequest struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+}
+
+//easyjson:json
+type GatewayMessage struct {
+	// Type is SubscribedMessage, ErrorMessage or type of event
+	Type    string      `json:"type"`
+	ID      uint64      `json:"id,omitempty"`
+	Topics  []string    `json:"topics,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+	Message string      `json:"message,omitempty"`
+}
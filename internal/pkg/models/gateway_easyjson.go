@@ -0,0 +1,260 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonAa2664a0DecodeGithubComNickeskovDbForumInternalPkgModels(in *jlexer.Lexer, out *GatewayRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "action":
+			out.Action = string(in.String())
+		case "topics":
+			if in.IsNull() {
+				in.Skip()
+				out.Topics = nil
+			} else {
+				in.Delim('[')
+				if out.Topics == nil {
+					if !in.IsDelim(']') {
+						out.Topics = make([]string, 0, 4)
+					} else {
+						out.Topics = []string{}
+					}
+				} else {
+					out.Topics = (out.Topics)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Topics = append(out.Topics, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAa2664a0EncodeGithubComNickeskovDbForumInternalPkgModels(out *jwriter.Writer, in GatewayRequest) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"action\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Action))
+	}
+	{
+		const prefix string = ",\"topics\":"
+		out.RawString(prefix)
+		if in.Topics == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Topics {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v GatewayRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAa2664a0EncodeGithubComNickeskovDbForumInternalPkgModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v GatewayRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAa2664a0EncodeGithubComNickeskovDbForumInternalPkgModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *GatewayRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAa2664a0DecodeGithubComNickeskovDbForumInternalPkgModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *GatewayRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAa2664a0DecodeGithubComNickeskovDbForumInternalPkgModels(l, v)
+}
+func easyjsonAa2664a0DecodeGithubComNickeskovDbForumInternalPkgModels1(in *jlexer.Lexer, out *GatewayMessage) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "type":
+			out.Type = string(in.String())
+		case "id":
+			out.ID = uint64(in.Uint64())
+		case "topics":
+			if in.IsNull() {
+				in.Skip()
+				out.Topics = nil
+			} else {
+				in.Delim('[')
+				if out.Topics == nil {
+					if !in.IsDelim(']') {
+						out.Topics = make([]string, 0, 4)
+					} else {
+						out.Topics = []string{}
+					}
+				} else {
+					out.Topics = (out.Topics)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					v4 = string(in.String())
+					out.Topics = append(out.Topics, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "data":
+			if m, ok := out.Data.(easyjson.Unmarshaler); ok {
+				m.UnmarshalEasyJSON(in)
+			} else if m, ok := out.Data.(json.Unmarshaler); ok {
+				_ = m.UnmarshalJSON(in.Raw())
+			} else {
+				out.Data = in.Interface()
+			}
+		case "message":
+			out.Message = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAa2664a0EncodeGithubComNickeskovDbForumInternalPkgModels1(out *jwriter.Writer, in GatewayMessage) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Type))
+	}
+	if in.ID != 0 {
+		const prefix string = ",\"id\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.ID))
+	}
+	if len(in.Topics) != 0 {
+		const prefix string = ",\"topics\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v5, v6 := range in.Topics {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v6))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Data != nil {
+		const prefix string = ",\"data\":"
+		out.RawString(prefix)
+		if m, ok := in.Data.(easyjson.Marshaler); ok {
+			m.MarshalEasyJSON(out)
+		} else if m, ok := in.Data.(json.Marshaler); ok {
+			out.Raw(m.MarshalJSON())
+		} else {
+			out.Raw(json.Marshal(in.Data))
+		}
+	}
+	if in.Message != "" {
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v GatewayMessage) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAa2664a0EncodeGithubComNickeskovDbForumInternalPkgModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v GatewayMessage) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAa2664a0EncodeGithubComNickeskovDbForumInternalPkgModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *GatewayMessage) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAa2664a0DecodeGithubComNickeskovDbForumInternalPkgModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *GatewayMessage) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAa2664a0DecodeGithubComNickeskovDbForumInternalPkgModels1(l, v)
+}
@@ -0,0 +1,20 @@
+package models
+
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+//easyjson:json
+type Health struct {
+	Status string `json:"status"`
+	// Checks is breakdown per dependency, it is empty in liveness response
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+//easyjson:json
+type HealthCheck struct {
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
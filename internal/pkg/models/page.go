@@ -0,0 +1,22 @@
+package models
+
+// Pages are returned by list endpoints instead of plain lists when cursor pagination is requested,
+// NextCursor is empty on the last page.
+
+//easyjson:json
+type UsersPage struct {
+	Items      Users  `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+//easyjson:json
+type ThreadsPage struct {
+	Items      Threads `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+//easyjson:json
+type PostsPage struct {
+	Items      Posts  `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
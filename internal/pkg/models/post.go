@@ -2,16 +2,24 @@ package models
 
 import "time"
 
+const (
+	// DeletedPostMessage replaces message of deleted posts in responses
+	DeletedPostMessage = "[deleted]"
+	// MovedPostMessageFormat is message of stub left in place of posts split into thread with given id
+	MovedPostMessageFormat = "[moved to thread %d]"
+)
+
 //easyjson:json
 type Post struct {
-	Author   string    `json:"author"`
-	Message  string    `json:"message"`
-	Created  time.Time `json:"created,omitempty"`
-	Forum    string    `json:"forum,omitempty"`
-	ID       int64     `json:"id,omitempty"`
-	IsEdited bool      `json:"isEdited,omitempty"`
-	Parent   int64     `json:"parent,omitempty"`
-	Thread   int32     `json:"thread,omitempty"`
+	Author    string    `json:"author"`
+	Message   string    `json:"message"`
+	Created   time.Time `json:"created,omitempty"`
+	Forum     string    `json:"forum,omitempty"`
+	ID        int64     `json:"id,omitempty"`
+	IsEdited  bool      `json:"isEdited,omitempty"`
+	IsDeleted bool      `json:"isDeleted,omitempty"`
+	Parent    int64     `json:"parent,omitempty"`
+	Thread    int32     `json:"thread,omitempty"`
 }
 
 //easyjson:json
@@ -26,12 +34,33 @@ type PostUpdate struct {
 //easyjson:json
 type PostUpdates []PostUpdate
 
+//easyjson:json
+type PostSplit struct {
+	Title string `json:"title"`
+	Slug  string `json:"slug,omitempty"`
+	// Stub leaves post with link to new thread in place of split posts
+	Stub bool `json:"stub,omitempty"`
+}
+
+//easyjson:json
+type PostRevision struct {
+	Post    int64     `json:"post"`
+	Number  int32     `json:"number"`
+	Author  string    `json:"author"`  // editor of post
+	Message string    `json:"message"` // message of post before edit
+	Created time.Time `json:"created"`
+}
+
+//easyjson:json
+type PostRevisions []PostRevision
+
 //easyjson:json
 type PostFullInfo struct {
-	Author *User   `json:"author,omitempty"`
-	Forum  *Forum  `json:"forum,omitempty"`
-	Post   *Post   `json:"post,omitempty"`
-	Thread *Thread `json:"thread,omitempty"`
+	Author    *User          `json:"author,omitempty"`
+	Forum     *Forum         `json:"forum,omitempty"`
+	Post      *Post          `json:"post,omitempty"`
+	Thread    *Thread        `json:"thread,omitempty"`
+	Revisions *PostRevisions `json:"revisions,omitempty"`
 }
 
 //easyjson:json
@@ -43,3 +72,38 @@ func (post Post) Validate() error {
 	}
 	return nil
 }
+
+// HideDeleted replaces message of deleted post with tombstone, deleted post keeps its place in thread tree
+func (post Post) HideDeleted() Post {
+	if post.IsDeleted {
+		post.Message = DeletedPostMessage
+	}
+	return post
+}
+
+func (posts Posts) HideDeleted() Posts {
+	for i := range posts {
+		posts[i] = posts[i].HideDeleted()
+	}
+	return posts
+}
+
+// HideDeleted replaces messages of revisions with tombstone if their post is deleted
+func (revisions PostRevisions) HideDeleted(post Post) PostRevisions {
+	if post.IsDeleted {
+		for i := range revisions {
+			revisions[i].Message = DeletedPostMessage
+		}
+	}
+	return revisions
+}
+
+func (split PostSplit) Validate() error {
+	if split.Title == "" {
+		return ErrValidation
+	}
+	if split.Slug != "" {
+		return validateSlug(split.Slug)
+	}
+	return nil
+}
@@ -26,7 +26,7 @@ func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels(in *jlexer
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(PostsFulls, 0, 2)
+				*out = make(PostsFulls, 0, 1)
 			} else {
 				*out = PostsFulls{}
 			}
@@ -294,7 +294,7 @@ func (v *PostUpdate) UnmarshalJSON(data []byte) error {
 func (v *PostUpdate) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels3(l, v)
 }
-func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels4(in *jlexer.Lexer, out *PostFullInfo) {
+func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels4(in *jlexer.Lexer, out *PostSplit) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -313,46 +313,12 @@ func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels4(in *jlexe
 			continue
 		}
 		switch key {
-		case "author":
-			if in.IsNull() {
-				in.Skip()
-				out.Author = nil
-			} else {
-				if out.Author == nil {
-					out.Author = new(User)
-				}
-				easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels5(in, out.Author)
-			}
-		case "forum":
-			if in.IsNull() {
-				in.Skip()
-				out.Forum = nil
-			} else {
-				if out.Forum == nil {
-					out.Forum = new(Forum)
-				}
-				(*out.Forum).UnmarshalEasyJSON(in)
-			}
-		case "post":
-			if in.IsNull() {
-				in.Skip()
-				out.Post = nil
-			} else {
-				if out.Post == nil {
-					out.Post = new(Post)
-				}
-				(*out.Post).UnmarshalEasyJSON(in)
-			}
-		case "thread":
-			if in.IsNull() {
-				in.Skip()
-				out.Thread = nil
-			} else {
-				if out.Thread == nil {
-					out.Thread = new(Thread)
-				}
-				easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels6(in, out.Thread)
-			}
+		case "title":
+			out.Title = string(in.String())
+		case "slug":
+			out.Slug = string(in.String())
+		case "stub":
+			out.Stub = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -363,73 +329,118 @@ func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels4(in *jlexe
 		in.Consumed()
 	}
 }
-func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels4(out *jwriter.Writer, in PostFullInfo) {
+func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels4(out *jwriter.Writer, in PostSplit) {
 	out.RawByte('{')
 	first := true
 	_ = first
-	if in.Author != nil {
-		const prefix string = ",\"author\":"
-		first = false
+	{
+		const prefix string = ",\"title\":"
 		out.RawString(prefix[1:])
-		easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels5(out, *in.Author)
-	}
-	if in.Forum != nil {
-		const prefix string = ",\"forum\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		(*in.Forum).MarshalEasyJSON(out)
+		out.String(string(in.Title))
 	}
-	if in.Post != nil {
-		const prefix string = ",\"post\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		(*in.Post).MarshalEasyJSON(out)
+	if in.Slug != "" {
+		const prefix string = ",\"slug\":"
+		out.RawString(prefix)
+		out.String(string(in.Slug))
 	}
-	if in.Thread != nil {
-		const prefix string = ",\"thread\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels6(out, *in.Thread)
+	if in.Stub {
+		const prefix string = ",\"stub\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Stub))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v PostFullInfo) MarshalJSON() ([]byte, error) {
+func (v PostSplit) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v PostFullInfo) MarshalEasyJSON(w *jwriter.Writer) {
+func (v PostSplit) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *PostFullInfo) UnmarshalJSON(data []byte) error {
+func (v *PostSplit) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *PostFullInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *PostSplit) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels4(l, v)
 }
-func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels6(in *jlexer.Lexer, out *Thread) {
+func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels5(in *jlexer.Lexer, out *PostRevisions) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(PostRevisions, 0, 1)
+			} else {
+				*out = PostRevisions{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v10 PostRevision
+			(v10).UnmarshalEasyJSON(in)
+			*out = append(*out, v10)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels5(out *jwriter.Writer, in PostRevisions) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v11, v12 := range in {
+			if v11 > 0 {
+				out.RawByte(',')
+			}
+			(v12).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PostRevisions) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PostRevisions) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PostRevisions) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PostRevisions) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels5(l, v)
+}
+func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels6(in *jlexer.Lexer, out *PostRevision) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -448,24 +459,18 @@ func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels6(in *jlexe
 			continue
 		}
 		switch key {
+		case "post":
+			out.Post = int64(in.Int64())
+		case "number":
+			out.Number = int32(in.Int32())
 		case "author":
 			out.Author = string(in.String())
-		case "title":
-			out.Title = string(in.String())
 		case "message":
 			out.Message = string(in.String())
-		case "id":
-			out.ID = int32(in.Int32())
-		case "forum":
-			out.Forum = string(in.String())
 		case "created":
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.Created).UnmarshalJSON(data))
 			}
-		case "slug":
-			out.Slug = string(in.String())
-		case "votes":
-			out.Votes = int32(in.Int32())
 		default:
 			in.SkipRecursive()
 		}
@@ -476,53 +481,62 @@ func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels6(in *jlexe
 		in.Consumed()
 	}
 }
-func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels6(out *jwriter.Writer, in Thread) {
+func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels6(out *jwriter.Writer, in PostRevision) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"author\":"
+		const prefix string = ",\"post\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.Author))
+		out.Int64(int64(in.Post))
 	}
 	{
-		const prefix string = ",\"title\":"
+		const prefix string = ",\"number\":"
 		out.RawString(prefix)
-		out.String(string(in.Title))
+		out.Int32(int32(in.Number))
 	}
 	{
-		const prefix string = ",\"message\":"
-		out.RawString(prefix)
-		out.String(string(in.Message))
-	}
-	if in.ID != 0 {
-		const prefix string = ",\"id\":"
+		const prefix string = ",\"author\":"
 		out.RawString(prefix)
-		out.Int32(int32(in.ID))
+		out.String(string(in.Author))
 	}
-	if in.Forum != "" {
-		const prefix string = ",\"forum\":"
+	{
+		const prefix string = ",\"message\":"
 		out.RawString(prefix)
-		out.String(string(in.Forum))
+		out.String(string(in.Message))
 	}
-	if true {
+	{
 		const prefix string = ",\"created\":"
 		out.RawString(prefix)
 		out.Raw((in.Created).MarshalJSON())
 	}
-	if in.Slug != "" {
-		const prefix string = ",\"slug\":"
-		out.RawString(prefix)
-		out.String(string(in.Slug))
-	}
-	if in.Votes != 0 {
-		const prefix string = ",\"votes\":"
-		out.RawString(prefix)
-		out.Int32(int32(in.Votes))
-	}
 	out.RawByte('}')
 }
-func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels5(in *jlexer.Lexer, out *User) {
+
+// MarshalJSON supports json.Marshaler interface
+func (v PostRevision) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PostRevision) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PostRevision) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PostRevision) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels6(l, v)
+}
+func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels7(in *jlexer.Lexer, out *PostFullInfo) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -541,14 +555,56 @@ func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels5(in *jlexe
 			continue
 		}
 		switch key {
-		case "email":
-			out.Email = string(in.String())
-		case "fullname":
-			out.Fullname = string(in.String())
-		case "nickname":
-			out.Nickname = string(in.String())
-		case "about":
-			out.About = string(in.String())
+		case "author":
+			if in.IsNull() {
+				in.Skip()
+				out.Author = nil
+			} else {
+				if out.Author == nil {
+					out.Author = new(User)
+				}
+				(*out.Author).UnmarshalEasyJSON(in)
+			}
+		case "forum":
+			if in.IsNull() {
+				in.Skip()
+				out.Forum = nil
+			} else {
+				if out.Forum == nil {
+					out.Forum = new(Forum)
+				}
+				(*out.Forum).UnmarshalEasyJSON(in)
+			}
+		case "post":
+			if in.IsNull() {
+				in.Skip()
+				out.Post = nil
+			} else {
+				if out.Post == nil {
+					out.Post = new(Post)
+				}
+				(*out.Post).UnmarshalEasyJSON(in)
+			}
+		case "thread":
+			if in.IsNull() {
+				in.Skip()
+				out.Thread = nil
+			} else {
+				if out.Thread == nil {
+					out.Thread = new(Thread)
+				}
+				(*out.Thread).UnmarshalEasyJSON(in)
+			}
+		case "revisions":
+			if in.IsNull() {
+				in.Skip()
+				out.Revisions = nil
+			} else {
+				if out.Revisions == nil {
+					out.Revisions = new(PostRevisions)
+				}
+				(*out.Revisions).UnmarshalEasyJSON(in)
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -559,33 +615,83 @@ func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels5(in *jlexe
 		in.Consumed()
 	}
 }
-func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels5(out *jwriter.Writer, in User) {
+func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels7(out *jwriter.Writer, in PostFullInfo) {
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
-		const prefix string = ",\"email\":"
+	if in.Author != nil {
+		const prefix string = ",\"author\":"
+		first = false
 		out.RawString(prefix[1:])
-		out.String(string(in.Email))
+		(*in.Author).MarshalEasyJSON(out)
 	}
-	{
-		const prefix string = ",\"fullname\":"
-		out.RawString(prefix)
-		out.String(string(in.Fullname))
+	if in.Forum != nil {
+		const prefix string = ",\"forum\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Forum).MarshalEasyJSON(out)
 	}
-	if in.Nickname != "" {
-		const prefix string = ",\"nickname\":"
-		out.RawString(prefix)
-		out.String(string(in.Nickname))
+	if in.Post != nil {
+		const prefix string = ",\"post\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Post).MarshalEasyJSON(out)
 	}
-	if in.About != "" {
-		const prefix string = ",\"about\":"
-		out.RawString(prefix)
-		out.String(string(in.About))
+	if in.Thread != nil {
+		const prefix string = ",\"thread\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Thread).MarshalEasyJSON(out)
+	}
+	if in.Revisions != nil {
+		const prefix string = ",\"revisions\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Revisions).MarshalEasyJSON(out)
 	}
 	out.RawByte('}')
 }
-func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels7(in *jlexer.Lexer, out *Post) {
+
+// MarshalJSON supports json.Marshaler interface
+func (v PostFullInfo) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels7(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PostFullInfo) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels7(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PostFullInfo) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels7(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PostFullInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels7(l, v)
+}
+func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels8(in *jlexer.Lexer, out *Post) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -618,6 +724,8 @@ func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels7(in *jlexe
 			out.ID = int64(in.Int64())
 		case "isEdited":
 			out.IsEdited = bool(in.Bool())
+		case "isDeleted":
+			out.IsDeleted = bool(in.Bool())
 		case "parent":
 			out.Parent = int64(in.Int64())
 		case "thread":
@@ -632,7 +740,7 @@ func easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels7(in *jlexe
 		in.Consumed()
 	}
 }
-func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels7(out *jwriter.Writer, in Post) {
+func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels8(out *jwriter.Writer, in Post) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -666,6 +774,11 @@ func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels7(out *jwri
 		out.RawString(prefix)
 		out.Bool(bool(in.IsEdited))
 	}
+	if in.IsDeleted {
+		const prefix string = ",\"isDeleted\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsDeleted))
+	}
 	if in.Parent != 0 {
 		const prefix string = ",\"parent\":"
 		out.RawString(prefix)
@@ -682,23 +795,23 @@ func easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels7(out *jwri
 // MarshalJSON supports json.Marshaler interface
 func (v Post) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels7(&w, v)
+	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels8(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Post) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels7(w, v)
+	easyjson5a72dc82EncodeGithubComNickeskovDbForumInternalPkgModels8(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Post) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels7(&r, v)
+	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels8(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Post) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels7(l, v)
+	easyjson5a72dc82DecodeGithubComNickeskovDbForumInternalPkgModels8(l, v)
 }
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	PostSearchResult   = "post"
+	ThreadSearchResult = "thread"
+)
+
+// SearchResult is found post or thread, Snippet is HTML-escaped text with matched words in <b></b> tags
+//
+//easyjson:json
+type SearchResult struct {
+	Type    string    `json:"type"`
+	ID      int64     `json:"id"`
+	Thread  int32     `json:"thread"`
+	Forum   string    `json:"forum"`
+	Author  string    `json:"author"`
+	Title   string    `json:"title,omitempty"`
+	Snippet string    `json:"snippet"`
+	Rank    float32   `json:"rank"`
+	Created time.Time `json:"created"`
+}
+
+//easyjson:json
+type SearchResults []SearchResult
+
+//easyjson:json
+type SearchPage struct {
+	Results    SearchResults `json:"results"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
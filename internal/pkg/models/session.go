@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+//easyjson:json
+type Credentials struct {
+	Nickname string `json:"nickname"`
+	Password string `json:"password"`
+}
+
+//easyjson:json
+type Session struct {
+	// Token is sent back in "Authorization: Bearer <token>" header, only its hash is stored
+	Token    string    `json:"token"`
+	Nickname string    `json:"nickname"`
+	Expires  time.Time `json:"expires"`
+}
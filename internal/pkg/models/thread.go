@@ -2,6 +2,14 @@ package models
 
 import "time"
 
+const (
+	OpenThreadState = "open"
+	// LockedThreadState forbids new posts in thread
+	LockedThreadState = "locked"
+	// ArchivedThreadState forbids new posts and votes in thread
+	ArchivedThreadState = "archived"
+)
+
 //easyjson:json
 type Thread struct {
 	Author  string    `json:"author"`
@@ -12,14 +20,59 @@ type Thread struct {
 	Created time.Time `json:"created,omitempty"`
 	Slug    string    `json:"slug,omitempty"`
 	Votes   int32     `json:"votes,omitempty"`
+	State   string    `json:"state,omitempty"`
+	// IsPinned threads are listed first in forum
+	IsPinned bool `json:"pinned,omitempty"`
 }
 
 //easyjson:json
 type Threads []Thread
 
+//easyjson:json
+type ThreadUpdate struct {
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message,omitempty"`
+	State   string `json:"state,omitempty"`
+	Pinned  *bool  `json:"pinned,omitempty"`
+}
+
+//easyjson:json
+type ThreadVotes struct {
+	Thread int32 `json:"thread"`
+	Votes  int32 `json:"votes"`
+	// Nickname and Voice describe vote which changed total
+	Nickname string `json:"nickname,omitempty"`
+	Voice    int16  `json:"voice,omitempty"`
+}
+
+//easyjson:json
+type ThreadMove struct {
+	Forum string `json:"forum"`
+}
+
+//easyjson:json
+type ThreadMerge struct {
+	// Thread is slug or id of thread which receives posts
+	Thread string `json:"thread"`
+}
+
 func (thread Thread) Validate() error {
 	if thread.Slug != "" {
 		return validateSlug(thread.Slug)
 	}
 	return nil
 }
+
+func (threadUpdate ThreadUpdate) Validate() error {
+	switch threadUpdate.State {
+	case "", OpenThreadState, LockedThreadState, ArchivedThreadState:
+		return nil
+	default:
+		return ErrValidation
+	}
+}
+
+// IsModeration reports whether update changes state or pinned flag, which only moderators can do
+func (threadUpdate ThreadUpdate) IsModeration() bool {
+	return threadUpdate.State != "" || threadUpdate.Pinned != nil
+}
@@ -83,7 +83,337 @@ func (v *Threads) UnmarshalJSON(data []byte) error {
 func (v *Threads) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels(l, v)
 }
-func easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels1(in *jlexer.Lexer, out *Thread) {
+func easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels1(in *jlexer.Lexer, out *ThreadVotes) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "thread":
+			out.Thread = int32(in.Int32())
+		case "votes":
+			out.Votes = int32(in.Int32())
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "voice":
+			out.Voice = int16(in.Int16())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels1(out *jwriter.Writer, in ThreadVotes) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix[1:])
+		out.Int32(int32(in.Thread))
+	}
+	{
+		const prefix string = ",\"votes\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Votes))
+	}
+	if in.Nickname != "" {
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix)
+		out.String(string(in.Nickname))
+	}
+	if in.Voice != 0 {
+		const prefix string = ",\"voice\":"
+		out.RawString(prefix)
+		out.Int16(int16(in.Voice))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ThreadVotes) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ThreadVotes) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ThreadVotes) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ThreadVotes) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels1(l, v)
+}
+func easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels2(in *jlexer.Lexer, out *ThreadUpdate) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "title":
+			out.Title = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		case "state":
+			out.State = string(in.String())
+		case "pinned":
+			if in.IsNull() {
+				in.Skip()
+				out.Pinned = nil
+			} else {
+				if out.Pinned == nil {
+					out.Pinned = new(bool)
+				}
+				*out.Pinned = bool(in.Bool())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels2(out *jwriter.Writer, in ThreadUpdate) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.Title))
+	}
+	if in.Message != "" {
+		const prefix string = ",\"message\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Message))
+	}
+	if in.State != "" {
+		const prefix string = ",\"state\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.State))
+	}
+	if in.Pinned != nil {
+		const prefix string = ",\"pinned\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(*in.Pinned))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ThreadUpdate) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ThreadUpdate) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ThreadUpdate) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ThreadUpdate) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels2(l, v)
+}
+func easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels3(in *jlexer.Lexer, out *ThreadMove) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "forum":
+			out.Forum = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels3(out *jwriter.Writer, in ThreadMove) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Forum))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ThreadMove) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ThreadMove) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ThreadMove) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ThreadMove) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels3(l, v)
+}
+func easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels4(in *jlexer.Lexer, out *ThreadMerge) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "thread":
+			out.Thread = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels4(out *jwriter.Writer, in ThreadMerge) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Thread))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ThreadMerge) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ThreadMerge) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ThreadMerge) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ThreadMerge) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels4(l, v)
+}
+func easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels5(in *jlexer.Lexer, out *Thread) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -120,6 +450,10 @@ func easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels1(in *jlexer
 			out.Slug = string(in.String())
 		case "votes":
 			out.Votes = int32(in.Int32())
+		case "state":
+			out.State = string(in.String())
+		case "pinned":
+			out.IsPinned = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -130,7 +464,7 @@ func easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels1(in *jlexer
 		in.Consumed()
 	}
 }
-func easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels1(out *jwriter.Writer, in Thread) {
+func easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels5(out *jwriter.Writer, in Thread) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -174,29 +508,39 @@ func easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels1(out *jwrit
 		out.RawString(prefix)
 		out.Int32(int32(in.Votes))
 	}
+	if in.State != "" {
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	if in.IsPinned {
+		const prefix string = ",\"pinned\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsPinned))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Thread) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels1(&w, v)
+	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels5(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Thread) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels1(w, v)
+	easyjson2d00218EncodeGithubComNickeskovDbForumInternalPkgModels5(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Thread) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels1(&r, v)
+	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels5(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Thread) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels1(l, v)
+	easyjson2d00218DecodeGithubComNickeskovDbForumInternalPkgModels5(l, v)
 }
@@ -6,6 +6,10 @@ type User struct {
 	Fullname string `json:"fullname"`
 	Nickname string `json:"nickname,omitempty"`
 	About    string `json:"about,omitempty"`
+	// Password is accepted on registration and profile update and never returned
+	Password string `json:"password,omitempty"`
+	// PasswordHash is bcrypt hash of Password, empty for users registered without password
+	PasswordHash string `json:"-"`
 }
 
 //easyjson:json
@@ -28,6 +32,9 @@ func (user User) Validate() error {
 	if err := validateNickname(user.Nickname); err != nil {
 		return err
 	}
+	if err := validatePassword(user.Password); err != nil {
+		return err
+	}
 	return nil
 }
 
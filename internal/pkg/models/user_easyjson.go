@@ -267,6 +267,8 @@ func easyjson9e1087fdDecodeGithubComNickeskovDbForumInternalPkgModels3(in *jlexe
 			out.Nickname = string(in.String())
 		case "about":
 			out.About = string(in.String())
+		case "password":
+			out.Password = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -301,6 +303,11 @@ func easyjson9e1087fdEncodeGithubComNickeskovDbForumInternalPkgModels3(out *jwri
 		out.RawString(prefix)
 		out.String(string(in.About))
 	}
+	if in.Password != "" {
+		const prefix string = ",\"password\":"
+		out.RawString(prefix)
+		out.String(string(in.Password))
+	}
 	out.RawByte('}')
 }
 
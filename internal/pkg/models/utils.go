@@ -5,6 +5,11 @@ import (
 	"regexp"
 )
 
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores bytes after 72
+)
+
 var (
 	reSlugValidator     = regexp.MustCompile(`^(\d|\w|-|_)*(\w|-|_)(\d|\w|-|_)*$`)
 	reNicknameValidator = regexp.MustCompile(`([a-zA-Z0-9]|_|\.)*`)
@@ -32,3 +37,10 @@ func validateSlug(slug string) error {
 	}
 	return nil
 }
+
+func validatePassword(password string) error {
+	if password != "" && (len(password) < minPasswordLength || len(password) > maxPasswordLength) {
+		return ErrValidation
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+const (
+	ThreadCreatedWebhookEvent = "thread.created"
+	ThreadVotedWebhookEvent   = "thread.voted"
+	PostCreatedWebhookEvent   = "post.created"
+	PostEditedWebhookEvent    = "post.edited"
+)
+
+const (
+	PendingWebhookDelivery = "pending"
+	// DeliveredWebhookDelivery got 2xx response
+	DeliveredWebhookDelivery = "delivered"
+	// FailedWebhookDelivery is not retried anymore
+	FailedWebhookDelivery = "failed"
+)
+
+//easyjson:json
+type Webhook struct {
+	ID    int64  `json:"id,omitempty"`
+	Forum string `json:"forum,omitempty"`
+	URL   string `json:"url"`
+	// Secret signs deliveries, it is returned only on creation
+	Secret  string    `json:"secret,omitempty"`
+	Events  []string  `json:"events"`
+	Created time.Time `json:"created,omitempty"`
+}
+
+//easyjson:json
+type Webhooks []Webhook
+
+//easyjson:json
+type WebhookDelivery struct {
+	ID      int64  `json:"id"`
+	Webhook int64  `json:"webhook"`
+	Event   string `json:"event"`
+	// Payload is json of event model, e.g. Post for post.created
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	// ResponseStatus and Error describe the last failed attempt
+	ResponseStatus int32      `json:"response_status,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	Created        time.Time  `json:"created"`
+	Delivered      *time.Time `json:"delivered,omitempty"`
+}
+
+//easyjson:json
+type WebhookDeliveries []WebhookDelivery
+
+//easyjson:json
+type WebhookPayload struct {
+	Delivery int64           `json:"delivery"`
+	Event    string          `json:"event"`
+	Forum    string          `json:"forum"`
+	Data     json.RawMessage `json:"data"`
+}
+
+func (webhook Webhook) Validate() error {
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrValidation
+	}
+
+	if len(webhook.Events) == 0 {
+		return ErrValidation
+	}
+	for _, event := range webhook.Events {
+		switch event {
+		case ThreadCreatedWebhookEvent, ThreadVotedWebhookEvent, PostCreatedWebhookEvent, PostEditedWebhookEvent:
+		default:
+			return ErrValidation
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+// Package pagination implements opaque cursor tokens of list endpoints.
+// Token is signed position of the last row of page, so clients can't forge or reuse it for other list.
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+// Cursor is position of the last row of page in list
+type Cursor struct {
+	// List identifies endpoint and its params (scope and order) the cursor was issued for
+	List     string    `json:"l"`
+	ID       int64     `json:"i,omitempty"`
+	Created  time.Time `json:"c"`
+	Pinned   bool      `json:"p,omitempty"`
+	Nickname string    `json:"n,omitempty"`
+}
+
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret []byte) Signer {
+	return Signer{
+		secret: secret,
+	}
+}
+
+// RandomSecret returns secret for signer, tokens signed by it are invalid after restart
+func RandomSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return secret, nil
+}
+
+// Encode returns token in "payload.signature" form, both parts are base64url encoded
+func (signer Signer) Encode(cursor Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signer.sign(payload)), nil
+}
+
+// Decode returns ErrInvalid if token is malformed, forged or was issued for other list
+func (signer Signer) Decode(token, list string) (Cursor, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return Cursor{}, models.ErrInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return Cursor{}, models.ErrInvalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, signer.sign(payload)) {
+		return Cursor{}, models.ErrInvalid
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.List != list {
+		return Cursor{}, models.ErrInvalid
+	}
+
+	return cursor, nil
+}
+
+func (signer Signer) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, signer.secret)
+	_, _ = mac.Write(payload)
+	return mac.Sum(nil)
+}
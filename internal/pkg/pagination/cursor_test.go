@@ -0,0 +1,84 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignerDecode(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	list := List("threads", "Go", "desc")
+
+	cursor := Cursor{List: list, ID: 42, Created: time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC), Pinned: true}
+	token, err := signer.Encode(cursor)
+	if err != nil {
+		t.Fatalf("cannot encode cursor: %+v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		t.Fatalf("token %q is not in payload.signature form", token)
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+	otherListToken, err := signer.Encode(Cursor{List: List("threads", "go", "asc"), ID: 42})
+	if err != nil {
+		t.Fatalf("cannot encode cursor: %+v", err)
+	}
+	otherSecretToken, err := NewSigner([]byte("other secret")).Encode(cursor)
+	if err != nil {
+		t.Fatalf("cannot encode cursor: %+v", err)
+	}
+
+	cases := []struct {
+		name  string
+		token string
+		list  string
+		valid bool
+	}{
+		{"Valid", token, list, true},
+		// list names are case insensitive like slugs
+		{"ValidWithListOfOtherCase", token, List("THREADS", "go", "DESC"), true},
+		{"OtherList", token, List("threads", "go", "asc"), false},
+		{"TokenOfOtherList", otherListToken, list, false},
+		{"OtherSecret", otherSecretToken, list, false},
+		{"TamperedPayload", encode([]byte(`{"l":"threads:go:desc","i":1}`)) + "." + parts[1], list, false},
+		{"SwappedSignature", parts[0] + "." + strings.Split(otherListToken, ".")[1], list, false},
+		{"TruncatedSignature", parts[0] + "." + parts[1][:len(parts[1])-2], list, false},
+		{"MissingSignature", parts[0], list, false},
+		{"ExtraPart", token + ".extra", list, false},
+		{"NotBase64Payload", "!!!." + parts[1], list, false},
+		{"NotBase64Signature", parts[0] + ".!!!", list, false},
+		{"Empty", "", list, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := signer.Decode(tc.token, tc.list)
+			switch {
+			case tc.valid && err != nil:
+				t.Errorf("unexpected error: %+v", err)
+			case tc.valid && (decoded.ID != cursor.ID || !decoded.Created.Equal(cursor.Created) ||
+				decoded.Pinned != cursor.Pinned):
+				t.Errorf("expected cursor %+v, got %+v", cursor, decoded)
+			case !tc.valid && !errors.Is(err, models.ErrInvalid):
+				t.Errorf("expected invalid cursor error, got %v, %+v", err, decoded)
+			}
+		})
+	}
+}
+
+func TestSignerDecodeRejectsSignedNotJSONPayload(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte("not json")
+	token := base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(NewSigner(secret).sign(payload))
+
+	if _, err := NewSigner(secret).Decode(token, "threads"); !errors.Is(err, models.ErrInvalid) {
+		t.Fatalf("expected invalid cursor error, got %v", err)
+	}
+}
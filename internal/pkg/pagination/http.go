@@ -0,0 +1,67 @@
+package pagination
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// CursorParam is query param of cursor token, its presence switches list response to page object
+	CursorParam = "cursor"
+	// SinceParam is replaced by cursor in link to next page
+	SinceParam = "since"
+	LimitParam = "limit"
+)
+
+// List builds list identity of cursor from endpoint name and params which affect rows order
+func List(name string, params ...string) string {
+	return strings.ToLower(strings.Join(append([]string{name}, params...), ":"))
+}
+
+// Requested reports whether client asked for page object with next cursor instead of plain list
+func Requested(r *http.Request) bool {
+	_, ok := r.URL.Query()[CursorParam]
+	return ok
+}
+
+// FromRequest returns cursor from request or nil for first page
+func (signer Signer) FromRequest(r *http.Request, list string) (*Cursor, error) {
+	token := r.URL.Query().Get(CursorParam)
+	if token == "" {
+		return nil, nil
+	}
+
+	cursor, err := signer.Decode(token, list)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+// IsFull reports whether page has limit rows, so the next page may exist
+func IsFull(r *http.Request, rows int) bool {
+	limit, err := strconv.Atoi(r.URL.Query().Get(LimitParam))
+	if err != nil {
+		limit = 1 // default limit of utils.ParseSinceDescLimit
+	}
+	return limit > 0 && rows >= limit
+}
+
+// SetNextLink sets Link header with URL of next page and returns token of cursor
+func (signer Signer) SetNextLink(w http.ResponseWriter, r *http.Request, cursor Cursor) (string, error) {
+	token, err := signer.Encode(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	query := r.URL.Query()
+	query.Del(SinceParam)
+	query.Set(CursorParam, token)
+
+	w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, query.Encode()))
+
+	return token, nil
+}
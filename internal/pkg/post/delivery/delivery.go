@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gorilla/mux"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/pagination"
 	"github.com/nickeskov/db_forum/internal/pkg/post"
 	"github.com/nickeskov/db_forum/internal/pkg/utils"
 	httpUtils "github.com/nickeskov/db_forum/pkg/http"
 	"github.com/nickeskov/db_forum/pkg/logger"
+	"github.com/nickeskov/db_forum/pkg/middleware"
 	"net/http"
 	"strconv"
 	"strings"
@@ -17,12 +20,18 @@ import (
 
 type Delivery struct {
 	useCase post.UseCase
+	guard   auth.Guard
+	signer  pagination.Signer
 	utils   httpUtils.Utils
 }
 
-func NewDelivery(useCase post.UseCase, logger logger.Logger) Delivery {
+func NewDelivery(useCase post.UseCase, guard auth.Guard, signer pagination.Signer,
+	logger logger.Logger) Delivery {
+
 	return Delivery{
 		useCase: useCase,
+		guard:   guard,
+		signer:  signer,
 		utils:   httpUtils.NewDeliveryUtils(logger),
 	}
 }
@@ -40,9 +49,16 @@ func (delivery Delivery) CreatePostsByThreadSlugOrID(w http.ResponseWriter, r *h
 		return
 	}
 
+	for _, newPost := range newPosts {
+		if err := delivery.guard.CheckCaller(r.Context(), newPost.Author); err != nil {
+			auth.WriteAccessError(delivery.utils, w, r, err)
+			return
+		}
+	}
+
 	threadSlugOrID := mux.Vars(r)["slug_or_id"]
 
-	createdPosts, err := delivery.useCase.CreatePostsByThreadSlugOrID(threadSlugOrID, newPosts)
+	createdPosts, err := delivery.useCase.CreatePostsByThreadSlugOrID(r.Context(), threadSlugOrID, newPosts)
 
 	switch {
 	case errors.Is(err, models.ErrDoesNotExist):
@@ -55,6 +71,11 @@ func (delivery Delivery) CreatePostsByThreadSlugOrID(w http.ResponseWriter, r *h
 			fmt.Sprintf("one or many parent posts not exists in thread with threadSlugOrID=%s",
 				threadSlugOrID))
 
+	case errors.Is(err, models.ErrAccessDenied):
+		delivery.utils.WriteResponseError(w, r, http.StatusForbidden,
+			fmt.Sprintf("can't create posts in thread with threadSlugOrID=%s: %v",
+				threadSlugOrID, err))
+
 	case err != nil:
 		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
 			fmt.Sprintf("%+v", err))
@@ -84,7 +105,7 @@ func (delivery Delivery) GetPostInfoByID(w http.ResponseWriter, r *http.Request)
 		related = strings.Split(relatedQuery, ",")
 	}
 
-	postFullInfo, err := delivery.useCase.GetPostInfoByID(id, related)
+	postFullInfo, err := delivery.useCase.GetPostInfoByID(r.Context(), id, related)
 
 	switch {
 	case errors.Is(err, models.ErrInvalid):
@@ -131,14 +152,26 @@ func (delivery Delivery) UpdatePostByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	updatedPost, err := delivery.useCase.UpdatePostByID(postUpdate)
+	if !delivery.checkPostEditor(w, r, id, auth.EditAction) {
+		return
+	}
+
+	if delivery.guard.Enabled() {
+		postUpdate.Author, _ = middleware.AuthenticatedNickname(r.Context()) // caller is editor of revision
+	}
+
+	updatedPost, err := delivery.useCase.UpdatePostByID(r.Context(), postUpdate)
 
 	switch {
 	case errors.Is(err, models.ErrDoesNotExist):
 		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
-			fmt.Sprintf("post does not exist in db, postID=%d", id),
+			fmt.Sprintf("post or editor does not exist in db, postID=%d, editor=%s", id, postUpdate.Author),
 		)
 
+	case errors.Is(err, models.ErrConflict):
+		delivery.utils.WriteResponseError(w, r, http.StatusConflict,
+			fmt.Sprintf("post is deleted, postID=%d", id))
+
 	case err != nil:
 		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
 			fmt.Sprintf("%+v", err))
@@ -154,6 +187,211 @@ func (delivery Delivery) UpdatePostByID(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+func (delivery Delivery) DeletePostByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !delivery.checkPostEditor(w, r, id, auth.DeleteAction) {
+		return
+	}
+
+	deletedPost, err := delivery.useCase.DeletePostByID(r.Context(), id)
+
+	delivery.writePostResponse(w, r, id, deletedPost, err)
+}
+
+func (delivery Delivery) RestorePostByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	restoredPost, err := delivery.useCase.RestorePostByID(r.Context(), id)
+
+	delivery.writePostResponse(w, r, id, restoredPost, err)
+}
+
+func (delivery Delivery) SplitPostByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, err := delivery.utils.ReadAllDataFromBody(w, r)
+	if err != nil {
+		return
+	}
+
+	var postSplit models.PostSplit
+
+	if err := json.Unmarshal(data, &postSplit); err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if validationErr := postSplit.Validate(); validationErr != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, validationErr.Error())
+		return
+	}
+
+	if !delivery.checkPostEditor(w, r, id, auth.ModerateAction) {
+		return
+	}
+
+	newThread, err := delivery.useCase.SplitPostByID(r.Context(), id, postSplit)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("post does not exist in db, postID=%d", id),
+		)
+
+	case errors.Is(err, models.ErrConflict):
+		delivery.utils.WriteResponseError(w, r, http.StatusConflict,
+			fmt.Sprintf("thread with slug=%s already exists", postSplit.Slug))
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(newThread)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusCreated, data)
+	}
+}
+
+func (delivery Delivery) GetPostRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	revisions, err := delivery.useCase.GetPostRevisions(r.Context(), id)
+
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("post does not exist in db, postID=%d", id),
+		)
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(revisions)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+func (delivery Delivery) GetPostRevision(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	number, err := strconv.ParseInt(vars["n"], 10, 32)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	revision, err := delivery.useCase.GetPostRevision(r.Context(), id, int32(number))
+
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("post or revision does not exist in db, postID=%d, revision=%d", id, number),
+		)
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(revision)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+// checkPostEditor writes error response and returns false if caller is not author of post
+// or moderator of its forum, moderation actions are allowed only to moderators
+func (delivery Delivery) checkPostEditor(w http.ResponseWriter, r *http.Request,
+	id int64, action auth.ContentAction) bool {
+	if !delivery.guard.ChecksAction(action) {
+		return true
+	}
+
+	storedPost, err := delivery.useCase.GetPostInfoByID(r.Context(), id, nil)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("post does not exist in db, postID=%d", id),
+		)
+		return false
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+		return false
+	}
+
+	err = delivery.guard.CheckContentAction(r.Context(), action, storedPost.Post.Forum, storedPost.Post.Author)
+	if err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return false
+	}
+
+	return true
+}
+
+func (delivery Delivery) writePostResponse(w http.ResponseWriter, r *http.Request,
+	id int64, postModel models.Post, err error) {
+
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("post does not exist in db, postID=%d", id),
+		)
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(postModel)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
 func (delivery Delivery) GetSortedPostsByThreadSlugOrID(w http.ResponseWriter, r *http.Request) {
 	threadSlugOrID := mux.Vars(r)["slug_or_id"]
 
@@ -162,7 +400,18 @@ func (delivery Delivery) GetSortedPostsByThreadSlugOrID(w http.ResponseWriter, r
 	sort := queryParams.Get("sort")
 	sinceThreadID, desc, limit := utils.ParseSinceDescLimit(queryParams)
 
-	posts, err := delivery.useCase.GetSortedPostsByThreadSlugOrID(threadSlugOrID, sinceThreadID,
+	list := pagination.List("posts", threadSlugOrID, sort, desc)
+	cursor, err := delivery.signer.FromRequest(r, list)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, "invalid cursor")
+		return
+	}
+	if cursor != nil {
+		// post ids are unique, so the last post of page is exact since of the next page
+		sinceThreadID = strconv.FormatInt(cursor.ID, 10)
+	}
+
+	posts, err := delivery.useCase.GetSortedPostsByThreadSlugOrID(r.Context(), threadSlugOrID, sinceThreadID,
 		sort, desc, limit)
 
 	switch {
@@ -178,6 +427,104 @@ func (delivery Delivery) GetSortedPostsByThreadSlugOrID(w http.ResponseWriter, r
 		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
 			fmt.Sprintf("%+v", err))
 
+	default:
+		// limit of parent_tree sort is applied to root posts
+		rows := len(posts)
+		if post.PostsSortType(sort) == post.ParentTreeSort {
+			rows = 0
+			for _, sorted := range posts {
+				if sorted.Parent == 0 {
+					rows++
+				}
+			}
+		}
+
+		page := models.PostsPage{Items: posts}
+		if pagination.IsFull(r, rows) {
+			page.NextCursor, err = delivery.signer.SetNextLink(w, r, pagination.Cursor{
+				List: list,
+				ID:   posts[len(posts)-1].ID,
+			})
+			if err != nil {
+				delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+
+		var data []byte
+		if pagination.Requested(r) {
+			data, err = json.Marshal(page)
+		} else {
+			data, err = json.Marshal(posts)
+		}
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+func (delivery Delivery) GetPostChildren(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sincePostID, desc, limit := utils.ParseSinceDescLimit(r.URL.Query())
+
+	posts, err := delivery.useCase.GetPostChildren(r.Context(), id, sincePostID, desc, limit)
+
+	delivery.writePostsResponse(w, r, id, posts, err)
+}
+
+func (delivery Delivery) GetPostDescendants(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	queryParams := r.URL.Query()
+
+	depth := queryParams.Get("depth")
+	sincePostID, desc, limit := utils.ParseSinceDescLimit(queryParams)
+
+	posts, err := delivery.useCase.GetPostDescendants(r.Context(), id, depth, sincePostID, desc, limit)
+
+	delivery.writePostsResponse(w, r, id, posts, err)
+}
+
+func (delivery Delivery) GetPostAncestors(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	posts, err := delivery.useCase.GetPostAncestors(r.Context(), id)
+
+	delivery.writePostsResponse(w, r, id, posts, err)
+}
+
+func (delivery Delivery) writePostsResponse(w http.ResponseWriter, r *http.Request,
+	id int64, posts models.Posts, err error) {
+
+	switch {
+	case errors.Is(err, models.ErrInvalid):
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("post does not exist in db, postID=%d", id),
+		)
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
 	default:
 		data, err := json.Marshal(posts)
 		if err != nil {
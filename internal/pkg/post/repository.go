@@ -1,13 +1,35 @@
 package post
 
-import "github.com/nickeskov/db_forum/internal/pkg/models"
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+)
 
 type Repository interface {
-	CreatePostsInThread(thread models.Thread, posts models.Posts) (models.Posts, error)
-	GetPostByID(id int64) (models.Post, error)
-	UpdatePostByID(post models.Post) (models.Post, error)
-	GetSortedPostsByThreadSlugOrID(threadID int32, sincePostID *int64,
+	// CreatePostsInThread returns ErrAccessDenied if thread is locked or archived
+	CreatePostsInThread(ctx context.Context, thread models.Thread, posts models.Posts) (models.Posts, error)
+	GetPostByID(ctx context.Context, id int64) (models.Post, error)
+	// UpdatePostByID stores previous message as revision edited by post.Author or by post author if it is empty
+	UpdatePostByID(ctx context.Context, post models.Post) (models.Post, error)
+	GetPostRevisions(ctx context.Context, postID int64) (models.PostRevisions, error)
+	GetPostRevision(ctx context.Context, postID int64, number int32) (models.PostRevision, error)
+	DeletePostByID(ctx context.Context, id int64) (models.Post, error)
+	RestorePostByID(ctx context.Context, id int64) (models.Post, error)
+	// SplitPostByID creates thread with title and slug of thread argument in forum of post and moves post
+	// with all its descendants to it, stub post with MovedPostMessageFormat message is left in place of post
+	// if leaveStub is set
+	SplitPostByID(ctx context.Context, id int64, thread models.Thread, leaveStub bool) (models.Thread, error)
+	GetSortedPostsByThreadSlugOrID(ctx context.Context, threadID int32, sincePostID *int64,
 		sort PostsSortType, desc bool, limit int64) (models.Posts, error)
+	// GetPostChildren returns direct replies to post ordered by id like flat sort
+	GetPostChildren(ctx context.Context, id int64, sincePostID *int64,
+		desc bool, limit int64) (models.Posts, error)
+	// GetPostDescendants returns all replies to post ordered by path like tree sort,
+	// positive depth limits count of levels below post
+	GetPostDescendants(ctx context.Context, id int64, depth int32, sincePostID *int64,
+		desc bool, limit int64) (models.Posts, error)
+	// GetPostAncestors returns parents of post starting from root post of thread
+	GetPostAncestors(ctx context.Context, id int64) (models.Posts, error)
 }
 
 type PostsSortType string
@@ -0,0 +1,113 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/post"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const repositoryName = "post"
+
+// Repository traces every method of wrapped repository and observes its latency
+type Repository struct {
+	repository post.Repository
+	observer   instrumentation.Observer
+}
+
+// NewRepository wraps repository, durations must have repository and method labels.
+// Durations and tracer may be nil.
+func NewRepository(repository post.Repository, durations *prometheus.HistogramVec,
+	tracer trace.Tracer) Repository {
+
+	return Repository{
+		repository: repository,
+		observer:   instrumentation.NewObserver(repositoryName, "repository", durations, tracer),
+	}
+}
+
+func (repo Repository) CreatePostsInThread(ctx context.Context,
+	thread models.Thread, posts models.Posts) (_ models.Posts, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "CreatePostsInThread")
+	defer func() { done(err) }()
+	return repo.repository.CreatePostsInThread(ctx, thread, posts)
+}
+
+func (repo Repository) GetPostByID(ctx context.Context, id int64) (_ models.Post, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetPostByID")
+	defer func() { done(err) }()
+	return repo.repository.GetPostByID(ctx, id)
+}
+
+func (repo Repository) UpdatePostByID(ctx context.Context, post models.Post) (_ models.Post, err error) {
+	ctx, done := repo.observer.Start(ctx, "UpdatePostByID")
+	defer func() { done(err) }()
+	return repo.repository.UpdatePostByID(ctx, post)
+}
+
+func (repo Repository) GetPostRevisions(ctx context.Context, postID int64) (_ models.PostRevisions, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetPostRevisions")
+	defer func() { done(err) }()
+	return repo.repository.GetPostRevisions(ctx, postID)
+}
+
+func (repo Repository) GetPostRevision(ctx context.Context,
+	postID int64, number int32) (_ models.PostRevision, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetPostRevision")
+	defer func() { done(err) }()
+	return repo.repository.GetPostRevision(ctx, postID, number)
+}
+
+func (repo Repository) DeletePostByID(ctx context.Context, id int64) (_ models.Post, err error) {
+	ctx, done := repo.observer.Start(ctx, "DeletePostByID")
+	defer func() { done(err) }()
+	return repo.repository.DeletePostByID(ctx, id)
+}
+
+func (repo Repository) RestorePostByID(ctx context.Context, id int64) (_ models.Post, err error) {
+	ctx, done := repo.observer.Start(ctx, "RestorePostByID")
+	defer func() { done(err) }()
+	return repo.repository.RestorePostByID(ctx, id)
+}
+
+func (repo Repository) SplitPostByID(ctx context.Context,
+	id int64, thread models.Thread, leaveStub bool) (_ models.Thread, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "SplitPostByID")
+	defer func() { done(err) }()
+	return repo.repository.SplitPostByID(ctx, id, thread, leaveStub)
+}
+
+func (repo Repository) GetSortedPostsByThreadSlugOrID(ctx context.Context,
+	threadID int32, sincePostID *int64, sort post.PostsSortType, desc bool, limit int64) (_ models.Posts, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetSortedPostsByThreadSlugOrID")
+	defer func() { done(err) }()
+	return repo.repository.GetSortedPostsByThreadSlugOrID(ctx, threadID, sincePostID, sort, desc, limit)
+}
+
+func (repo Repository) GetPostChildren(ctx context.Context,
+	id int64, sincePostID *int64, desc bool, limit int64) (_ models.Posts, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetPostChildren")
+	defer func() { done(err) }()
+	return repo.repository.GetPostChildren(ctx, id, sincePostID, desc, limit)
+}
+
+func (repo Repository) GetPostDescendants(ctx context.Context,
+	id int64, depth int32, sincePostID *int64, desc bool, limit int64) (_ models.Posts, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetPostDescendants")
+	defer func() { done(err) }()
+	return repo.repository.GetPostDescendants(ctx, id, depth, sincePostID, desc, limit)
+}
+
+func (repo Repository) GetPostAncestors(ctx context.Context, id int64) (_ models.Posts, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetPostAncestors")
+	defer func() { done(err) }()
+	return repo.repository.GetPostAncestors(ctx, id)
+}
@@ -0,0 +1,512 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/post"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+	"sort"
+	"time"
+)
+
+type Repository struct {
+	db *memoryDB.DB
+}
+
+func NewRepository(db *memoryDB.DB) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) CreatePostsInThread(ctx context.Context, thread models.Thread,
+	posts models.Posts) (models.Posts, error) {
+
+	if len(posts) == 0 {
+		return make(models.Posts, 0), nil
+	}
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	stored, ok := repo.db.Threads[thread.ID]
+	switch {
+	case !ok:
+		return nil, models.ErrDoesNotExist // thread does not exist
+	case stored.State == models.LockedThreadState || stored.State == models.ArchivedThreadState:
+		return nil, models.ErrAccessDenied // thread is locked or archived
+	}
+	// thread could be moved to other forum after caller read it
+	thread.Forum = stored.Forum
+
+	created := memoryDB.Timestamp(time.Now())
+
+	// posts of batch may be parents of next posts, so they are visible for path building
+	// and removed if some post of batch can't be inserted, as transaction rollback does
+	pending := make(map[int64]*memoryDB.Post, len(posts))
+
+	insertedPosts := make(models.Posts, 0, len(posts))
+	pendingPosts := make([]memoryDB.Post, 0, len(posts))
+
+	for _, postModel := range posts {
+		id := repo.db.NextPostID()
+
+		path, err := repo.postPath(pending, thread.ID, id, postModel.Parent)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := repo.db.UsersByNickname[memoryDB.CIKey(postModel.Author)]; !ok {
+			return nil, models.ErrDoesNotExist // author does not exist
+		}
+
+		newPost := memoryDB.Post{
+			Post: models.Post{
+				ID:       id,
+				Parent:   postModel.Parent,
+				Author:   postModel.Author,
+				Message:  postModel.Message,
+				IsEdited: false,
+				Forum:    thread.Forum,
+				Thread:   thread.ID,
+				Created:  created,
+			},
+			Path: path,
+		}
+
+		pending[id] = &newPost
+		pendingPosts = append(pendingPosts, newPost)
+		insertedPosts = append(insertedPosts, newPost.Post)
+	}
+
+	for _, pendingPost := range pendingPosts {
+		repo.db.InsertPost(pendingPost)
+	}
+
+	payloads := make([]interface{}, 0, len(insertedPosts))
+	for _, insertedPost := range insertedPosts {
+		payloads = append(payloads, insertedPost)
+	}
+	if err := repo.db.InsertWebhookEvents(thread.Forum, models.PostCreatedWebhookEvent, payloads...); err != nil {
+		return nil, err
+	}
+
+	return insertedPosts, nil
+}
+
+func (repo Repository) GetPostByID(ctx context.Context, id int64) (models.Post, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Post{}, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	stored, ok := repo.db.Posts[id]
+	if !ok {
+		return models.Post{}, models.ErrDoesNotExist
+	}
+
+	return stored.Post, nil
+}
+
+func (repo Repository) UpdatePostByID(ctx context.Context, post models.Post) (models.Post, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Post{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	stored, ok := repo.db.Posts[post.ID]
+	if !ok {
+		return models.Post{}, models.ErrDoesNotExist
+	}
+
+	if post.Message == "" || post.Message == stored.Message {
+		return stored.Post, nil
+	}
+	if stored.IsDeleted {
+		return models.Post{}, models.ErrConflict // deleted post must be restored before edit
+	}
+
+	editor := post.Author
+	if editor == "" {
+		editor = stored.Author
+	}
+	if _, ok := repo.db.UsersByNickname[memoryDB.CIKey(editor)]; !ok {
+		return models.Post{}, models.ErrDoesNotExist // editor does not exist
+	}
+
+	revisions := repo.db.PostRevisions[stored.ID]
+	repo.db.PostRevisions[stored.ID] = append(revisions, models.PostRevision{
+		Post:    stored.ID,
+		Number:  int32(len(revisions) + 1),
+		Author:  editor,
+		Message: stored.Message,
+		Created: memoryDB.Timestamp(time.Now()),
+	})
+
+	stored.Message = post.Message
+	stored.IsEdited = true
+
+	err := repo.db.InsertWebhookEvents(stored.Forum, models.PostEditedWebhookEvent, stored.Post.HideDeleted())
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	return stored.Post, nil
+}
+
+func (repo Repository) GetPostRevisions(ctx context.Context, postID int64) (models.PostRevisions, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	return append(make(models.PostRevisions, 0), repo.db.PostRevisions[postID]...), nil
+}
+
+func (repo Repository) GetPostRevision(ctx context.Context, postID int64,
+	number int32) (models.PostRevision, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.PostRevision{}, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	revisions := repo.db.PostRevisions[postID]
+	if number < 1 || int(number) > len(revisions) {
+		return models.PostRevision{}, models.ErrDoesNotExist
+	}
+
+	return revisions[number-1], nil
+}
+
+func (repo Repository) DeletePostByID(ctx context.Context, id int64) (models.Post, error) {
+	return repo.setPostIsDeleted(ctx, id, true)
+}
+
+func (repo Repository) RestorePostByID(ctx context.Context, id int64) (models.Post, error) {
+	return repo.setPostIsDeleted(ctx, id, false)
+}
+
+func (repo Repository) setPostIsDeleted(ctx context.Context, id int64, isDeleted bool) (models.Post, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Post{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	stored, ok := repo.db.Posts[id]
+	if !ok {
+		return models.Post{}, models.ErrDoesNotExist
+	}
+
+	repo.db.SetPostDeleted(stored, isDeleted)
+
+	return stored.Post, nil
+}
+
+func (repo Repository) SplitPostByID(ctx context.Context, id int64,
+	thread models.Thread, leaveStub bool) (models.Thread, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	stored, ok := repo.db.Posts[id]
+	if !ok {
+		return models.Thread{}, models.ErrDoesNotExist
+	}
+	if _, ok := repo.db.ThreadsBySlug[memoryDB.CIKey(thread.Slug)]; ok && thread.Slug != "" {
+		return models.Thread{}, models.ErrConflict
+	}
+
+	oldThreadID, oldParent := stored.Thread, stored.Parent
+
+	thread.Author = stored.Author
+	thread.Forum = stored.Forum
+	thread.Message = stored.HideDeleted().Message
+	thread.Created = stored.Created
+	thread.Votes = 0
+	thread.State = models.OpenThreadState
+	thread.IsPinned = false
+
+	thread = repo.db.InsertThread(thread)
+	repo.db.SplitPosts(stored, thread.ID)
+
+	if leaveStub {
+		stubID := repo.db.NextPostID()
+
+		path, err := repo.db.PostPath(oldThreadID, stubID, oldParent)
+		if err != nil {
+			return models.Thread{}, err
+		}
+
+		repo.db.InsertPost(memoryDB.Post{
+			Post: models.Post{
+				ID:      stubID,
+				Parent:  oldParent,
+				Author:  stored.Author,
+				Message: fmt.Sprintf(models.MovedPostMessageFormat, thread.ID),
+				Forum:   stored.Forum,
+				Thread:  oldThreadID,
+				Created: memoryDB.Timestamp(time.Now()),
+			},
+			Path: path,
+		})
+	}
+
+	return thread, nil
+}
+
+func (repo Repository) GetSortedPostsByThreadSlugOrID(ctx context.Context, threadID int32, sincePostID *int64,
+	sortType post.PostsSortType, desc bool, limit int64) (models.Posts, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	var sincePost *memoryDB.Post
+	if sincePostID != nil {
+		// in postgres comparison with path of not existing post is NULL, so nothing is selected
+		stored, ok := repo.db.Posts[*sincePostID]
+		if !ok && sortType != post.FlatSort {
+			return make(models.Posts, 0), nil
+		}
+		sincePost = stored
+	}
+
+	threadPosts := repo.db.ThreadPosts[threadID]
+
+	var sorted []*memoryDB.Post
+	switch sortType {
+	case post.FlatSort:
+		sorted = flatSort(threadPosts, sincePostID, desc, limit)
+	case post.TreeSort:
+		sorted = treeSort(threadPosts, sincePost, desc, limit)
+	case post.ParentTreeSort:
+		sorted = parentTreeSort(threadPosts, sincePost, desc, limit)
+	default:
+		return nil, models.ErrInvalid
+	}
+
+	return copyPosts(sorted), nil
+}
+
+func (repo Repository) GetPostChildren(ctx context.Context, id int64, sincePostID *int64,
+	desc bool, limit int64) (models.Posts, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	stored, ok := repo.db.Posts[id]
+	if !ok {
+		return nil, models.ErrDoesNotExist
+	}
+
+	children := make([]*memoryDB.Post, 0)
+	for _, threadPost := range repo.db.ThreadPosts[stored.Thread] {
+		if threadPost.Parent == id {
+			children = append(children, threadPost)
+		}
+	}
+
+	return copyPosts(flatSort(children, sincePostID, desc, limit)), nil
+}
+
+func (repo Repository) GetPostDescendants(ctx context.Context, id int64, depth int32, sincePostID *int64,
+	desc bool, limit int64) (models.Posts, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	stored, ok := repo.db.Posts[id]
+	if !ok {
+		return nil, models.ErrDoesNotExist
+	}
+
+	var sincePost *memoryDB.Post
+	if sincePostID != nil {
+		// in postgres comparison with path of not existing post is NULL, so nothing is selected
+		if sincePost, ok = repo.db.Posts[*sincePostID]; !ok {
+			return make(models.Posts, 0), nil
+		}
+	}
+
+	descendants := make([]*memoryDB.Post, 0)
+	for _, threadPost := range repo.db.ThreadPosts[stored.Thread] {
+		level := len(threadPost.Path) - len(stored.Path)
+		if level <= 0 || depth > 0 && level > int(depth) || !memoryDB.HasPathPrefix(threadPost.Path, stored.Path) {
+			continue
+		}
+		descendants = append(descendants, threadPost)
+	}
+
+	return copyPosts(treeSort(descendants, sincePost, desc, limit)), nil
+}
+
+func (repo Repository) GetPostAncestors(ctx context.Context, id int64) (models.Posts, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	stored, ok := repo.db.Posts[id]
+	if !ok {
+		return nil, models.ErrDoesNotExist
+	}
+
+	ancestors := make(models.Posts, 0, len(stored.Path)-1)
+	for _, ancestorID := range stored.Path[:len(stored.Path)-1] {
+		ancestors = append(ancestors, repo.db.Posts[ancestorID].Post)
+	}
+
+	return ancestors, nil
+}
+
+func (repo Repository) postPath(pending map[int64]*memoryDB.Post,
+	threadID int32, id, parentID int64) ([]int64, error) {
+
+	parent, ok := pending[parentID]
+	if !ok {
+		return repo.db.PostPath(threadID, id, parentID)
+	}
+
+	path := make([]int64, 0, len(parent.Path)+1)
+	path = append(path, parent.Path...)
+	return append(path, id), nil
+}
+
+func copyPosts(stored []*memoryDB.Post) models.Posts {
+	posts := make(models.Posts, 0, len(stored))
+	for _, storedPost := range stored {
+		posts = append(posts, storedPost.Post)
+	}
+	return posts
+}
+
+func flatSort(threadPosts []*memoryDB.Post, sincePostID *int64, desc bool, limit int64) []*memoryDB.Post {
+	selected := make([]*memoryDB.Post, 0)
+	for _, stored := range threadPosts {
+		if sincePostID != nil && (desc && stored.ID >= *sincePostID || !desc && stored.ID <= *sincePostID) {
+			continue
+		}
+		selected = append(selected, stored)
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		if desc {
+			return selected[i].ID > selected[j].ID
+		}
+		return selected[i].ID < selected[j].ID
+	})
+
+	return limitPosts(selected, limit)
+}
+
+func treeSort(threadPosts []*memoryDB.Post, sincePost *memoryDB.Post, desc bool, limit int64) []*memoryDB.Post {
+	selected := make([]*memoryDB.Post, 0)
+	for _, stored := range threadPosts {
+		if sincePost != nil {
+			cmp := memoryDB.ComparePaths(stored.Path, sincePost.Path)
+			if desc && cmp >= 0 || !desc && cmp <= 0 {
+				continue
+			}
+		}
+		selected = append(selected, stored)
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		cmp := memoryDB.ComparePaths(selected[i].Path, selected[j].Path)
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	return limitPosts(selected, limit)
+}
+
+// parentTreeSort limits root posts, desc order is applied only to roots, their subtrees are always ascending
+func parentTreeSort(threadPosts []*memoryDB.Post, sincePost *memoryDB.Post,
+	desc bool, limit int64) []*memoryDB.Post {
+
+	roots := make([]int64, 0)
+	for _, stored := range threadPosts {
+		if stored.Parent != 0 {
+			continue
+		}
+		if sincePost != nil {
+			sinceRoot := sincePost.Path[0]
+			if desc && stored.ID >= sinceRoot || !desc && stored.ID <= sinceRoot {
+				continue
+			}
+		}
+		roots = append(roots, stored.ID)
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		if desc {
+			return roots[i] > roots[j]
+		}
+		return roots[i] < roots[j]
+	})
+	if limit >= 0 && int64(len(roots)) > limit {
+		roots = roots[:limit]
+	}
+
+	rootsOrder := make(map[int64]int, len(roots))
+	for i, root := range roots {
+		rootsOrder[root] = i
+	}
+
+	selected := make([]*memoryDB.Post, 0)
+	for _, stored := range threadPosts {
+		if _, ok := rootsOrder[stored.Path[0]]; ok {
+			selected = append(selected, stored)
+		}
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		left, right := selected[i], selected[j]
+		if left.Path[0] != right.Path[0] {
+			return rootsOrder[left.Path[0]] < rootsOrder[right.Path[0]]
+		}
+		return memoryDB.ComparePaths(left.Path[1:], right.Path[1:]) < 0
+	})
+
+	return selected
+}
+
+func limitPosts(posts []*memoryDB.Post, limit int64) []*memoryDB.Post {
+	if limit >= 0 && int64(len(posts)) > limit {
+		return posts[:limit]
+	}
+	return posts
+}
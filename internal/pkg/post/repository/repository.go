@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
 	"github.com/nickeskov/db_forum/internal/pkg/post"
 	"github.com/nickeskov/db_forum/internal/pkg/utils/database/driver/pgx/codes"
+	webhookRepository "github.com/nickeskov/db_forum/internal/pkg/webhook/repository"
 	sqlHelpers "github.com/nickeskov/db_forum/pkg/sql"
 	pgx4Helpers "github.com/nickeskov/db_forum/pkg/sql/pgx/v4"
 	"github.com/pkg/errors"
@@ -23,15 +25,13 @@ func NewRepository(db *pgxpool.Pool) Repository {
 	}
 }
 
-func (repo Repository) CreatePostsInThread(thread models.Thread,
+func (repo Repository) CreatePostsInThread(ctx context.Context, thread models.Thread,
 	posts models.Posts) (insertedPosts models.Posts, err error) {
 
 	if len(posts) == 0 {
 		return make(models.Posts, 0), nil
 	}
 
-	ctx := context.Background()
-
 	tx, err := repo.db.Begin(ctx)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -40,16 +40,28 @@ func (repo Repository) CreatePostsInThread(thread models.Thread,
 		err = pgx4Helpers.FinishPgx4Transaction(ctx, tx, err)
 	}()
 
+	// thread could be moved to other forum or locked after caller read it,
+	// shared lock keeps its forum and state till commit
+	err = tx.QueryRow(ctx, `SELECT forum_slug, state FROM threads WHERE id = $1 FOR SHARE`, thread.ID).
+		Scan(&thread.Forum, &thread.State)
+	switch {
+	case err == pgx.ErrNoRows:
+		return nil, models.ErrDoesNotExist
+	case err != nil:
+		return nil, errors.WithStack(err)
+	case thread.State == models.LockedThreadState || thread.State == models.ArchivedThreadState:
+		return nil, errors.Wrapf(models.ErrAccessDenied, "thread is %s", thread.State)
+	}
+
 	batch := createPostsBatch(thread, posts)
 
 	batchResults := tx.SendBatch(ctx, batch)
-	defer func() {
-		if closeErr := batchResults.Close(); closeErr != nil {
-			err = errors.Wrap(err, closeErr.Error())
-		}
-	}()
 
 	insertedPosts, err = getInsertedPosts(batchResults, batch.Len())
+	// batch must be closed before next query in transaction
+	if closeErr := batchResults.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
 
 	if pgxErr := codes.ExtractPgx4ErrorCode(err); pgxErr != nil {
 		switch pgxErr.Error() {
@@ -62,13 +74,23 @@ func (repo Repository) CreatePostsInThread(thread models.Thread,
 				"some error post creating with thread=%+v, posts=%+v", thread, posts)
 		}
 	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 
-	return insertedPosts, errors.WithStack(err)
-}
+	payloads := make([]interface{}, 0, len(insertedPosts))
+	for _, insertedPost := range insertedPosts {
+		payloads = append(payloads, insertedPost)
+	}
+	if err = webhookRepository.InsertEvents(ctx, tx, thread.Forum,
+		models.PostCreatedWebhookEvent, payloads...); err != nil {
+		return nil, err
+	}
 
-func (repo Repository) GetPostByID(id int64) (models.Post, error) {
-	ctx := context.Background()
+	return insertedPosts, nil
+}
 
+func (repo Repository) GetPostByID(ctx context.Context, id int64) (models.Post, error) {
 	var postModel models.Post
 
 	row := repo.db.QueryRow(ctx, `
@@ -77,6 +99,7 @@ func (repo Repository) GetPostByID(id int64) (models.Post, error) {
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -97,32 +120,178 @@ func (repo Repository) GetPostByID(id int64) (models.Post, error) {
 	}
 }
 
-func (repo Repository) UpdatePostByID(post models.Post) (models.Post, error) {
-	ctx := context.Background()
+// UpdatePostByID changes post message and stores previous one as revision edited by post.Author,
+// author of post is editor if post.Author is empty
+func (repo Repository) UpdatePostByID(ctx context.Context,
+	post models.Post) (updatedPost models.Post, err error) {
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return models.Post{}, errors.WithStack(err)
+	}
+	defer func() {
+		err = pgx4Helpers.FinishPgx4Transaction(ctx, tx, err)
+	}()
+
+	row := tx.QueryRow(ctx, `
+			SELECT id,
+				   thread_id,
+				   author_nickname,
+				   forum_slug,
+				   is_edited,
+				   is_deleted,
+				   message,
+				   parent,
+				   created
+			FROM posts
+			WHERE id = $1
+				FOR UPDATE`,
+		post.ID,
+	)
+
+	switch err := scanPosts(row, &updatedPost); err {
+	case nil:
+	case pgx.ErrNoRows:
+		return models.Post{}, models.ErrDoesNotExist
+	default:
+		return models.Post{}, errors.WithStack(err)
+	}
+
+	if post.Message == "" || post.Message == updatedPost.Message {
+		return updatedPost, nil
+	}
+	if updatedPost.IsDeleted {
+		return models.Post{}, models.ErrConflict // deleted post must be restored before edit
+	}
+
+	editor := post.Author
+	if editor == "" {
+		editor = updatedPost.Author
+	}
 
-	var postMessage *string
+	// post row is locked, so revision numbers can't race
+	_, err = tx.Exec(ctx, `
+			INSERT INTO post_revisions (post_id, number, author_nickname, message)
+			SELECT $1, COALESCE(MAX(number), 0) + 1, $2, $3
+			FROM post_revisions
+			WHERE post_id = $1`,
+		updatedPost.ID,
+		editor,
+		updatedPost.Message,
+	)
 
-	if post.Message != "" {
-		postMessage = &post.Message
+	if pgxErr := codes.ExtractPgx4ErrorCode(err); pgxErr != nil {
+		switch pgxErr.Error() {
+		case codes.ErrCodeForeignKey:
+			return models.Post{}, models.ErrDoesNotExist // editor does not exist
+		default:
+			return models.Post{}, errors.Wrapf(err,
+				"some error while saving revision of post=%+v", updatedPost)
+		}
 	}
 
+	_, err = tx.Exec(ctx, `
+			UPDATE posts
+			SET message   = $2,
+				is_edited = TRUE
+			WHERE id = $1`,
+		updatedPost.ID,
+		post.Message,
+	)
+	if err != nil {
+		return models.Post{}, errors.WithStack(err)
+	}
+
+	updatedPost.Message = post.Message
+	updatedPost.IsEdited = true
+
+	if err = webhookRepository.InsertEvents(ctx, tx, updatedPost.Forum,
+		models.PostEditedWebhookEvent, updatedPost.HideDeleted()); err != nil {
+		return models.Post{}, err
+	}
+
+	return updatedPost, nil
+}
+
+// GetPostRevisions returns revisions of post ordered by number
+func (repo Repository) GetPostRevisions(ctx context.Context, postID int64) (models.PostRevisions, error) {
+	rows, err := repo.db.Query(ctx, `
+			SELECT post_id, number, author_nickname, message, created
+			FROM post_revisions
+			WHERE post_id = $1
+			ORDER BY number`,
+		postID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer rows.Close()
+
+	revisions := make(models.PostRevisions, 0)
+	for rows.Next() {
+		var revision models.PostRevision
+
+		if err := scanPostRevision(rows, &revision); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, errors.WithStack(rows.Err())
+}
+
+func (repo Repository) GetPostRevision(ctx context.Context, postID int64,
+	number int32) (models.PostRevision, error) {
+
+	var revision models.PostRevision
+
+	row := repo.db.QueryRow(ctx, `
+			SELECT post_id, number, author_nickname, message, created
+			FROM post_revisions
+			WHERE post_id = $1
+			  AND number = $2`,
+		postID,
+		number,
+	)
+
+	switch err := scanPostRevision(row, &revision); err {
+	case nil:
+		return revision, nil
+
+	case pgx.ErrNoRows:
+		return models.PostRevision{}, models.ErrDoesNotExist
+
+	default:
+		return models.PostRevision{}, errors.WithStack(err)
+	}
+}
+
+// DeletePostByID marks post as deleted, post keeps its place in thread tree and is not counted in forum posts
+func (repo Repository) DeletePostByID(ctx context.Context, id int64) (models.Post, error) {
+	return repo.setPostIsDeleted(ctx, id, true)
+}
+
+func (repo Repository) RestorePostByID(ctx context.Context, id int64) (models.Post, error) {
+	return repo.setPostIsDeleted(ctx, id, false)
+}
+
+func (repo Repository) setPostIsDeleted(ctx context.Context, id int64, isDeleted bool) (models.Post, error) {
+	var postModel models.Post
+
 	row := repo.db.QueryRow(ctx, `
 			UPDATE posts
-			SET message   = COALESCE($2, message),
-				is_edited = CASE
-								WHEN (is_edited = TRUE
-									OR (is_edited = FALSE AND $2 IS NOT NULL AND $2 <> message)) THEN TRUE
-								ELSE FALSE
-					END
+			SET is_deleted = $2
 			WHERE id = $1
-			RETURNING id, thread_id, author_nickname, forum_slug, is_edited, message, parent, created`,
-		post.ID,
-		postMessage,
+			RETURNING id, thread_id, author_nickname, forum_slug, is_edited, is_deleted, message, parent, created`,
+		id,
+		isDeleted,
 	)
 
-	switch err := scanPosts(row, &post); err {
+	switch err := scanPosts(row, &postModel); err {
 	case nil:
-		return post, nil
+		return postModel, nil
 
 	case pgx.ErrNoRows:
 		return models.Post{}, models.ErrDoesNotExist
@@ -132,6 +301,127 @@ func (repo Repository) UpdatePostByID(post models.Post) (models.Post, error) {
 	}
 }
 
+func (repo Repository) SplitPostByID(ctx context.Context, id int64,
+	thread models.Thread, leaveStub bool) (newThread models.Thread, err error) {
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return models.Thread{}, errors.WithStack(err)
+	}
+	defer func() {
+		err = pgx4Helpers.FinishPgx4Transaction(ctx, tx, err)
+	}()
+
+	var root models.Post
+
+	row := tx.QueryRow(ctx, `
+			SELECT id,
+				   thread_id,
+				   author_nickname,
+				   forum_slug,
+				   is_edited,
+				   is_deleted,
+				   message,
+				   parent,
+				   created
+			FROM posts
+			WHERE id = $1
+				FOR UPDATE`,
+		id,
+	)
+
+	switch err := scanPosts(row, &root); err {
+	case nil:
+	case pgx.ErrNoRows:
+		return models.Thread{}, models.ErrDoesNotExist
+	default:
+		return models.Thread{}, errors.WithStack(err)
+	}
+
+	var threadSlug *string
+	if thread.Slug != "" {
+		threadSlug = &thread.Slug
+	}
+
+	newThread = thread
+	newThread.Author = root.Author
+	newThread.Forum = root.Forum
+	newThread.Message = root.HideDeleted().Message
+
+	err = tx.QueryRow(ctx, `
+			INSERT INTO threads (slug, author_nickname, title, message, created, forum_slug)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, votes, created, state, is_pinned`,
+		threadSlug,
+		newThread.Author,
+		newThread.Title,
+		newThread.Message,
+		root.Created,
+		newThread.Forum,
+	).Scan(
+		&newThread.ID,
+		&newThread.Votes,
+		&newThread.Created,
+		&newThread.State,
+		&newThread.IsPinned,
+	)
+
+	if pgxErr := codes.ExtractPgx4ErrorCode(err); pgxErr != nil {
+		switch pgxErr.Error() {
+		case codes.ErrCodeUnique:
+			return models.Thread{}, models.ErrConflict // thread with same slug already exists
+		default:
+			return models.Thread{}, errors.Wrapf(err,
+				"some error while splitting postID=%d into thread=%+v", id, thread)
+		}
+	}
+	if err != nil {
+		return models.Thread{}, errors.WithStack(err)
+	}
+
+	// subtree is selected by path prefix, paths are cut to start from root of subtree
+	_, err = tx.Exec(ctx, `
+			UPDATE posts AS p
+			SET thread_id = $2,
+				path      = p.path[ARRAY_LENGTH(root.path, 1):],
+				parent    = CASE WHEN p.id = root.id THEN NULL ELSE p.parent END
+			FROM (SELECT id, thread_id, path FROM posts WHERE id = $1) AS root
+			WHERE p.thread_id = root.thread_id
+			  AND p.path >= root.path
+			  AND p.path[1:ARRAY_LENGTH(root.path, 1)] = root.path`,
+		id,
+		newThread.ID,
+	)
+	if err != nil {
+		return models.Thread{}, errors.Wrapf(err,
+			"some error while splitting postID=%d into threadID=%d", id, newThread.ID)
+	}
+
+	if leaveStub {
+		var parent *int64
+		if root.Parent != 0 {
+			parent = &root.Parent
+		}
+
+		_, err = tx.Exec(ctx, `
+				INSERT INTO posts (thread_id, author_nickname, forum_slug, message, parent, created)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+			root.Thread,
+			root.Author,
+			root.Forum,
+			fmt.Sprintf(models.MovedPostMessageFormat, newThread.ID),
+			parent,
+			time.Now(),
+		)
+		if err != nil {
+			return models.Thread{}, errors.Wrapf(err,
+				"some error while creating stub of postID=%d split into threadID=%d", id, newThread.ID)
+		}
+	}
+
+	return newThread, nil
+}
+
 func createPostsBatch(thread models.Thread, posts models.Posts) *pgx.Batch {
 	batch := new(pgx.Batch)
 
@@ -148,7 +438,7 @@ func createPostsBatch(thread models.Thread, posts models.Posts) *pgx.Batch {
 		batch.Queue(`
 				INSERT INTO posts (thread_id, author_nickname, forum_slug, message, parent, created)
 				VALUES ($1, $2, $3, $4, $5, $6) 
-				RETURNING id, thread_id, author_nickname, forum_slug, is_edited, message, parent, created`,
+				RETURNING id, thread_id, author_nickname, forum_slug, is_edited, is_deleted, message, parent, created`,
 			thread.ID,
 			postModel.Author,
 			thread.Forum,
@@ -173,11 +463,9 @@ func getInsertedPosts(batchResults pgx.BatchResults, batchLen int) (models.Posts
 	return insertedPosts, nil
 }
 
-func (repo Repository) GetSortedPostsByThreadSlugOrID(threadID int32, sincePostID *int64,
+func (repo Repository) GetSortedPostsByThreadSlugOrID(ctx context.Context, threadID int32, sincePostID *int64,
 	sort post.PostsSortType, desc bool, limit int64) (models.Posts, error) { // err = {nil, modesl.ErrInvalid unknown}
 
-	ctx := context.Background()
-
 	var err error
 	var rows pgx.Rows
 
@@ -199,20 +487,112 @@ func (repo Repository) GetSortedPostsByThreadSlugOrID(threadID int32, sincePostI
 		return nil, errors.WithStack(err)
 	}
 
+	return scanPostsRows(rows)
+}
+
+func (repo Repository) GetPostChildren(ctx context.Context, id int64, sincePostID *int64,
+	desc bool, limit int64) (models.Posts, error) {
+
+	if _, _, err := getPostPath(ctx, repo.db, id); err != nil {
+		return nil, err
+	}
+
+	var err error
+	var rows pgx.Rows
+
+	if sincePostID != nil {
+		rows, err = repo.db.Query(ctx, sqlGetPostChildrenSince[desc], id, *sincePostID, limit)
+	} else {
+		rows, err = repo.db.Query(ctx, sqlGetPostChildren[desc], id, limit)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return scanPostsRows(rows)
+}
+
+func (repo Repository) GetPostDescendants(ctx context.Context, id int64, depth int32, sincePostID *int64,
+	desc bool, limit int64) (models.Posts, error) {
+
+	threadID, path, err := getPostPath(ctx, repo.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows pgx.Rows
+
+	if sincePostID != nil {
+		rows, err = repo.db.Query(ctx, sqlGetPostDescendantsSince[desc],
+			threadID, path, len(path), depth, *sincePostID, limit)
+	} else {
+		rows, err = repo.db.Query(ctx, sqlGetPostDescendants[desc],
+			threadID, path, len(path), depth, limit)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return scanPostsRows(rows)
+}
+
+func (repo Repository) GetPostAncestors(ctx context.Context, id int64) (models.Posts, error) {
+	_, path, err := getPostPath(ctx, repo.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(ctx, `
+			SELECT id,
+				   thread_id,
+				   author_nickname,
+				   forum_slug,
+				   is_edited,
+				   is_deleted,
+				   message,
+				   parent,
+				   created
+			FROM posts
+			WHERE id = ANY ($1::BIGINT[])
+			ORDER BY path`,
+		path[:len(path)-1],
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return scanPostsRows(rows)
+}
+
+// getPostPath returns thread and materialized path of post
+func getPostPath(ctx context.Context, querier pgx4Helpers.Querier, id int64) (int32, []int64, error) {
+	var threadID int32
+	var path []int64
+
+	err := querier.QueryRow(ctx, `SELECT thread_id, path FROM posts WHERE id = $1`, id).Scan(&threadID, &path)
+	switch {
+	case err == pgx.ErrNoRows:
+		return 0, nil, models.ErrDoesNotExist
+	case err != nil:
+		return 0, nil, errors.Wrapf(err, "some error while selecting path of postID=%d", id)
+	}
+
+	return threadID, path, nil
+}
+
+func scanPostsRows(rows pgx.Rows) (models.Posts, error) {
 	defer rows.Close()
 
 	posts := make(models.Posts, 0)
 	for rows.Next() {
 		var postModel models.Post
-
 		if err := scanPosts(rows, &postModel); err != nil {
 			return nil, errors.WithStack(err)
 		}
-
 		posts = append(posts, postModel)
 	}
 
-	return posts, nil
+	return posts, errors.WithStack(rows.Err())
 }
 
 func scanPosts(scanner sqlHelpers.Scanner, postDst *models.Post) error {
@@ -224,6 +604,7 @@ func scanPosts(scanner sqlHelpers.Scanner, postDst *models.Post) error {
 		&postDst.Author,
 		&postDst.Forum,
 		&postDst.IsEdited,
+		&postDst.IsDeleted,
 		&postDst.Message,
 		&postParent,
 		&postDst.Created,
@@ -238,3 +619,13 @@ func scanPosts(scanner sqlHelpers.Scanner, postDst *models.Post) error {
 
 	return nil
 }
+
+func scanPostRevision(scanner sqlHelpers.Scanner, revisionDst *models.PostRevision) error {
+	return scanner.Scan(
+		&revisionDst.Post,
+		&revisionDst.Number,
+		&revisionDst.Author,
+		&revisionDst.Message,
+		&revisionDst.Created,
+	)
+}
@@ -10,6 +10,7 @@ var sqlGetSortedPostsSince = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -24,6 +25,7 @@ var sqlGetSortedPostsSince = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -47,6 +49,7 @@ var sqlGetSortedPostsSince = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -62,6 +65,7 @@ var sqlGetSortedPostsSince = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -76,6 +80,7 @@ var sqlGetSortedPostsSince = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -99,6 +104,7 @@ var sqlGetSortedPostsSince = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -117,6 +123,7 @@ var sqlGetSortedPosts = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -131,6 +138,7 @@ var sqlGetSortedPosts = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -151,6 +159,7 @@ var sqlGetSortedPosts = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -166,6 +175,7 @@ var sqlGetSortedPosts = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -180,6 +190,7 @@ var sqlGetSortedPosts = map[bool]map[post.PostsSortType]string{
 					   author_nickname,
 					   forum_slug,
 					   is_edited,
+					   is_deleted,
 					   message,
 					   parent,
 					   created
@@ -200,6 +211,7 @@ var sqlGetSortedPosts = map[bool]map[post.PostsSortType]string{
 				   author_nickname,
 				   forum_slug,
 				   is_edited,
+				   is_deleted,
 				   message,
 				   parent,
 				   created
@@ -209,3 +221,149 @@ var sqlGetSortedPosts = map[bool]map[post.PostsSortType]string{
 			ORDER BY path`,
 	},
 }
+
+var sqlGetPostChildrenSince = map[bool]string{
+	true: `
+		SELECT id,
+			   thread_id,
+			   author_nickname,
+			   forum_slug,
+			   is_edited,
+			   is_deleted,
+			   message,
+			   parent,
+			   created
+		FROM posts
+		WHERE parent = $1
+		  AND id < $2
+		ORDER BY id DESC
+		LIMIT $3`,
+
+	false: `
+		SELECT id,
+			   thread_id,
+			   author_nickname,
+			   forum_slug,
+			   is_edited,
+			   is_deleted,
+			   message,
+			   parent,
+			   created
+		FROM posts
+		WHERE parent = $1
+		  AND id > $2
+		ORDER BY id
+		LIMIT $3`,
+}
+
+var sqlGetPostChildren = map[bool]string{
+	true: `
+		SELECT id,
+			   thread_id,
+			   author_nickname,
+			   forum_slug,
+			   is_edited,
+			   is_deleted,
+			   message,
+			   parent,
+			   created
+		FROM posts
+		WHERE parent = $1
+		ORDER BY id DESC
+		LIMIT $2`,
+
+	false: `
+		SELECT id,
+			   thread_id,
+			   author_nickname,
+			   forum_slug,
+			   is_edited,
+			   is_deleted,
+			   message,
+			   parent,
+			   created
+		FROM posts
+		WHERE parent = $1
+		ORDER BY id
+		LIMIT $2`,
+}
+
+// descendants are selected by prefix $2 of length $3 of their paths,
+// depth $4 limits length of descendants paths if positive
+var sqlGetPostDescendantsSince = map[bool]string{
+	true: `
+		SELECT id,
+			   thread_id,
+			   author_nickname,
+			   forum_slug,
+			   is_edited,
+			   is_deleted,
+			   message,
+			   parent,
+			   created
+		FROM posts
+		WHERE thread_id = $1
+		  AND path > $2
+		  AND path[1:$3] = $2
+		  AND ($4 <= 0 OR ARRAY_LENGTH(path, 1) <= $3 + $4)
+		  AND path < (SELECT path FROM posts WHERE id = $5)
+		ORDER BY path DESC
+		LIMIT $6`,
+
+	false: `
+		SELECT id,
+			   thread_id,
+			   author_nickname,
+			   forum_slug,
+			   is_edited,
+			   is_deleted,
+			   message,
+			   parent,
+			   created
+		FROM posts
+		WHERE thread_id = $1
+		  AND path > $2
+		  AND path[1:$3] = $2
+		  AND ($4 <= 0 OR ARRAY_LENGTH(path, 1) <= $3 + $4)
+		  AND path > (SELECT path FROM posts WHERE id = $5)
+		ORDER BY path
+		LIMIT $6`,
+}
+
+var sqlGetPostDescendants = map[bool]string{
+	true: `
+		SELECT id,
+			   thread_id,
+			   author_nickname,
+			   forum_slug,
+			   is_edited,
+			   is_deleted,
+			   message,
+			   parent,
+			   created
+		FROM posts
+		WHERE thread_id = $1
+		  AND path > $2
+		  AND path[1:$3] = $2
+		  AND ($4 <= 0 OR ARRAY_LENGTH(path, 1) <= $3 + $4)
+		ORDER BY path DESC
+		LIMIT $5`,
+
+	false: `
+		SELECT id,
+			   thread_id,
+			   author_nickname,
+			   forum_slug,
+			   is_edited,
+			   is_deleted,
+			   message,
+			   parent,
+			   created
+		FROM posts
+		WHERE thread_id = $1
+		  AND path > $2
+		  AND path[1:$3] = $2
+		  AND ($4 <= 0 OR ARRAY_LENGTH(path, 1) <= $3 + $4)
+		ORDER BY path
+		LIMIT $5`,
+}
@@ -1,11 +1,23 @@
 package post
 
-import "github.com/nickeskov/db_forum/internal/pkg/models"
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+)
 
 type UseCase interface {
-	CreatePostsByThreadSlugOrID(threadSlugOrID string, posts models.Posts) (models.Posts, error)
-	GetPostInfoByID(id int64, related []string) (models.PostFullInfo, error)
-	UpdatePostByID(post models.Post) (models.Post, error)
-	GetSortedPostsByThreadSlugOrID(threadSlugOrID, sincePostID,
+	CreatePostsByThreadSlugOrID(ctx context.Context,
+		threadSlugOrID string, posts models.Posts) (models.Posts, error)
+	GetPostInfoByID(ctx context.Context, id int64, related []string) (models.PostFullInfo, error)
+	UpdatePostByID(ctx context.Context, post models.Post) (models.Post, error)
+	DeletePostByID(ctx context.Context, id int64) (models.Post, error)
+	GetPostRevisions(ctx context.Context, id int64) (models.PostRevisions, error)
+	GetPostRevision(ctx context.Context, id int64, number int32) (models.PostRevision, error)
+	RestorePostByID(ctx context.Context, id int64) (models.Post, error)
+	SplitPostByID(ctx context.Context, id int64, split models.PostSplit) (models.Thread, error)
+	GetSortedPostsByThreadSlugOrID(ctx context.Context, threadSlugOrID, sincePostID,
 		sort, desc, limit string) (models.Posts, error)
+	GetPostChildren(ctx context.Context, id int64, sincePostID, desc, limit string) (models.Posts, error)
+	GetPostDescendants(ctx context.Context, id int64, depth, sincePostID, desc, limit string) (models.Posts, error)
+	GetPostAncestors(ctx context.Context, id int64) (models.Posts, error)
 }
@@ -0,0 +1,110 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/post"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const useCaseName = "post"
+
+// UseCase traces every method of wrapped usecase
+type UseCase struct {
+	useCase  post.UseCase
+	observer instrumentation.Observer
+}
+
+func NewUseCase(useCase post.UseCase, tracer trace.Tracer) UseCase {
+	return UseCase{
+		useCase:  useCase,
+		observer: instrumentation.NewObserver(useCaseName, "usecase", nil, tracer),
+	}
+}
+
+func (useCase UseCase) CreatePostsByThreadSlugOrID(ctx context.Context,
+	threadSlugOrID string, posts models.Posts) (_ models.Posts, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "CreatePostsByThreadSlugOrID")
+	defer func() { done(err) }()
+	return useCase.useCase.CreatePostsByThreadSlugOrID(ctx, threadSlugOrID, posts)
+}
+
+func (useCase UseCase) GetPostInfoByID(ctx context.Context,
+	id int64, related []string) (_ models.PostFullInfo, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "GetPostInfoByID")
+	defer func() { done(err) }()
+	return useCase.useCase.GetPostInfoByID(ctx, id, related)
+}
+
+func (useCase UseCase) UpdatePostByID(ctx context.Context, post models.Post) (_ models.Post, err error) {
+	ctx, done := useCase.observer.Start(ctx, "UpdatePostByID")
+	defer func() { done(err) }()
+	return useCase.useCase.UpdatePostByID(ctx, post)
+}
+
+func (useCase UseCase) DeletePostByID(ctx context.Context, id int64) (_ models.Post, err error) {
+	ctx, done := useCase.observer.Start(ctx, "DeletePostByID")
+	defer func() { done(err) }()
+	return useCase.useCase.DeletePostByID(ctx, id)
+}
+
+func (useCase UseCase) GetPostRevisions(ctx context.Context, id int64) (_ models.PostRevisions, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GetPostRevisions")
+	defer func() { done(err) }()
+	return useCase.useCase.GetPostRevisions(ctx, id)
+}
+
+func (useCase UseCase) GetPostRevision(ctx context.Context,
+	id int64, number int32) (_ models.PostRevision, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "GetPostRevision")
+	defer func() { done(err) }()
+	return useCase.useCase.GetPostRevision(ctx, id, number)
+}
+
+func (useCase UseCase) RestorePostByID(ctx context.Context, id int64) (_ models.Post, err error) {
+	ctx, done := useCase.observer.Start(ctx, "RestorePostByID")
+	defer func() { done(err) }()
+	return useCase.useCase.RestorePostByID(ctx, id)
+}
+
+func (useCase UseCase) SplitPostByID(ctx context.Context,
+	id int64, split models.PostSplit) (_ models.Thread, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "SplitPostByID")
+	defer func() { done(err) }()
+	return useCase.useCase.SplitPostByID(ctx, id, split)
+}
+
+func (useCase UseCase) GetSortedPostsByThreadSlugOrID(ctx context.Context,
+	threadSlugOrID, sincePostID, sort, desc, limit string) (_ models.Posts, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "GetSortedPostsByThreadSlugOrID")
+	defer func() { done(err) }()
+	return useCase.useCase.GetSortedPostsByThreadSlugOrID(ctx, threadSlugOrID, sincePostID, sort, desc, limit)
+}
+
+func (useCase UseCase) GetPostChildren(ctx context.Context,
+	id int64, sincePostID, desc, limit string) (_ models.Posts, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "GetPostChildren")
+	defer func() { done(err) }()
+	return useCase.useCase.GetPostChildren(ctx, id, sincePostID, desc, limit)
+}
+
+func (useCase UseCase) GetPostDescendants(ctx context.Context,
+	id int64, depth, sincePostID, desc, limit string) (_ models.Posts, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "GetPostDescendants")
+	defer func() { done(err) }()
+	return useCase.useCase.GetPostDescendants(ctx, id, depth, sincePostID, desc, limit)
+}
+
+func (useCase UseCase) GetPostAncestors(ctx context.Context, id int64) (_ models.Posts, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GetPostAncestors")
+	defer func() { done(err) }()
+	return useCase.useCase.GetPostAncestors(ctx, id)
+}
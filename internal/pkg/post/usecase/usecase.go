@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/events"
 	"github.com/nickeskov/db_forum/internal/pkg/forum"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
 	"github.com/nickeskov/db_forum/internal/pkg/post"
@@ -15,28 +17,30 @@ type UseCase struct {
 	userRepo   user.Repository
 	forumRepo  forum.Repository
 	threadRepo thread.Repository
+	publisher  events.Publisher
 }
 
-func NewUseCase(repository post.Repository,
-	userRepo user.Repository, forumRepo forum.Repository, threadRepo thread.Repository) UseCase {
+func NewUseCase(repository post.Repository, userRepo user.Repository, forumRepo forum.Repository,
+	threadRepo thread.Repository, publisher events.Publisher) UseCase {
 	return UseCase{
 		repository: repository,
 		userRepo:   userRepo,
 		forumRepo:  forumRepo,
 		threadRepo: threadRepo,
+		publisher:  publisher,
 	}
 }
 
-func (useCase UseCase) CreatePostsByThreadSlugOrID(threadSlugOrID string,
+func (useCase UseCase) CreatePostsByThreadSlugOrID(ctx context.Context, threadSlugOrID string,
 	posts models.Posts) (models.Posts, error) {
 
 	var err error
 	var postsThread models.Thread
 
 	if id, convertErr := strconv.Atoi(threadSlugOrID); convertErr != nil {
-		postsThread, err = useCase.threadRepo.GetBySlug(threadSlugOrID)
+		postsThread, err = useCase.threadRepo.GetBySlug(ctx, threadSlugOrID)
 	} else {
-		postsThread, err = useCase.threadRepo.GetByID(int32(id))
+		postsThread, err = useCase.threadRepo.GetByID(ctx, int32(id))
 	}
 
 	switch {
@@ -46,13 +50,38 @@ func (useCase UseCase) CreatePostsByThreadSlugOrID(threadSlugOrID string,
 		return nil, errors.WithStack(err)
 	}
 
-	return useCase.repository.CreatePostsInThread(postsThread, posts)
+	checkedAuthors := make(map[string]bool)
+	for _, newPost := range posts {
+		if checkedAuthors[newPost.Author] {
+			continue
+		}
+		err := forum.CheckNotBanned(ctx, useCase.forumRepo, postsThread.Forum, newPost.Author)
+		switch {
+		case errors.Is(err, models.ErrAccessDenied):
+			return nil, errors.Wrapf(err, "author with nickname=%s is banned", newPost.Author)
+		case err != nil:
+			return nil, err
+		}
+		checkedAuthors[newPost.Author] = true
+	}
+
+	createdPosts, err := useCase.repository.CreatePostsInThread(ctx, postsThread, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, createdPost := range createdPosts {
+		useCase.publisher.Publish(events.PostCreatedEvent, createdPost,
+			events.ActivityTopics(createdPost.Thread, createdPost.Forum, createdPost.Author)...)
+	}
+
+	return createdPosts, nil
 }
 
-func (useCase UseCase) GetPostInfoByID(id int64,
+func (useCase UseCase) GetPostInfoByID(ctx context.Context, id int64,
 	related []string) (postFullInfo models.PostFullInfo, err error) {
 
-	postModel, err := useCase.repository.GetPostByID(id)
+	postModel, err := useCase.repository.GetPostByID(ctx, id)
 	switch {
 	case errors.Is(err, models.ErrDoesNotExist):
 		return models.PostFullInfo{}, models.ErrDoesNotExist
@@ -60,31 +89,40 @@ func (useCase UseCase) GetPostInfoByID(id int64,
 		return models.PostFullInfo{}, errors.WithStack(err)
 	}
 
+	postModel = postModel.HideDeleted()
 	postFullInfo.Post = &postModel
 
 	for _, entityName := range related {
 		switch entityName {
 		case "user":
-			relatedAuthor, relatedErr := useCase.userRepo.GetByNickname(postModel.Author)
+			relatedAuthor, relatedErr := useCase.userRepo.GetByNickname(ctx, postModel.Author)
 			if relatedErr != nil {
 				err = relatedErr
 			} else {
 				postFullInfo.Author = &relatedAuthor
 			}
 		case "forum":
-			relatedForum, relatedErr := useCase.forumRepo.GetBySlug(postModel.Forum)
+			relatedForum, relatedErr := useCase.forumRepo.GetBySlug(ctx, postModel.Forum)
 			if relatedErr != nil {
 				err = relatedErr
 			} else {
 				postFullInfo.Forum = &relatedForum
 			}
 		case "thread":
-			relatedThread, relatedErr := useCase.threadRepo.GetByID(postModel.Thread)
+			relatedThread, relatedErr := useCase.threadRepo.GetByID(ctx, postModel.Thread)
 			if relatedErr != nil {
 				err = relatedErr
 			} else {
 				postFullInfo.Thread = &relatedThread
 			}
+		case "revisions":
+			relatedRevisions, relatedErr := useCase.repository.GetPostRevisions(ctx, postModel.ID)
+			if relatedErr != nil {
+				err = relatedErr
+			} else {
+				relatedRevisions = relatedRevisions.HideDeleted(postModel)
+				postFullInfo.Revisions = &relatedRevisions
+			}
 		default:
 			return models.PostFullInfo{}, models.ErrInvalid
 		}
@@ -100,46 +138,84 @@ func (useCase UseCase) GetPostInfoByID(id int64,
 	return postFullInfo, nil
 }
 
-func (useCase UseCase) UpdatePostByID(post models.Post) (models.Post, error) {
-	return useCase.repository.UpdatePostByID(post)
+func (useCase UseCase) UpdatePostByID(ctx context.Context, post models.Post) (models.Post, error) {
+	updatedPost, err := useCase.repository.UpdatePostByID(ctx, post)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	updatedPost = updatedPost.HideDeleted()
+	if post.Message != "" { // empty update returns post as is
+		useCase.publisher.Publish(events.PostUpdatedEvent, updatedPost,
+			events.ActivityTopics(updatedPost.Thread, updatedPost.Forum, updatedPost.Author)...)
+	}
+
+	return updatedPost, nil
 }
 
-func (useCase UseCase) GetSortedPostsByThreadSlugOrID(threadSlugOrID, sincePostID,
-	sort, desc, limit string) (models.Posts, error) {
+func (useCase UseCase) DeletePostByID(ctx context.Context, id int64) (models.Post, error) {
+	deletedPost, err := useCase.repository.DeletePostByID(ctx, id)
+	return deletedPost.HideDeleted(), err
+}
 
-	if _, ok := postsAllowedSortTypes[post.PostsSortType(sort)]; !ok {
-		sort = string(post.FlatSort)
+func (useCase UseCase) GetPostRevisions(ctx context.Context, id int64) (models.PostRevisions, error) {
+	postModel, err := useCase.repository.GetPostByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	var sincePostIDIntPtr *int64
-
-	if sincePostID != "" {
-		sincePostIDIntPtr = new(int64)
+	revisions, err := useCase.repository.GetPostRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-		sincePostIDInt, err := strconv.ParseInt(sincePostID, 10, 64)
-		if err != nil {
-			return nil, models.ErrInvalid
-		}
+	return revisions.HideDeleted(postModel), nil
+}
 
-		*sincePostIDIntPtr = sincePostIDInt
+func (useCase UseCase) GetPostRevision(ctx context.Context, id int64, number int32) (models.PostRevision, error) {
+	postModel, err := useCase.repository.GetPostByID(ctx, id)
+	if err != nil {
+		return models.PostRevision{}, err
 	}
 
-	descBool, err := strconv.ParseBool(desc)
+	revision, err := useCase.repository.GetPostRevision(ctx, id, number)
 	if err != nil {
-		return nil, models.ErrInvalid
+		return models.PostRevision{}, err
+	}
+
+	return models.PostRevisions{revision}.HideDeleted(postModel)[0], nil
+}
+
+func (useCase UseCase) RestorePostByID(ctx context.Context, id int64) (models.Post, error) {
+	return useCase.repository.RestorePostByID(ctx, id)
+}
+
+func (useCase UseCase) SplitPostByID(ctx context.Context, id int64, split models.PostSplit) (models.Thread, error) {
+	newThread := models.Thread{
+		Title: split.Title,
+		Slug:  split.Slug,
+	}
+	return useCase.repository.SplitPostByID(ctx, id, newThread, split.Stub)
+}
+
+func (useCase UseCase) GetSortedPostsByThreadSlugOrID(ctx context.Context, threadSlugOrID, sincePostID,
+	sort, desc, limit string) (models.Posts, error) {
+
+	if _, ok := postsAllowedSortTypes[post.PostsSortType(sort)]; !ok {
+		sort = string(post.FlatSort)
 	}
 
-	limitInt, err := strconv.ParseInt(limit, 10, 64)
+	sincePostIDIntPtr, descBool, limitInt, err := parseSinceDescLimit(sincePostID, desc, limit)
 	if err != nil {
-		return nil, models.ErrInvalid
+		return nil, err
 	}
 
 	var threadModel models.Thread
 
 	if id, convertErr := strconv.Atoi(threadSlugOrID); convertErr != nil {
-		threadModel, err = useCase.threadRepo.GetBySlug(threadSlugOrID)
+		threadModel, err = useCase.threadRepo.GetBySlug(ctx, threadSlugOrID)
 	} else {
-		threadModel, err = useCase.threadRepo.GetByID(int32(id))
+		threadModel, err = useCase.threadRepo.GetByID(ctx, int32(id))
 	}
 
 	switch {
@@ -149,13 +225,75 @@ func (useCase UseCase) GetSortedPostsByThreadSlugOrID(threadSlugOrID, sincePostI
 		return nil, errors.WithStack(err)
 	}
 
-	return useCase.repository.GetSortedPostsByThreadSlugOrID(
+	posts, err := useCase.repository.GetSortedPostsByThreadSlugOrID(
+		ctx,
 		threadModel.ID,
 		sincePostIDIntPtr,
 		post.PostsSortType(sort),
 		descBool,
 		limitInt,
 	)
+
+	return posts.HideDeleted(), err
+}
+
+func (useCase UseCase) GetPostChildren(ctx context.Context, id int64,
+	sincePostID, desc, limit string) (models.Posts, error) {
+
+	sincePostIDIntPtr, descBool, limitInt, err := parseSinceDescLimit(sincePostID, desc, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := useCase.repository.GetPostChildren(ctx, id, sincePostIDIntPtr, descBool, limitInt)
+	return posts.HideDeleted(), err
+}
+
+func (useCase UseCase) GetPostDescendants(ctx context.Context, id int64,
+	depth, sincePostID, desc, limit string) (models.Posts, error) {
+
+	sincePostIDIntPtr, descBool, limitInt, err := parseSinceDescLimit(sincePostID, desc, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var depthInt int64
+	if depth != "" {
+		if depthInt, err = strconv.ParseInt(depth, 10, 32); err != nil || depthInt < 0 {
+			return nil, models.ErrInvalid
+		}
+	}
+
+	posts, err := useCase.repository.GetPostDescendants(ctx, id, int32(depthInt),
+		sincePostIDIntPtr, descBool, limitInt)
+	return posts.HideDeleted(), err
+}
+
+func (useCase UseCase) GetPostAncestors(ctx context.Context, id int64) (models.Posts, error) {
+	posts, err := useCase.repository.GetPostAncestors(ctx, id)
+	return posts.HideDeleted(), err
+}
+
+// parseSinceDescLimit parses pagination query params of posts lists, since is optional
+func parseSinceDescLimit(since, desc, limit string) (sincePostID *int64, descBool bool, limitInt int64, err error) {
+	if since != "" {
+		sincePostIDInt, err := strconv.ParseInt(since, 10, 64)
+		if err != nil {
+			return nil, false, 0, models.ErrInvalid
+		}
+
+		sincePostID = &sincePostIDInt
+	}
+
+	if descBool, err = strconv.ParseBool(desc); err != nil {
+		return nil, false, 0, models.ErrInvalid
+	}
+
+	if limitInt, err = strconv.ParseInt(limit, 10, 64); err != nil {
+		return nil, false, 0, models.ErrInvalid
+	}
+
+	return sincePostID, descBool, limitInt, nil
 }
 
 var postsAllowedSortTypes = map[post.PostsSortType]bool{
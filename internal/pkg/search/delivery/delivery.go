@@ -0,0 +1,58 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/search"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"net/http"
+)
+
+type Delivery struct {
+	useCase search.UseCase
+	utils   httpUtils.Utils
+}
+
+func NewDelivery(useCase search.UseCase, logger logger.Logger) Delivery {
+	return Delivery{
+		useCase: useCase,
+		utils:   httpUtils.NewDeliveryUtils(logger),
+	}
+}
+
+func (delivery Delivery) Search(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	page, err := delivery.useCase.Search(r.Context(),
+		queryParams.Get("q"),
+		queryParams.Get("forum"),
+		queryParams.Get("author"),
+		queryParams.Get("since"),
+		queryParams.Get("until"),
+		queryParams.Get("cursor"),
+		queryParams.Get("limit"),
+	)
+
+	switch {
+	case errors.Is(err, models.ErrInvalid):
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest,
+			"q is required, since and until must be RFC3339 times, limit must be in range [1, 100], "+
+				"cursor must be next_cursor of previous page")
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(page)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"time"
+)
+
+type Repository interface {
+	Search(ctx context.Context, query Query) (models.SearchResults, error)
+}
+
+// Query finds posts and threads matching Text, empty filters are ignored
+type Query struct {
+	Text   string
+	Forum  string
+	Author string
+	Since  *time.Time // inclusive
+	Until  *time.Time // exclusive
+	After  *Cursor
+	Limit  int32
+}
+
+// Cursor is position of last result of previous page, results are ordered by rank, type and id descending
+type Cursor struct {
+	Rank float32 `json:"rank"`
+	Type string  `json:"type"`
+	ID   int64   `json:"id"`
+}
+
+// IsAfter reports whether result is placed after cursor in results order
+func (cursor Cursor) IsAfter(result models.SearchResult) bool {
+	switch {
+	case result.Rank != cursor.Rank:
+		return result.Rank < cursor.Rank
+	case result.Type != cursor.Type:
+		return result.Type < cursor.Type
+	default:
+		return result.ID < cursor.ID
+	}
+}
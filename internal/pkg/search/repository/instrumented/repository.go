@@ -0,0 +1,35 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/search"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const repositoryName = "search"
+
+// Repository traces every method of wrapped repository and observes its latency
+type Repository struct {
+	repository search.Repository
+	observer   instrumentation.Observer
+}
+
+// NewRepository wraps repository, durations must have repository and method labels.
+// Durations and tracer may be nil.
+func NewRepository(repository search.Repository, durations *prometheus.HistogramVec,
+	tracer trace.Tracer) Repository {
+
+	return Repository{
+		repository: repository,
+		observer:   instrumentation.NewObserver(repositoryName, "repository", durations, tracer),
+	}
+}
+
+func (repo Repository) Search(ctx context.Context, query search.Query) (_ models.SearchResults, err error) {
+	ctx, done := repo.observer.Start(ctx, "Search")
+	defer func() { done(err) }()
+	return repo.repository.Search(ctx, query)
+}
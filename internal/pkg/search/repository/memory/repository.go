@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/search"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	titleWeight   = 1.0
+	messageWeight = 0.4
+)
+
+// Repository matches documents containing all query words, it does not support
+// stemming and websearch operators, words of query are just lowercased
+type Repository struct {
+	db *memoryDB.DB
+}
+
+func NewRepository(db *memoryDB.DB) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) Search(ctx context.Context, query search.Query) (models.SearchResults, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	terms := words(query.Text)
+	if len(terms) == 0 {
+		return make(models.SearchResults, 0), nil
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	results := make(models.SearchResults, 0)
+	add := func(result models.SearchResult, text string) {
+		if result.Rank == 0 || !matchesFilters(query, result) {
+			return
+		}
+		if query.After != nil && !query.After.IsAfter(result) {
+			return
+		}
+		result.Snippet = highlight(text, terms)
+		results = append(results, result)
+	}
+
+	for _, stored := range repo.db.Posts {
+		if stored.IsDeleted {
+			continue
+		}
+		add(models.SearchResult{
+			Type:    models.PostSearchResult,
+			ID:      stored.ID,
+			Thread:  stored.Thread,
+			Forum:   stored.Forum,
+			Author:  stored.Author,
+			Rank:    rank(terms, stored.Message, messageWeight),
+			Created: stored.Created,
+		}, stored.Message)
+	}
+
+	for _, stored := range repo.db.Threads {
+		add(models.SearchResult{
+			Type:    models.ThreadSearchResult,
+			ID:      int64(stored.ID),
+			Thread:  stored.ID,
+			Forum:   stored.Forum,
+			Author:  stored.Author,
+			Title:   stored.Title,
+			Rank:    rank(terms, stored.Title, titleWeight) + rank(terms, stored.Message, messageWeight),
+			Created: stored.Created,
+		}, stored.Title+" "+stored.Message)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return search.Cursor{Rank: results[i].Rank, Type: results[i].Type, ID: results[i].ID}.IsAfter(results[j])
+	})
+
+	if query.Limit >= 0 && int(query.Limit) < len(results) {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+func matchesFilters(query search.Query, result models.SearchResult) bool {
+	switch {
+	case query.Forum != "" && memoryDB.CIKey(query.Forum) != memoryDB.CIKey(result.Forum),
+		query.Author != "" && memoryDB.CIKey(query.Author) != memoryDB.CIKey(result.Author),
+		query.Since != nil && result.Created.Before(*query.Since),
+		query.Until != nil && !result.Created.Before(*query.Until):
+		return false
+	}
+	return true
+}
+
+// rank is zero if text misses some term, otherwise it grows with terms frequency
+func rank(terms []string, text string, weight float32) float32 {
+	textWords := words(text)
+	if len(textWords) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(textWords))
+	for _, word := range textWords {
+		counts[word]++
+	}
+
+	matched := 0
+	for _, term := range terms {
+		if counts[term] == 0 {
+			return 0
+		}
+		matched += counts[term]
+	}
+
+	return weight * float32(matched) / float32(len(textWords))
+}
+
+// highlight wraps query terms in text with <b></b> like ts_headline does,
+// rest of text is HTML-escaped, so snippet can be shown as HTML
+func highlight(text string, terms []string) string {
+	isTerm := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		isTerm[term] = true
+	}
+
+	var builder strings.Builder
+	wordStart := -1
+	flush := func(end int) {
+		word := text[wordStart:end]
+		if isTerm[strings.ToLower(word)] {
+			builder.WriteString("<b>" + word + "</b>")
+		} else {
+			builder.WriteString(word)
+		}
+		wordStart = -1
+	}
+
+	for i, r := range text {
+		switch {
+		case isWordRune(r):
+			if wordStart < 0 {
+				wordStart = i
+			}
+		default:
+			if wordStart >= 0 {
+				flush(i)
+			}
+			builder.WriteString(html.EscapeString(string(r)))
+		}
+	}
+	if wordStart >= 0 {
+		flush(len(text))
+	}
+
+	return builder.String()
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/search"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) Search(ctx context.Context, query search.Query) (models.SearchResults, error) {
+	var cursorRank *float32
+	var cursorType *string
+	var cursorID *int64
+
+	if query.After != nil {
+		cursorRank, cursorType, cursorID = &query.After.Rank, &query.After.Type, &query.After.ID
+	}
+
+	rows, err := repo.db.Query(ctx, sqlSearch,
+		query.Text,
+		nullableString(query.Forum),
+		nullableString(query.Author),
+		nullableTime(query.Since),
+		nullableTime(query.Until),
+		cursorRank,
+		cursorType,
+		cursorID,
+		query.Limit,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer rows.Close()
+
+	results := make(models.SearchResults, 0)
+	for rows.Next() {
+		var result models.SearchResult
+
+		err := rows.Scan(
+			&result.Type,
+			&result.ID,
+			&result.Thread,
+			&result.Forum,
+			&result.Author,
+			&result.Title,
+			&result.Snippet,
+			&result.Rank,
+			&result.Created,
+		)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, errors.WithStack(rows.Err())
+}
+
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func nullableTime(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -0,0 +1,64 @@
+package repository
+
+// sqlSearch ranks posts and threads matched by websearch query,
+// snippets are built only for rows of result page because ts_headline is expensive.
+// Text is HTML-escaped before ts_headline, so only its <b></b> tags are markup of snippet,
+// parser reads escaped characters as entities, which are not indexed and not highlighted.
+const sqlSearch = `
+	WITH query AS (
+		SELECT websearch_to_tsquery('simple', $1) AS q
+	),
+		 found AS (
+			 SELECT 'post'                         AS type,
+					p.id,
+					p.thread_id,
+					p.forum_slug,
+					p.author_nickname,
+					''                             AS title,
+					p.message                      AS text,
+					ts_rank(p.search_vector, query.q) AS rank,
+					p.created
+			 FROM posts AS p,
+				  query
+			 WHERE p.search_vector @@ query.q
+			   AND NOT p.is_deleted
+			   AND ($2::CITEXT IS NULL OR p.forum_slug = $2)
+			   AND ($3::CITEXT IS NULL OR p.author_nickname = $3)
+			   AND ($4::TIMESTAMPTZ IS NULL OR p.created >= $4)
+			   AND ($5::TIMESTAMPTZ IS NULL OR p.created < $5)
+			 UNION ALL
+			 SELECT 'thread',
+					t.id,
+					t.id,
+					t.forum_slug,
+					t.author_nickname,
+					t.title,
+					t.title || ' ' || t.message,
+					ts_rank(t.search_vector, query.q),
+					t.created
+			 FROM threads AS t,
+				  query
+			 WHERE t.search_vector @@ query.q
+			   AND ($2::CITEXT IS NULL OR t.forum_slug = $2)
+			   AND ($3::CITEXT IS NULL OR t.author_nickname = $3)
+			   AND ($4::TIMESTAMPTZ IS NULL OR t.created >= $4)
+			   AND ($5::TIMESTAMPTZ IS NULL OR t.created < $5)
+		 )
+	SELECT found.type,
+		   found.id,
+		   found.thread_id,
+		   found.forum_slug,
+		   found.author_nickname,
+		   found.title,
+		   ts_headline('simple',
+					   replace(replace(replace(replace(replace(
+						   found.text, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+					   query.q, 'StartSel=<b>, StopSel=</b>, MinWords=15, MaxWords=35'),
+		   found.rank,
+		   found.created
+	FROM found,
+		 query
+	WHERE $6::REAL IS NULL
+	   OR (found.rank, found.type, found.id) < ($6::REAL, $7::TEXT, $8::BIGINT)
+	ORDER BY found.rank DESC, found.type DESC, found.id DESC
+	LIMIT $9`
@@ -0,0 +1,10 @@
+package search
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+)
+
+type UseCase interface {
+	Search(ctx context.Context, text, forum, author, since, until, cursor, limit string) (models.SearchPage, error)
+}
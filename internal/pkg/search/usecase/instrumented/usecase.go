@@ -0,0 +1,32 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/search"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const useCaseName = "search"
+
+// UseCase traces every method of wrapped usecase
+type UseCase struct {
+	useCase  search.UseCase
+	observer instrumentation.Observer
+}
+
+func NewUseCase(useCase search.UseCase, tracer trace.Tracer) UseCase {
+	return UseCase{
+		useCase:  useCase,
+		observer: instrumentation.NewObserver(useCaseName, "usecase", nil, tracer),
+	}
+}
+
+func (useCase UseCase) Search(ctx context.Context,
+	text, forum, author, since, until, cursor, limit string) (_ models.SearchPage, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "Search")
+	defer func() { done(err) }()
+	return useCase.useCase.Search(ctx, text, forum, author, since, until, cursor, limit)
+}
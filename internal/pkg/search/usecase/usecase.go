@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/search"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type UseCase struct {
+	repository search.Repository
+}
+
+func NewUseCase(repository search.Repository) UseCase {
+	return UseCase{
+		repository: repository,
+	}
+}
+
+func (useCase UseCase) Search(ctx context.Context, text, forum, author, since, until,
+	cursor, limit string) (models.SearchPage, error) {
+
+	query := search.Query{
+		Text:   strings.TrimSpace(text),
+		Forum:  forum,
+		Author: author,
+		Limit:  defaultLimit,
+	}
+	if query.Text == "" {
+		return models.SearchPage{}, models.ErrInvalid
+	}
+
+	var err error
+
+	if query.Since, err = parseTime(since); err != nil {
+		return models.SearchPage{}, models.ErrInvalid
+	}
+	if query.Until, err = parseTime(until); err != nil {
+		return models.SearchPage{}, models.ErrInvalid
+	}
+
+	if limit != "" {
+		limitInt, err := strconv.ParseInt(limit, 10, 32)
+		if err != nil || limitInt <= 0 || limitInt > maxLimit {
+			return models.SearchPage{}, models.ErrInvalid
+		}
+		query.Limit = int32(limitInt)
+	}
+
+	if cursor != "" {
+		if query.After, err = decodeCursor(cursor); err != nil {
+			return models.SearchPage{}, models.ErrInvalid
+		}
+	}
+
+	results, err := useCase.repository.Search(ctx, query)
+	if err != nil {
+		return models.SearchPage{}, errors.WithStack(err)
+	}
+
+	page := models.SearchPage{
+		Results: results,
+	}
+
+	if len(results) == int(query.Limit) {
+		last := results[len(results)-1]
+		page.NextCursor, err = encodeCursor(search.Cursor{Rank: last.Rank, Type: last.Type, ID: last.ID})
+		if err != nil {
+			return models.SearchPage{}, err
+		}
+	}
+
+	return page, nil
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+func encodeCursor(cursor search.Cursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(value string) (*search.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := new(search.Cursor)
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, err
+	}
+
+	switch cursor.Type {
+	case models.PostSearchResult, models.ThreadSearchResult:
+	default:
+		return nil, errors.Errorf("unknown cursor type %q", cursor.Type)
+	}
+
+	return cursor, nil
+}
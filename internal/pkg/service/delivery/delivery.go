@@ -22,7 +22,7 @@ func NewDelivery(useCase service.UseCase, logger logger.Logger) Delivery {
 }
 
 func (delivery Delivery) DropAllData(w http.ResponseWriter, r *http.Request) {
-	if err := delivery.useCase.DropAllData(); err != nil {
+	if err := delivery.useCase.DropAllData(r.Context()); err != nil {
 		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
 			fmt.Sprintf("%+v", err))
 	} else {
@@ -31,7 +31,7 @@ func (delivery Delivery) DropAllData(w http.ResponseWriter, r *http.Request) {
 }
 
 func (delivery Delivery) GetStatus(w http.ResponseWriter, r *http.Request) {
-	if status, err := delivery.useCase.GetStatus(); err != nil {
+	if status, err := delivery.useCase.GetStatus(r.Context()); err != nil {
 		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
 			fmt.Sprintf("%+v", err))
 	} else if data, err := json.Marshal(status); err != nil {
@@ -0,0 +1,47 @@
+package instrumented
+
+import (
+	"context"
+	serviceModels "github.com/nickeskov/db_forum/internal/pkg/models/service"
+	"github.com/nickeskov/db_forum/internal/pkg/service"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const repositoryName = "service"
+
+// Repository traces every method of wrapped repository and observes its latency
+type Repository struct {
+	repository service.Repository
+	observer   instrumentation.Observer
+}
+
+// NewRepository wraps repository, durations must have repository and method labels.
+// Durations and tracer may be nil.
+func NewRepository(repository service.Repository, durations *prometheus.HistogramVec,
+	tracer trace.Tracer) Repository {
+
+	return Repository{
+		repository: repository,
+		observer:   instrumentation.NewObserver(repositoryName, "repository", durations, tracer),
+	}
+}
+
+func (repo Repository) DropAllData(ctx context.Context) (err error) {
+	ctx, done := repo.observer.Start(ctx, "DropAllData")
+	defer func() { done(err) }()
+	return repo.repository.DropAllData(ctx)
+}
+
+func (repo Repository) GetStatus(ctx context.Context) (_ serviceModels.Status, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetStatus")
+	defer func() { done(err) }()
+	return repo.repository.GetStatus(ctx)
+}
+
+func (repo Repository) RecountCounters(ctx context.Context) (err error) {
+	ctx, done := repo.observer.Start(ctx, "RecountCounters")
+	defer func() { done(err) }()
+	return repo.repository.RecountCounters(ctx)
+}
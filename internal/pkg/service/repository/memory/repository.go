@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models/service"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+)
+
+type Repository struct {
+	db *memoryDB.DB
+}
+
+func NewRepository(db *memoryDB.DB) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) DropAllData(ctx context.Context) error {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	repo.db.Truncate()
+
+	return nil
+}
+
+func (repo Repository) GetStatus(ctx context.Context) (service.Status, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return service.Status{}, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	var posts int64
+	for _, stored := range repo.db.Posts {
+		if !stored.IsDeleted {
+			posts++
+		}
+	}
+
+	return service.Status{
+		Forum:  int64(len(repo.db.Forums)),
+		Post:   posts,
+		Thread: int32(len(repo.db.Threads)),
+		User:   int32(len(repo.db.Users)),
+	}, nil
+}
+
+func (repo Repository) RecountCounters(ctx context.Context) error {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	for _, forum := range repo.db.Forums {
+		forum.Threads = 0
+		forum.Posts = 0
+	}
+	for _, thread := range repo.db.Threads {
+		repo.db.Forums[memoryDB.CIKey(thread.Forum)].Threads++
+	}
+	for _, stored := range repo.db.Posts {
+		if !stored.IsDeleted {
+			repo.db.Forums[memoryDB.CIKey(stored.Forum)].Posts++
+		}
+	}
+
+	return nil
+}
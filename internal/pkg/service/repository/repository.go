@@ -18,17 +18,14 @@ func NewRepository(db *pgxpool.Pool) Repository {
 	}
 }
 
-func (repo Repository) DropAllData() error {
-	ctx := context.Background()
-
+func (repo Repository) DropAllData(ctx context.Context) error {
 	_, err := repo.db.Exec(ctx,
-		`TRUNCATE users, forums, threads, votes, posts, forums_users_nicknames`)
+		`TRUNCATE users, forums, threads, votes, posts, post_revisions, forums_users_nicknames, sessions, forum_roles,
+			webhooks, webhook_deliveries`)
 	return errors.WithStack(err)
 }
 
-func (repo Repository) GetStatus() (status service.Status, err error) {
-	ctx := context.Background()
-
+func (repo Repository) GetStatus(ctx context.Context) (status service.Status, err error) {
 	tx, err := repo.db.Begin(ctx)
 	if err != nil {
 		return service.Status{}, errors.WithStack(err)
@@ -46,9 +43,18 @@ func (repo Repository) GetStatus() (status service.Status, err error) {
 	if err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM threads`).Scan(&status.Thread); err != nil {
 		return service.Status{}, errors.WithStack(err)
 	}
-	if err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM posts`).Scan(&status.Post); err != nil {
+	if err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM posts WHERE NOT is_deleted`).Scan(&status.Post); err != nil {
 		return service.Status{}, errors.WithStack(err)
 	}
 
 	return status, nil
 }
+
+func (repo Repository) RecountCounters(ctx context.Context) error {
+	_, err := repo.db.Exec(ctx, `
+			UPDATE forums AS f
+			SET threads = (SELECT COUNT(*) FROM threads AS t WHERE t.forum_slug = f.slug),
+				posts   = (SELECT COUNT(*) FROM posts AS p WHERE p.forum_slug = f.slug AND NOT p.is_deleted)`,
+	)
+	return errors.WithStack(err)
+}
@@ -1,8 +1,12 @@
 package service
 
-import "github.com/nickeskov/db_forum/internal/pkg/models/service"
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models/service"
+)
 
 type UseCase interface {
-	DropAllData() error
-	GetStatus() (service.Status, error)
+	DropAllData(ctx context.Context) error
+	GetStatus(ctx context.Context) (service.Status, error)
+	RecountCounters(ctx context.Context) error
 }
@@ -0,0 +1,42 @@
+package instrumented
+
+import (
+	"context"
+	serviceModels "github.com/nickeskov/db_forum/internal/pkg/models/service"
+	"github.com/nickeskov/db_forum/internal/pkg/service"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const useCaseName = "service"
+
+// UseCase traces every method of wrapped usecase
+type UseCase struct {
+	useCase  service.UseCase
+	observer instrumentation.Observer
+}
+
+func NewUseCase(useCase service.UseCase, tracer trace.Tracer) UseCase {
+	return UseCase{
+		useCase:  useCase,
+		observer: instrumentation.NewObserver(useCaseName, "usecase", nil, tracer),
+	}
+}
+
+func (useCase UseCase) DropAllData(ctx context.Context) (err error) {
+	ctx, done := useCase.observer.Start(ctx, "DropAllData")
+	defer func() { done(err) }()
+	return useCase.useCase.DropAllData(ctx)
+}
+
+func (useCase UseCase) GetStatus(ctx context.Context) (_ serviceModels.Status, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GetStatus")
+	defer func() { done(err) }()
+	return useCase.useCase.GetStatus(ctx)
+}
+
+func (useCase UseCase) RecountCounters(ctx context.Context) (err error) {
+	ctx, done := useCase.observer.Start(ctx, "RecountCounters")
+	defer func() { done(err) }()
+	return useCase.useCase.RecountCounters(ctx)
+}
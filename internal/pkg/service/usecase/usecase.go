@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	serviceModels "github.com/nickeskov/db_forum/internal/pkg/models/service"
 	"github.com/nickeskov/db_forum/internal/pkg/service"
 )
@@ -15,10 +16,14 @@ func NewUseCase(repo service.Repository) UseCase {
 	}
 }
 
-func (useCase UseCase) DropAllData() error {
-	return useCase.repo.DropAllData()
+func (useCase UseCase) DropAllData(ctx context.Context) error {
+	return useCase.repo.DropAllData(ctx)
 }
 
-func (useCase UseCase) GetStatus() (serviceModels.Status, error) {
-	return useCase.repo.GetStatus()
+func (useCase UseCase) RecountCounters(ctx context.Context) error {
+	return useCase.repo.RecountCounters(ctx)
+}
+
+func (useCase UseCase) GetStatus(ctx context.Context) (serviceModels.Status, error) {
+	return useCase.repo.GetStatus(ctx)
 }
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gorilla/mux"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/pagination"
 	"github.com/nickeskov/db_forum/internal/pkg/thread"
 	"github.com/nickeskov/db_forum/internal/pkg/utils"
 	httpUtils "github.com/nickeskov/db_forum/pkg/http"
@@ -15,12 +17,18 @@ import (
 
 type Delivery struct {
 	useCase thread.UseCase
+	guard   auth.Guard
+	signer  pagination.Signer
 	utils   httpUtils.Utils
 }
 
-func NewDelivery(useCase thread.UseCase, logger logger.Logger) Delivery {
+func NewDelivery(useCase thread.UseCase, guard auth.Guard, signer pagination.Signer,
+	logger logger.Logger) Delivery {
+
 	return Delivery{
 		useCase: useCase,
+		guard:   guard,
+		signer:  signer,
 		utils:   httpUtils.NewDeliveryUtils(logger),
 	}
 }
@@ -43,12 +51,17 @@ func (delivery Delivery) CreateThread(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := delivery.guard.CheckCaller(r.Context(), newThread.Author); err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return
+	}
+
 	newThread.Forum = mux.Vars(r)["slug"]
 
-	createdThread, err := delivery.useCase.Create(newThread)
+	createdThread, err := delivery.useCase.Create(r.Context(), newThread)
 	switch {
 	case errors.Is(err, models.ErrConflict):
-		existingThread, err := delivery.useCase.GetBySlugOrID(newThread.Slug)
+		existingThread, err := delivery.useCase.GetBySlugOrID(r.Context(), newThread.Slug)
 		if err != nil {
 			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
 				fmt.Sprintf("%+v", err))
@@ -68,6 +81,10 @@ func (delivery Delivery) CreateThread(w http.ResponseWriter, r *http.Request) {
 			fmt.Sprintf("cannot create thread (author=%s or forum=%s does not exist)",
 				newThread.Author, newThread.Forum))
 
+	case errors.Is(err, models.ErrAccessDenied):
+		delivery.utils.WriteResponseError(w, r, http.StatusForbidden,
+			fmt.Sprintf("author=%s is banned in forum=%s", newThread.Author, newThread.Forum))
+
 	case err != nil:
 		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
 			fmt.Sprintf("%+v", err))
@@ -88,7 +105,21 @@ func (delivery Delivery) GetThreadsByForumSlug(w http.ResponseWriter, r *http.Re
 
 	since, desc, limit := utils.ParseSinceDescLimit(r.URL.Query())
 
-	threads, err := delivery.useCase.GetThreadsByForumSlug(forumSlug, since, desc, limit)
+	list := pagination.List("threads", forumSlug, desc)
+	cursor, err := delivery.signer.FromRequest(r, list)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, "invalid cursor")
+		return
+	}
+
+	var threads models.Threads
+	if cursor != nil {
+		after := models.Thread{ID: int32(cursor.ID), Created: cursor.Created, IsPinned: cursor.Pinned}
+		threads, err = delivery.useCase.GetThreadsByForumSlugAfter(r.Context(), forumSlug, after, desc, limit)
+	} else {
+		threads, err = delivery.useCase.GetThreadsByForumSlug(r.Context(), forumSlug, since, desc, limit)
+	}
+
 	switch {
 	case errors.Is(err, models.ErrDoesNotExist):
 		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
@@ -102,7 +133,27 @@ func (delivery Delivery) GetThreadsByForumSlug(w http.ResponseWriter, r *http.Re
 			fmt.Sprintf("%+v", err))
 
 	default:
-		data, err := json.Marshal(threads)
+		page := models.ThreadsPage{Items: threads}
+		if pagination.IsFull(r, len(threads)) {
+			last := threads[len(threads)-1]
+			page.NextCursor, err = delivery.signer.SetNextLink(w, r, pagination.Cursor{
+				List:    list,
+				ID:      int64(last.ID),
+				Created: last.Created,
+				Pinned:  last.IsPinned,
+			})
+			if err != nil {
+				delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+
+		var data []byte
+		if pagination.Requested(r) {
+			data, err = json.Marshal(page)
+		} else {
+			data, err = json.Marshal(threads)
+		}
 		if err != nil {
 			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
 			return
@@ -115,7 +166,7 @@ func (delivery Delivery) GetThreadsByForumSlug(w http.ResponseWriter, r *http.Re
 func (delivery Delivery) GetThreadBySlugOrID(w http.ResponseWriter, r *http.Request) {
 	slugOrID := mux.Vars(r)["slug_or_id"]
 
-	threads, err := delivery.useCase.GetBySlugOrID(slugOrID)
+	threads, err := delivery.useCase.GetBySlugOrID(r.Context(), slugOrID)
 	switch {
 	case errors.Is(err, models.ErrDoesNotExist):
 		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
@@ -142,16 +193,30 @@ func (delivery Delivery) UpdateThreadBySlugOrID(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	var threadUpdate models.Thread
+	var threadUpdate models.ThreadUpdate
 
 	if err := json.Unmarshal(data, &threadUpdate); err != nil {
 		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	if err := threadUpdate.Validate(); err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest,
+			fmt.Sprintf("invalid thread state=%s", threadUpdate.State))
+		return
+	}
+
 	slugOrID := mux.Vars(r)["slug_or_id"]
 
-	updatedThread, err := delivery.useCase.UpdateBySlugOrID(slugOrID, threadUpdate)
+	action := auth.EditAction
+	if threadUpdate.IsModeration() {
+		action = auth.ModerateAction
+	}
+	if !delivery.checkThreadEditor(w, r, slugOrID, action) {
+		return
+	}
+
+	updatedThread, err := delivery.useCase.UpdateBySlugOrID(r.Context(), slugOrID, threadUpdate)
 	switch {
 	case errors.Is(err, models.ErrDoesNotExist):
 		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
@@ -185,15 +250,24 @@ func (delivery Delivery) VoteThreadBySlugOrID(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if err := delivery.guard.CheckCaller(r.Context(), vote.Nickname); err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return
+	}
+
 	slugOrID := mux.Vars(r)["slug_or_id"]
 
-	updatedThread, err := delivery.useCase.VoteBySlugOrID(slugOrID, vote)
+	updatedThread, err := delivery.useCase.VoteBySlugOrID(r.Context(), slugOrID, vote)
 	switch {
 	case errors.Is(err, models.ErrDoesNotExist):
 		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
 			fmt.Sprintf("thread with slug_or_id=%s or author=%s does not exits",
 				slugOrID, vote.Nickname))
 
+	case errors.Is(err, models.ErrAccessDenied):
+		delivery.utils.WriteResponseError(w, r, http.StatusForbidden,
+			fmt.Sprintf("thread with slug_or_id=%s is archived", slugOrID))
+
 	case err != nil:
 		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
 			fmt.Sprintf("%+v", err))
@@ -208,3 +282,127 @@ func (delivery Delivery) VoteThreadBySlugOrID(w http.ResponseWriter, r *http.Req
 		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
 	}
 }
+
+func (delivery Delivery) MoveThreadBySlugOrID(w http.ResponseWriter, r *http.Request) {
+	data, err := delivery.utils.ReadAllDataFromBody(w, r)
+	if err != nil {
+		return
+	}
+
+	var threadMove models.ThreadMove
+
+	if err := json.Unmarshal(data, &threadMove); err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	slugOrID := mux.Vars(r)["slug_or_id"]
+
+	if !delivery.checkThreadEditor(w, r, slugOrID, auth.ModerateAction) {
+		return
+	}
+	if err := delivery.guard.CheckForumModerator(r.Context(), threadMove.Forum); err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return
+	}
+
+	movedThread, err := delivery.useCase.MoveBySlugOrID(r.Context(), slugOrID, threadMove.Forum)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("thread with slug_or_id=%s does not exits", slugOrID))
+
+	case errors.Is(err, models.ErrBadForeign):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("forum with slug=%s does not exits", threadMove.Forum))
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(movedThread)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+func (delivery Delivery) MergeThreadBySlugOrID(w http.ResponseWriter, r *http.Request) {
+	data, err := delivery.utils.ReadAllDataFromBody(w, r)
+	if err != nil {
+		return
+	}
+
+	var threadMerge models.ThreadMerge
+
+	if err := json.Unmarshal(data, &threadMerge); err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	slugOrID := mux.Vars(r)["slug_or_id"]
+
+	if !delivery.checkThreadEditor(w, r, slugOrID, auth.ModerateAction) ||
+		!delivery.checkThreadEditor(w, r, threadMerge.Thread, auth.ModerateAction) {
+		return
+	}
+
+	mergedThread, err := delivery.useCase.MergeBySlugOrID(r.Context(), slugOrID, threadMerge.Thread)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("thread with slug_or_id=%s or slug_or_id=%s does not exits",
+				slugOrID, threadMerge.Thread))
+
+	case errors.Is(err, models.ErrConflict):
+		delivery.utils.WriteResponseError(w, r, http.StatusConflict,
+			fmt.Sprintf("thread with slug_or_id=%s can't be merged into itself", slugOrID))
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(mergedThread)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+// checkThreadEditor writes error response and returns false if caller is not author of thread
+// or moderator of its forum, moderation actions are allowed only to moderators
+func (delivery Delivery) checkThreadEditor(w http.ResponseWriter, r *http.Request,
+	slugOrID string, action auth.ContentAction) bool {
+	if !delivery.guard.ChecksAction(action) {
+		return true
+	}
+
+	storedThread, err := delivery.useCase.GetBySlugOrID(r.Context(), slugOrID)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("thread with slug_or_id=%s does not exits", slugOrID))
+		return false
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+		return false
+	}
+
+	err = delivery.guard.CheckContentAction(r.Context(), action, storedThread.Forum, storedThread.Author)
+	if err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return false
+	}
+
+	return true
+}
@@ -1,20 +1,34 @@
 package thread
 
 import (
+	"context"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
 	"time"
 )
 
 type Repository interface {
-	GetByID(id int32) (models.Thread, error)
-	GetBySlug(slug string) (models.Thread, error)
+	GetByID(ctx context.Context, id int32) (models.Thread, error)
+	GetBySlug(ctx context.Context, slug string) (models.Thread, error)
 
-	UpdateByID(thread models.Thread) (models.Thread, error)
-	UpdateBySlug(thread models.Thread) (models.Thread, error)
+	UpdateByID(ctx context.Context, id int32, update models.ThreadUpdate) (models.Thread, error)
+	UpdateBySlug(ctx context.Context, slug string, update models.ThreadUpdate) (models.Thread, error)
 
-	VoteByID(id int32, vote models.Vote) (models.Thread, error)
-	VoteBySlug(slug string, vote models.Vote) (models.Thread, error)
+	// VoteByID and VoteBySlug return ErrAccessDenied for archived thread
+	VoteByID(ctx context.Context, id int32, vote models.Vote) (models.Thread, error)
+	VoteBySlug(ctx context.Context, slug string, vote models.Vote) (models.Thread, error)
 
-	Create(thread models.Thread) (models.Thread, error)
-	GetThreadsByForumSlug(forumSlug string, since *time.Time, desc bool, limit int32) (models.Threads, error)
+	// MoveByID moves thread with its posts to other forum, ErrBadForeign is returned for not existing forum
+	MoveByID(ctx context.Context, id int32, forumSlug string) (models.Thread, error)
+	// MergeByID moves posts of source thread to target thread keeping posts tree and deletes source thread,
+	// ErrConflict is returned if source and target are the same thread
+	MergeByID(ctx context.Context, sourceID, targetID int32) (models.Thread, error)
+
+	Create(ctx context.Context, thread models.Thread) (models.Thread, error)
+	// GetThreadsByForumSlug lists pinned threads first
+	GetThreadsByForumSlug(ctx context.Context, forumSlug string, since *time.Time,
+		desc bool, limit int32) (models.Threads, error)
+	// GetThreadsByForumSlugAfter lists threads following after thread in the same order,
+	// id breaks ties of threads with the same created time
+	GetThreadsByForumSlugAfter(ctx context.Context, forumSlug string, after models.Thread,
+		desc bool, limit int32) (models.Threads, error)
 }
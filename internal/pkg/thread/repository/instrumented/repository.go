@@ -0,0 +1,104 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/thread"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const repositoryName = "thread"
+
+// Repository traces every method of wrapped repository and observes its latency
+type Repository struct {
+	repository thread.Repository
+	observer   instrumentation.Observer
+}
+
+// NewRepository wraps repository, durations must have repository and method labels.
+// Durations and tracer may be nil.
+func NewRepository(repository thread.Repository, durations *prometheus.HistogramVec,
+	tracer trace.Tracer) Repository {
+
+	return Repository{
+		repository: repository,
+		observer:   instrumentation.NewObserver(repositoryName, "repository", durations, tracer),
+	}
+}
+
+func (repo Repository) GetByID(ctx context.Context, id int32) (_ models.Thread, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetByID")
+	defer func() { done(err) }()
+	return repo.repository.GetByID(ctx, id)
+}
+
+func (repo Repository) GetBySlug(ctx context.Context, slug string) (_ models.Thread, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetBySlug")
+	defer func() { done(err) }()
+	return repo.repository.GetBySlug(ctx, slug)
+}
+
+func (repo Repository) UpdateByID(ctx context.Context,
+	id int32, update models.ThreadUpdate) (_ models.Thread, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "UpdateByID")
+	defer func() { done(err) }()
+	return repo.repository.UpdateByID(ctx, id, update)
+}
+
+func (repo Repository) UpdateBySlug(ctx context.Context,
+	slug string, update models.ThreadUpdate) (_ models.Thread, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "UpdateBySlug")
+	defer func() { done(err) }()
+	return repo.repository.UpdateBySlug(ctx, slug, update)
+}
+
+func (repo Repository) VoteByID(ctx context.Context, id int32, vote models.Vote) (_ models.Thread, err error) {
+	ctx, done := repo.observer.Start(ctx, "VoteByID")
+	defer func() { done(err) }()
+	return repo.repository.VoteByID(ctx, id, vote)
+}
+
+func (repo Repository) VoteBySlug(ctx context.Context, slug string, vote models.Vote) (_ models.Thread, err error) {
+	ctx, done := repo.observer.Start(ctx, "VoteBySlug")
+	defer func() { done(err) }()
+	return repo.repository.VoteBySlug(ctx, slug, vote)
+}
+
+func (repo Repository) MoveByID(ctx context.Context, id int32, forumSlug string) (_ models.Thread, err error) {
+	ctx, done := repo.observer.Start(ctx, "MoveByID")
+	defer func() { done(err) }()
+	return repo.repository.MoveByID(ctx, id, forumSlug)
+}
+
+func (repo Repository) MergeByID(ctx context.Context, sourceID, targetID int32) (_ models.Thread, err error) {
+	ctx, done := repo.observer.Start(ctx, "MergeByID")
+	defer func() { done(err) }()
+	return repo.repository.MergeByID(ctx, sourceID, targetID)
+}
+
+func (repo Repository) Create(ctx context.Context, thread models.Thread) (_ models.Thread, err error) {
+	ctx, done := repo.observer.Start(ctx, "Create")
+	defer func() { done(err) }()
+	return repo.repository.Create(ctx, thread)
+}
+
+func (repo Repository) GetThreadsByForumSlug(ctx context.Context,
+	forumSlug string, since *time.Time, desc bool, limit int32) (_ models.Threads, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetThreadsByForumSlug")
+	defer func() { done(err) }()
+	return repo.repository.GetThreadsByForumSlug(ctx, forumSlug, since, desc, limit)
+}
+
+func (repo Repository) GetThreadsByForumSlugAfter(ctx context.Context,
+	forumSlug string, after models.Thread, desc bool, limit int32) (_ models.Threads, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetThreadsByForumSlugAfter")
+	defer func() { done(err) }()
+	return repo.repository.GetThreadsByForumSlugAfter(ctx, forumSlug, after, desc, limit)
+}
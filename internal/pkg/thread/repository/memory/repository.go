@@ -0,0 +1,317 @@
+package memory
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+	"sort"
+	"time"
+)
+
+type Repository struct {
+	db *memoryDB.DB
+}
+
+func NewRepository(db *memoryDB.DB) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) GetByID(ctx context.Context, id int32) (models.Thread, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	stored, ok := repo.db.Threads[id]
+	if !ok {
+		return models.Thread{}, models.ErrDoesNotExist
+	}
+
+	return *stored, nil
+}
+
+func (repo Repository) GetBySlug(ctx context.Context, slug string) (models.Thread, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	stored, ok := repo.db.ThreadsBySlug[memoryDB.CIKey(slug)]
+	if !ok {
+		return models.Thread{}, models.ErrDoesNotExist
+	}
+
+	return *stored, nil
+}
+
+func (repo Repository) UpdateByID(ctx context.Context, id int32,
+	update models.ThreadUpdate) (models.Thread, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	return updateThread(repo.db.Threads[id], update)
+}
+
+func (repo Repository) UpdateBySlug(ctx context.Context, slug string,
+	update models.ThreadUpdate) (models.Thread, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	return updateThread(repo.db.ThreadsBySlug[memoryDB.CIKey(slug)], update)
+}
+
+func (repo Repository) VoteByID(ctx context.Context, id int32, vote models.Vote) (models.Thread, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	return repo.vote(repo.db.Threads[id], vote)
+}
+
+func (repo Repository) VoteBySlug(ctx context.Context, slug string, vote models.Vote) (models.Thread, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	return repo.vote(repo.db.ThreadsBySlug[memoryDB.CIKey(slug)], vote)
+}
+
+func (repo Repository) MoveByID(ctx context.Context, id int32, forumSlug string) (models.Thread, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	stored, ok := repo.db.Threads[id]
+	if !ok {
+		return models.Thread{}, models.ErrDoesNotExist
+	}
+	forum, ok := repo.db.Forums[memoryDB.CIKey(forumSlug)]
+	if !ok {
+		return models.Thread{}, models.ErrBadForeign
+	}
+
+	if memoryDB.CIKey(stored.Forum) != memoryDB.CIKey(forum.Slug) {
+		repo.db.MoveThread(stored, forum)
+	}
+
+	return *stored, nil
+}
+
+func (repo Repository) MergeByID(ctx context.Context, sourceID, targetID int32) (models.Thread, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	source, ok := repo.db.Threads[sourceID]
+	if !ok {
+		return models.Thread{}, models.ErrDoesNotExist
+	}
+	target, ok := repo.db.Threads[targetID]
+	if !ok {
+		return models.Thread{}, models.ErrDoesNotExist
+	}
+	if sourceID == targetID {
+		return models.Thread{}, models.ErrConflict
+	}
+
+	repo.db.MergeThreads(source, target)
+
+	return *target, nil
+}
+
+func (repo Repository) Create(ctx context.Context, thread models.Thread) (models.Thread, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Thread{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	author, ok := repo.db.UsersByNickname[memoryDB.CIKey(thread.Author)]
+	if !ok {
+		return models.Thread{}, models.ErrBadForeign
+	}
+	forum, ok := repo.db.Forums[memoryDB.CIKey(thread.Forum)]
+	if !ok {
+		return models.Thread{}, models.ErrBadForeign
+	}
+	if _, ok := repo.db.ThreadsBySlug[memoryDB.CIKey(thread.Slug)]; ok && thread.Slug != "" {
+		return models.Thread{}, models.ErrConflict
+	}
+
+	thread.Author = author.Nickname
+	thread.Forum = forum.Slug
+	thread.Votes = 0
+	thread.State = models.OpenThreadState
+	thread.IsPinned = false
+
+	thread = repo.db.InsertThread(thread)
+
+	if err := repo.db.InsertWebhookEvents(thread.Forum, models.ThreadCreatedWebhookEvent, thread); err != nil {
+		return models.Thread{}, err
+	}
+
+	return thread, nil
+}
+
+func (repo Repository) GetThreadsByForumSlug(ctx context.Context, forumSlug string,
+	since *time.Time, desc bool, limit int32) (models.Threads, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, models.ErrInvalid
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	forumKey := memoryDB.CIKey(forumSlug)
+	if _, ok := repo.db.Forums[forumKey]; !ok {
+		return nil, models.ErrDoesNotExist
+	}
+
+	threads := make(models.Threads, 0)
+	for _, stored := range repo.db.Threads {
+		if memoryDB.CIKey(stored.Forum) != forumKey {
+			continue
+		}
+		if since != nil && (desc && stored.Created.After(*since) || !desc && stored.Created.Before(*since)) {
+			continue
+		}
+		threads = append(threads, *stored)
+	}
+
+	return sortThreads(threads, desc, limit), nil
+}
+
+func (repo Repository) GetThreadsByForumSlugAfter(ctx context.Context, forumSlug string,
+	after models.Thread, desc bool, limit int32) (models.Threads, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, models.ErrInvalid
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	forumKey := memoryDB.CIKey(forumSlug)
+	if _, ok := repo.db.Forums[forumKey]; !ok {
+		return nil, models.ErrDoesNotExist
+	}
+
+	threads := make(models.Threads, 0)
+	for _, stored := range repo.db.Threads {
+		if memoryDB.CIKey(stored.Forum) != forumKey || !threadLess(after, *stored, desc) {
+			continue
+		}
+		threads = append(threads, *stored)
+	}
+
+	return sortThreads(threads, desc, limit), nil
+}
+
+func (repo Repository) vote(stored *models.Thread, vote models.Vote) (models.Thread, error) {
+	if stored == nil {
+		return models.Thread{}, models.ErrDoesNotExist // thread does not exist
+	}
+	if stored.State == models.ArchivedThreadState {
+		return models.Thread{}, models.ErrAccessDenied
+	}
+	if _, ok := repo.db.UsersByNickname[memoryDB.CIKey(vote.Nickname)]; !ok {
+		return models.Thread{}, models.ErrDoesNotExist // author does not exist
+	}
+
+	repo.db.UpsertVote(stored.ID, vote)
+
+	err := repo.db.InsertWebhookEvents(stored.Forum, models.ThreadVotedWebhookEvent, models.ThreadVotes{
+		Thread:   stored.ID,
+		Votes:    stored.Votes,
+		Nickname: vote.Nickname,
+		Voice:    vote.Voice,
+	})
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	return *stored, nil
+}
+
+func updateThread(stored *models.Thread, update models.ThreadUpdate) (models.Thread, error) {
+	if stored == nil {
+		return models.Thread{}, models.ErrDoesNotExist
+	}
+
+	if update.Title != "" {
+		stored.Title = update.Title
+	}
+	if update.Message != "" {
+		stored.Message = update.Message
+	}
+	if update.State != "" {
+		stored.State = update.State
+	}
+	if update.Pinned != nil {
+		stored.IsPinned = *update.Pinned
+	}
+
+	return *stored, nil
+}
+
+func sortThreads(threads models.Threads, desc bool, limit int32) models.Threads {
+	sort.Slice(threads, func(i, j int) bool {
+		return threadLess(threads[i], threads[j], desc)
+	})
+
+	if int(limit) < len(threads) {
+		threads = threads[:limit]
+	}
+
+	return threads
+}
+
+// threadLess orders threads like ORDER BY is_pinned DESC, created, id with created and id reversed for desc
+func threadLess(left, right models.Thread, desc bool) bool {
+	if left.IsPinned != right.IsPinned {
+		return left.IsPinned
+	}
+	if desc {
+		left, right = right, left
+	}
+	if !left.Created.Equal(right.Created) {
+		return left.Created.Before(right.Created)
+	}
+	return left.ID < right.ID
+}
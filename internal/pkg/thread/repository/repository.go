@@ -7,9 +7,11 @@ import (
 	"github.com/nickeskov/db_forum/internal/pkg/forum"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
 	"github.com/nickeskov/db_forum/internal/pkg/utils/database/driver/pgx/codes"
+	webhookRepository "github.com/nickeskov/db_forum/internal/pkg/webhook/repository"
 	sqlHelpers "github.com/nickeskov/db_forum/pkg/sql"
 	pgx4Helpers "github.com/nickeskov/db_forum/pkg/sql/pgx/v4"
 	"github.com/pkg/errors"
+	"strings"
 	"time"
 )
 
@@ -25,16 +27,16 @@ func NewRepository(db *pgxpool.Pool, forumRepo forum.Repository) Repository {
 	}
 }
 
-func (repo Repository) GetByID(id int32) (models.Thread, error) {
-	return getByID(repo.db, id)
+func (repo Repository) GetByID(ctx context.Context, id int32) (models.Thread, error) {
+	return getByID(ctx, repo.db, id)
 }
 
-func (repo Repository) GetBySlug(slug string) (models.Thread, error) {
-	return getBySlug(repo.db, slug)
+func (repo Repository) GetBySlug(ctx context.Context, slug string) (models.Thread, error) {
+	return getBySlug(ctx, repo.db, slug)
 }
 
-func (repo Repository) VoteByID(id int32, vote models.Vote) (thread models.Thread, err error) {
-	ctx := context.Background()
+func (repo Repository) VoteByID(ctx context.Context, id int32,
+	vote models.Vote) (thread models.Thread, err error) {
 
 	tx, err := repo.db.Begin(ctx)
 	if err != nil {
@@ -44,35 +46,24 @@ func (repo Repository) VoteByID(id int32, vote models.Vote) (thread models.Threa
 		err = pgx4Helpers.FinishPgx4Transaction(ctx, tx, err)
 	}()
 
-	_, err = tx.Exec(ctx, `
-			INSERT INTO votes (thread_id, author_nickname, voice)
-			VALUES ($1, $2, $3)
-			ON CONFLICT (thread_id, author_nickname) DO UPDATE SET voice = $3`,
-		id,
-		vote.Nickname,
-		vote.Voice,
-	)
-
-	if pgxErr := codes.ExtractPgx4ErrorCode(err); pgxErr != nil {
-		switch pgxErr.Error() {
-		case codes.ErrCodeForeignKey:
-			return models.Thread{}, models.ErrDoesNotExist // author or thread does not exist
-		default:
-			return models.Thread{}, errors.Wrapf(err,
-				"some error while voting threadID=%d, vote=%+v", id, vote)
-		}
+	if err = insertVote(ctx, tx, id, vote); err != nil {
+		return models.Thread{}, err
 	}
 
-	if thread, err = getByID(tx, id); err != nil {
+	if thread, err = getByID(ctx, tx, id); err != nil {
 		return models.Thread{}, errors.Wrapf(err,
 			"some error while voting threadID=%d, vote=%+v", id, vote)
 	}
 
-	return thread, errors.WithStack(err)
+	if err = insertVoteEvent(ctx, tx, thread, vote); err != nil {
+		return models.Thread{}, err
+	}
+
+	return thread, nil
 }
 
-func (repo Repository) VoteBySlug(slug string, vote models.Vote) (thread models.Thread, err error) {
-	ctx := context.Background()
+func (repo Repository) VoteBySlug(ctx context.Context, slug string,
+	vote models.Vote) (thread models.Thread, err error) {
 
 	tx, err := repo.db.Begin(ctx)
 	if err != nil {
@@ -82,7 +73,7 @@ func (repo Repository) VoteBySlug(slug string, vote models.Vote) (thread models.
 		err = pgx4Helpers.FinishPgx4Transaction(ctx, tx, err)
 	}()
 
-	thread, err = getBySlug(tx, slug) // get thread model for id
+	thread, err = getBySlug(ctx, tx, slug) // get thread model for id
 	switch {
 	case err == models.ErrDoesNotExist:
 		return models.Thread{}, models.ErrDoesNotExist // thread does not exist
@@ -91,23 +82,8 @@ func (repo Repository) VoteBySlug(slug string, vote models.Vote) (thread models.
 			"some error while voting threadSlug=%s, vote=%+v", slug, vote)
 	}
 
-	_, err = tx.Exec(ctx, `
-			INSERT INTO votes (thread_id, author_nickname, voice)
-			VALUES ($1, $2, $3)
-			ON CONFLICT (thread_id, author_nickname) DO UPDATE SET voice = $3`,
-		thread.ID,
-		vote.Nickname,
-		vote.Voice,
-	)
-
-	if pgxErr := codes.ExtractPgx4ErrorCode(err); pgxErr != nil {
-		switch pgxErr.Error() {
-		case codes.ErrCodeForeignKey:
-			return models.Thread{}, models.ErrDoesNotExist // author does not exist
-		default:
-			return models.Thread{}, errors.Wrapf(err,
-				"some error while voting threadSlug=%s, vote=%+v", slug, vote)
-		}
+	if err = insertVote(ctx, tx, thread.ID, vote); err != nil {
+		return models.Thread{}, err
 	}
 
 	err = tx.QueryRow(ctx,
@@ -121,23 +97,160 @@ func (repo Repository) VoteBySlug(slug string, vote models.Vote) (thread models.
 			"some error while voting threadSlug=%s, vote=%+v", slug, vote)
 	}
 
-	return thread, errors.WithStack(err)
+	if err = insertVoteEvent(ctx, tx, thread, vote); err != nil {
+		return models.Thread{}, err
+	}
+
+	return thread, nil
 }
 
-func (repo Repository) Create(thread models.Thread) (models.Thread, error) {
-	ctx := context.Background()
+func (repo Repository) MoveByID(ctx context.Context, id int32,
+	forumSlug string) (thread models.Thread, err error) {
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return models.Thread{}, errors.WithStack(err)
+	}
+	defer func() {
+		err = pgx4Helpers.FinishPgx4Transaction(ctx, tx, err)
+	}()
+
+	if err = lockThreads(ctx, tx, id); err != nil {
+		return models.Thread{}, err
+	}
+	if thread, err = getByID(ctx, tx, id); err != nil {
+		return models.Thread{}, err
+	}
+
+	var newForumSlug string
+	err = tx.QueryRow(ctx, `SELECT slug FROM forums WHERE slug = $1`, forumSlug).Scan(&newForumSlug)
+	switch {
+	case err == pgx.ErrNoRows:
+		return models.Thread{}, models.ErrBadForeign
+	case err != nil:
+		return models.Thread{}, errors.Wrapf(err,
+			"some error while moving threadID=%d to forumSlug=%s", id, forumSlug)
+	}
+
+	if strings.EqualFold(thread.Forum, newForumSlug) {
+		return thread, nil
+	}
+
+	oldForumSlug := thread.Forum
+
+	if _, err = tx.Exec(ctx, `UPDATE threads SET forum_slug = $2 WHERE id = $1`, id, newForumSlug); err != nil {
+		return models.Thread{}, errors.Wrapf(err,
+			"some error while moving threadID=%d to forumSlug=%s", id, forumSlug)
+	}
+
+	movedPosts, err := movePosts(ctx, tx, id, id, newForumSlug)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	if err = updateForumCounters(ctx, tx, oldForumSlug, -1, -movedPosts); err != nil {
+		return models.Thread{}, err
+	}
+	if err = updateForumCounters(ctx, tx, newForumSlug, 1, movedPosts); err != nil {
+		return models.Thread{}, err
+	}
+
+	authors, err := threadAuthors(ctx, tx, id)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	if err = addForumUsers(ctx, tx, newForumSlug, authors); err != nil {
+		return models.Thread{}, err
+	}
+	if err = removeInactiveForumUsers(ctx, tx, oldForumSlug, authors); err != nil {
+		return models.Thread{}, err
+	}
+
+	thread.Forum = newForumSlug
+
+	return thread, nil
+}
+
+func (repo Repository) MergeByID(ctx context.Context,
+	sourceID, targetID int32) (thread models.Thread, err error) {
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return models.Thread{}, errors.WithStack(err)
+	}
+	defer func() {
+		err = pgx4Helpers.FinishPgx4Transaction(ctx, tx, err)
+	}()
+
+	if err = lockThreads(ctx, tx, sourceID, targetID); err != nil {
+		return models.Thread{}, err
+	}
+	if sourceID == targetID {
+		return models.Thread{}, models.ErrConflict
+	}
+
+	source, err := getByID(ctx, tx, sourceID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	target, err := getByID(ctx, tx, targetID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	authors, err := threadAuthors(ctx, tx, sourceID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	// posts paths consist of global post ids, so they remain valid in target thread
+	movedPosts, err := movePosts(ctx, tx, sourceID, targetID, target.Forum)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM threads WHERE id = $1`, sourceID); err != nil {
+		return models.Thread{}, errors.Wrapf(err,
+			"some error while merging threadID=%d into threadID=%d", sourceID, targetID)
+	}
+
+	if err = updateForumCounters(ctx, tx, source.Forum, -1, -movedPosts); err != nil {
+		return models.Thread{}, err
+	}
+	if err = updateForumCounters(ctx, tx, target.Forum, 0, movedPosts); err != nil {
+		return models.Thread{}, err
+	}
 
+	if err = addForumUsers(ctx, tx, target.Forum, authors[1:]); err != nil {
+		return models.Thread{}, err
+	}
+	if err = removeInactiveForumUsers(ctx, tx, source.Forum, authors); err != nil {
+		return models.Thread{}, err
+	}
+
+	return target, nil
+}
+
+func (repo Repository) Create(ctx context.Context, thread models.Thread) (_ models.Thread, err error) {
 	var threadSlug *string
 	if thread.Slug != "" {
 		threadSlug = &thread.Slug
 	}
 
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return models.Thread{}, errors.WithStack(err)
+	}
+	defer func() {
+		err = pgx4Helpers.FinishPgx4Transaction(ctx, tx, err)
+	}()
+
 	// TODO(nickeskov): maybe remove nickname select, because tests passing without it
-	err := repo.db.QueryRow(ctx, `
+	err = tx.QueryRow(ctx, `
 			INSERT INTO threads (slug, author_nickname, title, message, created, forum_slug)
 			VALUES ($1, (SELECT nickname FROM users WHERE nickname = $2), $3, $4, $5,
 					(SELECT slug FROM forums WHERE slug = $6))
-			RETURNING id, author_nickname, forum_slug, created`,
+			RETURNING id, author_nickname, forum_slug, created, state, is_pinned`,
 		threadSlug,
 		thread.Author,
 		thread.Title,
@@ -149,6 +262,8 @@ func (repo Repository) Create(thread models.Thread) (models.Thread, error) {
 		&thread.Author,
 		&thread.Forum,
 		&thread.Created,
+		&thread.State,
+		&thread.IsPinned,
 	)
 
 	if pgxErr := codes.ExtractPgx4ErrorCode(err); pgxErr != nil {
@@ -162,22 +277,36 @@ func (repo Repository) Create(thread models.Thread) (models.Thread, error) {
 				"some error while creating thread=%+v", thread)
 		}
 	}
+	if err != nil {
+		return models.Thread{}, errors.WithStack(err)
+	}
 
-	return thread, errors.WithStack(err)
+	if err = webhookRepository.InsertEvents(ctx, tx, thread.Forum,
+		models.ThreadCreatedWebhookEvent, thread); err != nil {
+		return models.Thread{}, err
+	}
+
+	return thread, nil
 }
 
-func (repo Repository) UpdateByID(thread models.Thread) (models.Thread, error) {
-	ctx := context.Background()
+func (repo Repository) UpdateByID(ctx context.Context, id int32,
+	update models.ThreadUpdate) (models.Thread, error) {
+
+	var thread models.Thread
 
 	row := repo.db.QueryRow(ctx, `
 			UPDATE threads
-			SET title   = COALESCE(NULLIF($2, ''), title),
-				message = COALESCE(NULLIF($3, ''), message)
+			SET title     = COALESCE(NULLIF($2, ''), title),
+				message   = COALESCE(NULLIF($3, ''), message),
+				state     = COALESCE(NULLIF($4, ''), state),
+				is_pinned = COALESCE($5, is_pinned)
 			WHERE id = $1
-			RETURNING id, slug, forum_slug, author_nickname, title, message, votes, created`,
-		thread.ID,
-		thread.Title,
-		thread.Message,
+			RETURNING id, slug, forum_slug, author_nickname, title, message, votes, created, state, is_pinned`,
+		id,
+		update.Title,
+		update.Message,
+		update.State,
+		update.Pinned,
 	)
 
 	err := scanThread(row, &thread)
@@ -187,24 +316,30 @@ func (repo Repository) UpdateByID(thread models.Thread) (models.Thread, error) {
 		return models.Thread{}, models.ErrDoesNotExist
 	case err != nil:
 		return models.Thread{}, errors.Wrapf(err,
-			"some error in thread repo in UpdateByID with thread=%+v", thread)
+			"some error in thread repo in UpdateByID with id=%d, update=%+v", id, update)
 	}
 
 	return thread, nil
 }
 
-func (repo Repository) UpdateBySlug(thread models.Thread) (models.Thread, error) {
-	ctx := context.Background()
+func (repo Repository) UpdateBySlug(ctx context.Context, slug string,
+	update models.ThreadUpdate) (models.Thread, error) {
+
+	var thread models.Thread
 
 	row := repo.db.QueryRow(ctx, `
 			UPDATE threads
-			SET title   = COALESCE(NULLIF($2, ''), title),
-				message = COALESCE(NULLIF($3, ''), message)
+			SET title     = COALESCE(NULLIF($2, ''), title),
+				message   = COALESCE(NULLIF($3, ''), message),
+				state     = COALESCE(NULLIF($4, ''), state),
+				is_pinned = COALESCE($5, is_pinned)
 			WHERE slug = $1
-			RETURNING id, slug, forum_slug, author_nickname, title, message, votes, created`,
-		thread.Slug,
-		thread.Title,
-		thread.Message,
+			RETURNING id, slug, forum_slug, author_nickname, title, message, votes, created, state, is_pinned`,
+		slug,
+		update.Title,
+		update.Message,
+		update.State,
+		update.Pinned,
 	)
 
 	err := scanThread(row, &thread)
@@ -214,16 +349,14 @@ func (repo Repository) UpdateBySlug(thread models.Thread) (models.Thread, error)
 		return models.Thread{}, models.ErrDoesNotExist
 	case err != nil:
 		return models.Thread{}, errors.Wrapf(err,
-			"some error in thread repo in UpdateBySlug with thread=%+v", thread)
+			"some error in thread repo in UpdateBySlug with slug=%s, update=%+v", slug, update)
 	}
 
 	return thread, nil
 }
 
-func (repo Repository) GetThreadsByForumSlug(forumSlug string, since *time.Time, desc bool,
-	limit int32) (models.Threads, error) {
-
-	ctx := context.Background()
+func (repo Repository) GetThreadsByForumSlug(ctx context.Context, forumSlug string,
+	since *time.Time, desc bool, limit int32) (models.Threads, error) {
 
 	var rows pgx.Rows
 	var err error
@@ -239,6 +372,25 @@ func (repo Repository) GetThreadsByForumSlug(forumSlug string, since *time.Time,
 		return nil, errors.WithStack(err)
 	}
 
+	return repo.scanForumThreads(ctx, rows, forumSlug)
+}
+
+func (repo Repository) GetThreadsByForumSlugAfter(ctx context.Context, forumSlug string,
+	after models.Thread, desc bool, limit int32) (models.Threads, error) {
+
+	rows, err := repo.db.Query(ctx, sqlGetThreadsByForumSlugAfter[desc],
+		forumSlug, after.IsPinned, after.Created, after.ID, limit)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return repo.scanForumThreads(ctx, rows, forumSlug)
+}
+
+// scanForumThreads returns ErrDoesNotExist for empty rows of not existing forum
+func (repo Repository) scanForumThreads(ctx context.Context, rows pgx.Rows,
+	forumSlug string) (models.Threads, error) {
+
 	defer rows.Close()
 
 	threads := make(models.Threads, 0)
@@ -253,7 +405,7 @@ func (repo Repository) GetThreadsByForumSlug(forumSlug string, since *time.Time,
 	}
 
 	if len(threads) == 0 {
-		_, err := repo.forumRepo.GetBySlug(forumSlug)
+		_, err := repo.forumRepo.GetBySlug(ctx, forumSlug)
 		switch {
 		case errors.Is(err, models.ErrDoesNotExist):
 			return nil, models.ErrDoesNotExist
@@ -266,9 +418,180 @@ func (repo Repository) GetThreadsByForumSlug(forumSlug string, since *time.Time,
 	return threads, nil
 }
 
-func getByID(querier pgx4Helpers.Querier, id int32) (models.Thread, error) {
-	ctx := context.Background()
+// insertVote inserts or updates vote of user, votes in archived thread are rejected with ErrAccessDenied
+func insertVote(ctx context.Context, executer pgx4Helpers.Executer, threadID int32, vote models.Vote) error {
+	tag, err := executer.Exec(ctx, `
+			INSERT INTO votes (thread_id, author_nickname, voice)
+			SELECT $1::INTEGER, $2::CITEXT, $3::SMALLINT
+			WHERE NOT EXISTS(SELECT 1 FROM threads WHERE id = $1 AND state = 'archived')
+			ON CONFLICT (thread_id, author_nickname) DO UPDATE SET voice = $3`,
+		threadID,
+		vote.Nickname,
+		vote.Voice,
+	)
+
+	if pgxErr := codes.ExtractPgx4ErrorCode(err); pgxErr != nil {
+		switch pgxErr.Error() {
+		case codes.ErrCodeForeignKey:
+			return models.ErrDoesNotExist // author or thread does not exist
+		default:
+			return errors.Wrapf(err,
+				"some error while voting threadID=%d, vote=%+v", threadID, vote)
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "some error while voting threadID=%d, vote=%+v", threadID, vote)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return models.ErrAccessDenied // thread is archived
+	}
+
+	return nil
+}
+
+// insertVoteEvent adds thread.voted webhook events with thread votes after vote of user
+func insertVoteEvent(ctx context.Context, executer pgx4Helpers.Executer, thread models.Thread,
+	vote models.Vote) error {
+
+	return webhookRepository.InsertEvents(ctx, executer, thread.Forum, models.ThreadVotedWebhookEvent,
+		models.ThreadVotes{
+			Thread:   thread.ID,
+			Votes:    thread.Votes,
+			Nickname: vote.Nickname,
+			Voice:    vote.Voice,
+		})
+}
+
+// lockThreads locks threads rows till the end of transaction or returns ErrDoesNotExist if some thread does not exist
+func lockThreads(ctx context.Context, querier pgx4Helpers.Querier, ids ...int32) error {
+	var locked int
+	err := querier.QueryRow(ctx, `
+			WITH locked AS (
+				SELECT id
+				FROM threads
+				WHERE id = ANY ($1::INTEGER[])
+				ORDER BY id
+					FOR UPDATE
+			)
+			SELECT COUNT(*)
+			FROM locked`,
+		ids,
+	).Scan(&locked)
+
+	if err != nil {
+		return errors.Wrapf(err, "some error while locking threads with ids=%v", ids)
+	}
+
+	expected := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		expected[id] = true
+	}
+	if locked != len(expected) {
+		return models.ErrDoesNotExist
+	}
+
+	return nil
+}
+
+// threadAuthors returns author of thread followed by distinct authors of its posts
+func threadAuthors(ctx context.Context, querier pgx4Helpers.Querier, id int32) ([]string, error) {
+	rows, err := querier.Query(ctx, `
+			SELECT author_nickname
+			FROM threads
+			WHERE id = $1
+			UNION ALL
+			(SELECT DISTINCT author_nickname
+			 FROM posts
+			 WHERE thread_id = $1)`,
+		id,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "some error while selecting authors of threadID=%d", id)
+	}
+	defer rows.Close()
+
+	authors := make([]string, 0)
+	for rows.Next() {
+		var author string
+		if err := rows.Scan(&author); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		authors = append(authors, author)
+	}
+
+	return authors, errors.WithStack(rows.Err())
+}
+
+// movePosts moves posts of thread to target thread and forum and returns count of moved not deleted posts
+func movePosts(ctx context.Context, querier pgx4Helpers.Querier,
+	threadID, targetThreadID int32, forumSlug string) (int64, error) {
+
+	var moved int64
+	err := querier.QueryRow(ctx, `
+			WITH moved AS (
+				UPDATE posts
+				SET thread_id  = $2,
+					forum_slug = $3
+				WHERE thread_id = $1
+				RETURNING is_deleted
+			)
+			SELECT COUNT(*) FILTER (WHERE NOT is_deleted)
+			FROM moved`,
+		threadID,
+		targetThreadID,
+		forumSlug,
+	).Scan(&moved)
+
+	return moved, errors.Wrapf(err, "some error while moving posts of threadID=%d to threadID=%d, forumSlug=%s",
+		threadID, targetThreadID, forumSlug)
+}
 
+func updateForumCounters(ctx context.Context, executer pgx4Helpers.Executer,
+	forumSlug string, threadsDelta int32, postsDelta int64) error {
+
+	_, err := executer.Exec(ctx, `
+			UPDATE forums
+			SET threads = threads + $2,
+				posts   = posts + $3
+			WHERE slug = $1`,
+		forumSlug,
+		threadsDelta,
+		postsDelta,
+	)
+	return errors.Wrapf(err, "some error while updating counters of forumSlug=%s", forumSlug)
+}
+
+func addForumUsers(ctx context.Context, executer pgx4Helpers.Executer, forumSlug string, nicknames []string) error {
+	_, err := executer.Exec(ctx, `
+			INSERT INTO forums_users_nicknames (forum_slug, user_nickname)
+			SELECT $1, nickname
+			FROM UNNEST($2::TEXT[]) AS nickname
+			ON CONFLICT DO NOTHING`,
+		forumSlug,
+		nicknames,
+	)
+	return errors.Wrapf(err, "some error while adding users of forumSlug=%s", forumSlug)
+}
+
+// removeInactiveForumUsers removes users without threads and posts in forum from forum users
+func removeInactiveForumUsers(ctx context.Context, executer pgx4Helpers.Executer,
+	forumSlug string, nicknames []string) error {
+
+	_, err := executer.Exec(ctx, `
+			DELETE
+			FROM forums_users_nicknames AS fu
+			WHERE fu.forum_slug = $1
+			  AND fu.user_nickname = ANY ($2::TEXT[])
+			  AND NOT EXISTS(SELECT 1 FROM threads AS t WHERE t.forum_slug = $1 AND t.author_nickname = fu.user_nickname)
+			  AND NOT EXISTS(SELECT 1 FROM posts AS p WHERE p.forum_slug = $1 AND p.author_nickname = fu.user_nickname)`,
+		forumSlug,
+		nicknames,
+	)
+	return errors.Wrapf(err, "some error while removing users of forumSlug=%s", forumSlug)
+}
+
+func getByID(ctx context.Context, querier pgx4Helpers.Querier, id int32) (models.Thread, error) {
 	var thread models.Thread
 
 	row := querier.QueryRow(ctx, `
@@ -279,7 +602,9 @@ func getByID(querier pgx4Helpers.Querier, id int32) (models.Thread, error) {
 				   title,
 				   message,
 				   votes,
-				   created
+				   created,
+				   state,
+				   is_pinned
 			FROM threads
 			WHERE id = $1`,
 		id,
@@ -294,9 +619,7 @@ func getByID(querier pgx4Helpers.Querier, id int32) (models.Thread, error) {
 	return thread, errors.WithStack(err)
 }
 
-func getBySlug(queryer pgx4Helpers.Querier, slug string) (models.Thread, error) {
-	ctx := context.Background()
-
+func getBySlug(ctx context.Context, queryer pgx4Helpers.Querier, slug string) (models.Thread, error) {
 	var thread models.Thread
 
 	row := queryer.QueryRow(ctx, `
@@ -307,7 +630,9 @@ func getBySlug(queryer pgx4Helpers.Querier, slug string) (models.Thread, error)
 				   title,
 				   message,
 				   votes,
-				   created
+				   created,
+				   state,
+				   is_pinned
 			FROM threads
 			WHERE slug = $1`,
 		slug,
@@ -332,5 +657,7 @@ func scanThread(scanner sqlHelpers.Scanner, threadDst *models.Thread) error {
 		&threadDst.Message,
 		&threadDst.Votes,
 		&threadDst.Created,
+		&threadDst.State,
+		&threadDst.IsPinned,
 	)
 }
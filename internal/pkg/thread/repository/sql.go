@@ -1,5 +1,6 @@
 package repository
 
+// pinned threads are listed first, since filter is applied to them too
 var sqlGetThreadsByForumSlugSince = map[bool]string{
 	true: `
 		SELECT id,
@@ -9,10 +10,12 @@ var sqlGetThreadsByForumSlugSince = map[bool]string{
 			   title,
 			   message,
 			   votes,
-			   created
+			   created,
+			   state,
+			   is_pinned
 		FROM threads
 		WHERE forum_slug = $1 AND created <= $2
-		ORDER BY created DESC
+		ORDER BY is_pinned DESC, created DESC, id DESC
 		LIMIT $3`,
 
 	false: `
@@ -23,10 +26,12 @@ var sqlGetThreadsByForumSlugSince = map[bool]string{
 			   title,
 			   message,
 			   votes,
-			   created
+			   created,
+			   state,
+			   is_pinned
 		FROM threads
 		WHERE forum_slug = $1 AND created >= $2
-		ORDER BY created
+		ORDER BY is_pinned DESC, created, id
 		LIMIT $3`,
 }
 
@@ -39,10 +44,12 @@ var sqlGetThreadsByForumSlug = map[bool]string{
 			   title,
 			   message,
 			   votes,
-			   created
+			   created,
+			   state,
+			   is_pinned
 		FROM threads
 		WHERE forum_slug = $1
-		ORDER BY created DESC
+		ORDER BY is_pinned DESC, created DESC, id DESC
 		LIMIT $2`,
 
 	false: `
@@ -53,9 +60,46 @@ var sqlGetThreadsByForumSlug = map[bool]string{
 			   title,
 			   message,
 			   votes,
-			   created
+			   created,
+			   state,
+			   is_pinned
 		FROM threads
 		WHERE forum_slug = $1
-		ORDER BY created
+		ORDER BY is_pinned DESC, created, id
 		LIMIT $2`,
 }
+
+// rows after cursor thread in (is_pinned DESC, created, id) order
+var sqlGetThreadsByForumSlugAfter = map[bool]string{
+	true: `
+		SELECT id,
+			   slug,
+			   forum_slug,
+			   author_nickname,
+			   title,
+			   message,
+			   votes,
+			   created,
+			   state,
+			   is_pinned
+		FROM threads
+		WHERE forum_slug = $1 AND (is_pinned < $2 OR is_pinned = $2 AND (created, id) < ($3, $4))
+		ORDER BY is_pinned DESC, created DESC, id DESC
+		LIMIT $5`,
+
+	false: `
+		SELECT id,
+			   slug,
+			   forum_slug,
+			   author_nickname,
+			   title,
+			   message,
+			   votes,
+			   created,
+			   state,
+			   is_pinned
+		FROM threads
+		WHERE forum_slug = $1 AND (is_pinned < $2 OR is_pinned = $2 AND (created, id) > ($3, $4))
+		ORDER BY is_pinned DESC, created, id
+		LIMIT $5`,
+}
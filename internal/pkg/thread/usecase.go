@@ -1,11 +1,19 @@
 package thread
 
-import "github.com/nickeskov/db_forum/internal/pkg/models"
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+)
 
 type UseCase interface {
-	GetBySlugOrID(slugOrID string) (models.Thread, error)
-	VoteBySlugOrID(slugOrID string, vote models.Vote) (models.Thread, error)
-	Create(thread models.Thread) (models.Thread, error)
-	UpdateBySlugOrID(slugOrID string, thread models.Thread) (models.Thread, error)
-	GetThreadsByForumSlug(forumSlug, since, desc, limit string) (models.Threads, error)
+	GetBySlugOrID(ctx context.Context, slugOrID string) (models.Thread, error)
+	VoteBySlugOrID(ctx context.Context, slugOrID string, vote models.Vote) (models.Thread, error)
+	Create(ctx context.Context, thread models.Thread) (models.Thread, error)
+	UpdateBySlugOrID(ctx context.Context, slugOrID string, update models.ThreadUpdate) (models.Thread, error)
+	MoveBySlugOrID(ctx context.Context, slugOrID, forumSlug string) (models.Thread, error)
+	MergeBySlugOrID(ctx context.Context, slugOrID, targetSlugOrID string) (models.Thread, error)
+	GetThreadsByForumSlug(ctx context.Context,
+		forumSlug, since, desc, limit string) (models.Threads, error)
+	GetThreadsByForumSlugAfter(ctx context.Context,
+		forumSlug string, after models.Thread, desc, limit string) (models.Threads, error)
 }
@@ -0,0 +1,82 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/thread"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const useCaseName = "thread"
+
+// UseCase traces every method of wrapped usecase
+type UseCase struct {
+	useCase  thread.UseCase
+	observer instrumentation.Observer
+}
+
+func NewUseCase(useCase thread.UseCase, tracer trace.Tracer) UseCase {
+	return UseCase{
+		useCase:  useCase,
+		observer: instrumentation.NewObserver(useCaseName, "usecase", nil, tracer),
+	}
+}
+
+func (useCase UseCase) GetBySlugOrID(ctx context.Context, slugOrID string) (_ models.Thread, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GetBySlugOrID")
+	defer func() { done(err) }()
+	return useCase.useCase.GetBySlugOrID(ctx, slugOrID)
+}
+
+func (useCase UseCase) VoteBySlugOrID(ctx context.Context,
+	slugOrID string, vote models.Vote) (_ models.Thread, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "VoteBySlugOrID")
+	defer func() { done(err) }()
+	return useCase.useCase.VoteBySlugOrID(ctx, slugOrID, vote)
+}
+
+func (useCase UseCase) Create(ctx context.Context, thread models.Thread) (_ models.Thread, err error) {
+	ctx, done := useCase.observer.Start(ctx, "Create")
+	defer func() { done(err) }()
+	return useCase.useCase.Create(ctx, thread)
+}
+
+func (useCase UseCase) UpdateBySlugOrID(ctx context.Context,
+	slugOrID string, update models.ThreadUpdate) (_ models.Thread, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "UpdateBySlugOrID")
+	defer func() { done(err) }()
+	return useCase.useCase.UpdateBySlugOrID(ctx, slugOrID, update)
+}
+
+func (useCase UseCase) MoveBySlugOrID(ctx context.Context, slugOrID, forumSlug string) (_ models.Thread, err error) {
+	ctx, done := useCase.observer.Start(ctx, "MoveBySlugOrID")
+	defer func() { done(err) }()
+	return useCase.useCase.MoveBySlugOrID(ctx, slugOrID, forumSlug)
+}
+
+func (useCase UseCase) MergeBySlugOrID(ctx context.Context,
+	slugOrID, targetSlugOrID string) (_ models.Thread, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "MergeBySlugOrID")
+	defer func() { done(err) }()
+	return useCase.useCase.MergeBySlugOrID(ctx, slugOrID, targetSlugOrID)
+}
+
+func (useCase UseCase) GetThreadsByForumSlug(ctx context.Context,
+	forumSlug, since, desc, limit string) (_ models.Threads, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "GetThreadsByForumSlug")
+	defer func() { done(err) }()
+	return useCase.useCase.GetThreadsByForumSlug(ctx, forumSlug, since, desc, limit)
+}
+
+func (useCase UseCase) GetThreadsByForumSlugAfter(ctx context.Context,
+	forumSlug string, after models.Thread, desc, limit string) (_ models.Threads, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "GetThreadsByForumSlugAfter")
+	defer func() { done(err) }()
+	return useCase.useCase.GetThreadsByForumSlugAfter(ctx, forumSlug, after, desc, limit)
+}
@@ -1,6 +1,9 @@
 package usecase
 
 import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/events"
+	"github.com/nickeskov/db_forum/internal/pkg/forum"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
 	"github.com/nickeskov/db_forum/internal/pkg/thread"
 	"github.com/nickeskov/db_forum/internal/pkg/utils"
@@ -9,63 +12,141 @@ import (
 )
 
 type UseCase struct {
-	repo thread.Repository
+	repo      thread.Repository
+	forumRepo forum.Repository
+	publisher events.Publisher
 }
 
-func NewUseCase(repo thread.Repository) UseCase {
+func NewUseCase(repo thread.Repository, forumRepo forum.Repository, publisher events.Publisher) UseCase {
 	return UseCase{
-		repo: repo,
+		repo:      repo,
+		forumRepo: forumRepo,
+		publisher: publisher,
 	}
 }
 
-func (useCase UseCase) GetBySlugOrID(slugOrID string) (models.Thread, error) {
+func (useCase UseCase) GetBySlugOrID(ctx context.Context, slugOrID string) (models.Thread, error) {
 	if id, err := strconv.Atoi(slugOrID); err != nil {
-		return useCase.repo.GetBySlug(slugOrID)
+		return useCase.repo.GetBySlug(ctx, slugOrID)
 	} else {
-		return useCase.repo.GetByID(int32(id))
+		return useCase.repo.GetByID(ctx, int32(id))
 	}
 }
 
-func (useCase UseCase) VoteBySlugOrID(slugOrID string, vote models.Vote) (models.Thread, error) {
-	if id, err := strconv.Atoi(slugOrID); err != nil {
-		return useCase.repo.VoteBySlug(slugOrID, vote)
+func (useCase UseCase) VoteBySlugOrID(ctx context.Context,
+	slugOrID string, vote models.Vote) (models.Thread, error) {
+
+	var votedThread models.Thread
+	var err error
+
+	if id, convertErr := strconv.Atoi(slugOrID); convertErr != nil {
+		votedThread, err = useCase.repo.VoteBySlug(ctx, slugOrID, vote)
 	} else {
-		return useCase.repo.VoteByID(int32(id), vote)
+		votedThread, err = useCase.repo.VoteByID(ctx, int32(id), vote)
 	}
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	useCase.publisher.Publish(events.VoteEvent, models.ThreadVotes{
+		Thread: votedThread.ID,
+		Votes:  votedThread.Votes,
+	}, events.ActivityTopics(votedThread.ID, votedThread.Forum, vote.Nickname)...)
+
+	return votedThread, nil
 }
 
-func (useCase UseCase) Create(thread models.Thread) (models.Thread, error) {
-	return useCase.repo.Create(thread)
+func (useCase UseCase) MoveBySlugOrID(ctx context.Context, slugOrID, forumSlug string) (models.Thread, error) {
+	stored, err := useCase.GetBySlugOrID(ctx, slugOrID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	return useCase.repo.MoveByID(ctx, stored.ID, forumSlug)
 }
 
-func (useCase UseCase) UpdateBySlugOrID(slugOrID string, thread models.Thread) (models.Thread, error) {
-	if id, err := strconv.Atoi(slugOrID); err != nil {
-		thread.Slug = slugOrID
-		return useCase.repo.UpdateBySlug(thread)
-	} else {
-		thread.ID = int32(id)
-		return useCase.repo.UpdateByID(thread)
+func (useCase UseCase) MergeBySlugOrID(ctx context.Context,
+	slugOrID, targetSlugOrID string) (models.Thread, error) {
+
+	source, err := useCase.GetBySlugOrID(ctx, slugOrID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	target, err := useCase.GetBySlugOrID(ctx, targetSlugOrID)
+	if err != nil {
+		return models.Thread{}, err
 	}
+
+	return useCase.repo.MergeByID(ctx, source.ID, target.ID)
 }
 
-func (useCase UseCase) GetThreadsByForumSlug(forumSlug, since, desc, limit string) (models.Threads, error) {
-	limitInt, err := strconv.Atoi(limit)
+func (useCase UseCase) Create(ctx context.Context, thread models.Thread) (models.Thread, error) {
+	if err := forum.CheckNotBanned(ctx, useCase.forumRepo, thread.Forum, thread.Author); err != nil {
+		return models.Thread{}, err
+	}
+
+	createdThread, err := useCase.repo.Create(ctx, thread)
 	if err != nil {
-		return nil, models.ErrInvalid
+		return models.Thread{}, err
 	}
 
-	descBool, err := strconv.ParseBool(desc)
+	useCase.publisher.Publish(events.ThreadCreatedEvent, createdThread,
+		events.ActivityTopics(createdThread.ID, createdThread.Forum, createdThread.Author)...)
+
+	return createdThread, nil
+}
+
+func (useCase UseCase) UpdateBySlugOrID(ctx context.Context,
+	slugOrID string, update models.ThreadUpdate) (models.Thread, error) {
+
+	if id, err := strconv.Atoi(slugOrID); err != nil {
+		return useCase.repo.UpdateBySlug(ctx, slugOrID, update)
+	} else {
+		return useCase.repo.UpdateByID(ctx, int32(id), update)
+	}
+}
+
+func (useCase UseCase) GetThreadsByForumSlug(ctx context.Context,
+	forumSlug, since, desc, limit string) (models.Threads, error) {
+
+	descBool, limitInt, err := parseDescLimit(desc, limit)
 	if err != nil {
-		return nil, models.ErrInvalid
+		return nil, err
 	}
 
 	if since != "" {
 		if sinceTime, err := time.Parse(utils.TimestampFormat, since); err != nil {
 			return nil, models.ErrInvalid
 		} else {
-			return useCase.repo.GetThreadsByForumSlug(forumSlug, &sinceTime, descBool, int32(limitInt))
+			return useCase.repo.GetThreadsByForumSlug(ctx, forumSlug, &sinceTime, descBool, limitInt)
 		}
 	}
 
-	return useCase.repo.GetThreadsByForumSlug(forumSlug, nil, descBool, int32(limitInt))
+	return useCase.repo.GetThreadsByForumSlug(ctx, forumSlug, nil, descBool, limitInt)
+}
+
+func (useCase UseCase) GetThreadsByForumSlugAfter(ctx context.Context,
+	forumSlug string, after models.Thread, desc, limit string) (models.Threads, error) {
+
+	descBool, limitInt, err := parseDescLimit(desc, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.repo.GetThreadsByForumSlugAfter(ctx, forumSlug, after, descBool, limitInt)
+}
+
+func parseDescLimit(desc, limit string) (bool, int32, error) {
+	limitInt, err := strconv.Atoi(limit)
+	if err != nil {
+		return false, 0, models.ErrInvalid
+	}
+
+	descBool, err := strconv.ParseBool(desc)
+	if err != nil {
+		return false, 0, models.ErrInvalid
+	}
+
+	return descBool, int32(limitInt), nil
 }
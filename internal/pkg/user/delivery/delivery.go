@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/gorilla/mux"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
 	"github.com/nickeskov/db_forum/internal/pkg/user"
 	httpUtils "github.com/nickeskov/db_forum/pkg/http"
@@ -15,12 +16,14 @@ import (
 
 type Delivery struct {
 	useCase user.UseCase
+	guard   auth.Guard
 	utils   httpUtils.Utils
 }
 
-func NewDelivery(useCase user.UseCase, logger logger.Logger) Delivery {
+func NewDelivery(useCase user.UseCase, guard auth.Guard, logger logger.Logger) Delivery {
 	return Delivery{
 		useCase: useCase,
+		guard:   guard,
 		utils:   httpUtils.NewDeliveryUtils(logger),
 	}
 }
@@ -61,11 +64,17 @@ func (delivery Delivery) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userCreateErr := delivery.useCase.Create(newUser)
+	if delivery.guard.Enabled() && newUser.Password == "" {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, "password is required")
+		return
+	}
+
+	userCreateErr := delivery.useCase.Create(r.Context(), newUser)
+	newUser.Password = ""
 
 	switch userCreateErr {
 	case models.ErrAlreadyExist:
-		users, err := delivery.useCase.GetWithSameNicknameAndEmail(newUser.Nickname, newUser.Email)
+		users, err := delivery.useCase.GetWithSameNicknameAndEmail(r.Context(), newUser.Nickname, newUser.Email)
 		if err != nil {
 			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
 			return
@@ -94,7 +103,7 @@ func (delivery Delivery) CreateUser(w http.ResponseWriter, r *http.Request) {
 func (delivery Delivery) GetUser(w http.ResponseWriter, r *http.Request) {
 	nickname := mux.Vars(r)["nickname"]
 
-	storedUser, getUserErr := delivery.useCase.GetByNickname(nickname)
+	storedUser, getUserErr := delivery.useCase.GetByNickname(r.Context(), nickname)
 	switch getUserErr {
 	case models.ErrDoesNotExist:
 		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
@@ -120,7 +129,43 @@ func (delivery Delivery) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatedUser, userUpdateErr := delivery.useCase.UpdateByNickname(userForUpdate)
+	// password is changed only by its owner even if auth is disabled, otherwise anyone could take over user
+	checkCaller := delivery.guard.CheckCaller
+	if userForUpdate.Password != "" {
+		checkCaller = delivery.guard.RequireCaller
+	}
+	if err := checkCaller(r.Context(), userForUpdate.Nickname); err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return
+	}
+
+	updatedUser, userUpdateErr := delivery.useCase.UpdateByNickname(r.Context(), userForUpdate)
+	delivery.writeUpdateResponse(w, r, userForUpdate, updatedUser, userUpdateErr)
+}
+
+// SetPassword replaces password of any user, it is admin route for users registered without password,
+// who can't login to update their profile
+func (delivery Delivery) SetPassword(w http.ResponseWriter, r *http.Request) {
+	userForUpdate, err := delivery.getUserFomBody(w, r)
+	if err != nil {
+		return
+	}
+
+	if userForUpdate.Password == "" {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, "password is required")
+		return
+	}
+
+	updatedUser, userUpdateErr := delivery.useCase.UpdateByNickname(r.Context(), models.User{
+		Nickname: userForUpdate.Nickname,
+		Password: userForUpdate.Password,
+	})
+	delivery.writeUpdateResponse(w, r, userForUpdate, updatedUser, userUpdateErr)
+}
+
+func (delivery Delivery) writeUpdateResponse(w http.ResponseWriter, r *http.Request,
+	userForUpdate, updatedUser models.User, userUpdateErr error) {
+
 	switch userUpdateErr {
 	case models.ErrDoesNotExist:
 		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
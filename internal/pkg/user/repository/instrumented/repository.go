@@ -0,0 +1,55 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/user"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const repositoryName = "user"
+
+// Repository traces every method of wrapped repository and observes its latency
+type Repository struct {
+	repository user.Repository
+	observer   instrumentation.Observer
+}
+
+// NewRepository wraps repository, durations must have repository and method labels.
+// Durations and tracer may be nil.
+func NewRepository(repository user.Repository, durations *prometheus.HistogramVec,
+	tracer trace.Tracer) Repository {
+
+	return Repository{
+		repository: repository,
+		observer:   instrumentation.NewObserver(repositoryName, "repository", durations, tracer),
+	}
+}
+
+func (repo Repository) Create(ctx context.Context, user models.User) (err error) {
+	ctx, done := repo.observer.Start(ctx, "Create")
+	defer func() { done(err) }()
+	return repo.repository.Create(ctx, user)
+}
+
+func (repo Repository) UpdateByNickname(ctx context.Context, user models.User) (_ models.User, err error) {
+	ctx, done := repo.observer.Start(ctx, "UpdateByNickname")
+	defer func() { done(err) }()
+	return repo.repository.UpdateByNickname(ctx, user)
+}
+
+func (repo Repository) GetByNickname(ctx context.Context, nickname string) (_ models.User, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetByNickname")
+	defer func() { done(err) }()
+	return repo.repository.GetByNickname(ctx, nickname)
+}
+
+func (repo Repository) GetWithSameNicknameAndEmail(ctx context.Context,
+	nickname, email string) (_ models.Users, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetWithSameNicknameAndEmail")
+	defer func() { done(err) }()
+	return repo.repository.GetWithSameNicknameAndEmail(ctx, nickname, email)
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+)
+
+type Repository struct {
+	db *memoryDB.DB
+}
+
+func NewRepository(db *memoryDB.DB) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) Create(ctx context.Context, user models.User) error {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	nicknameKey, emailKey := memoryDB.CIKey(user.Nickname), memoryDB.CIKey(user.Email)
+
+	if _, ok := repo.db.UsersByNickname[nicknameKey]; ok {
+		return models.ErrAlreadyExist
+	}
+	if _, ok := repo.db.UsersByEmail[emailKey]; ok {
+		return models.ErrAlreadyExist
+	}
+
+	// password hash is stored apart from user like column which is never selected
+	if user.PasswordHash != "" {
+		repo.db.PasswordHashes[nicknameKey] = user.PasswordHash
+	}
+
+	stored := user
+	stored.Password, stored.PasswordHash = "", ""
+	repo.db.Users = append(repo.db.Users, &stored)
+	repo.db.UsersByNickname[nicknameKey] = &stored
+	repo.db.UsersByEmail[emailKey] = &stored
+
+	return nil
+}
+
+func (repo Repository) UpdateByNickname(ctx context.Context, user models.User) (models.User, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.User{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	stored, ok := repo.db.UsersByNickname[memoryDB.CIKey(user.Nickname)]
+	if !ok {
+		return models.User{}, models.ErrDoesNotExist
+	}
+
+	if user.Email != "" {
+		emailKey := memoryDB.CIKey(user.Email)
+		if owner, ok := repo.db.UsersByEmail[emailKey]; ok && owner != stored {
+			return models.User{}, models.ErrConflict
+		}
+
+		delete(repo.db.UsersByEmail, memoryDB.CIKey(stored.Email))
+		repo.db.UsersByEmail[emailKey] = stored
+		stored.Email = user.Email
+	}
+	if user.Fullname != "" {
+		stored.Fullname = user.Fullname
+	}
+	if user.About != "" {
+		stored.About = user.About
+	}
+	if user.PasswordHash != "" {
+		nicknameKey := memoryDB.CIKey(stored.Nickname)
+		repo.db.PasswordHashes[nicknameKey] = user.PasswordHash
+		for key, session := range repo.db.Sessions {
+			if memoryDB.CIKey(session.Nickname) == nicknameKey {
+				delete(repo.db.Sessions, key)
+			}
+		}
+	}
+
+	return *stored, nil
+}
+
+func (repo Repository) GetByNickname(ctx context.Context, nickname string) (models.User, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.User{}, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	stored, ok := repo.db.UsersByNickname[memoryDB.CIKey(nickname)]
+	if !ok {
+		return models.User{}, models.ErrDoesNotExist
+	}
+
+	return *stored, nil
+}
+
+func (repo Repository) GetWithSameNicknameAndEmail(ctx context.Context,
+	nickname, email string) (models.Users, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	nicknameKey, emailKey := memoryDB.CIKey(nickname), memoryDB.CIKey(email)
+
+	var users models.Users
+	for _, stored := range repo.db.Users {
+		if memoryDB.CIKey(stored.Nickname) == nicknameKey || memoryDB.CIKey(stored.Email) == emailKey {
+			users = append(users, *stored)
+		}
+	}
+
+	return users, nil
+}
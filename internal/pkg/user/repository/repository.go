@@ -20,16 +20,15 @@ func NewRepository(db *pgxpool.Pool) Repository {
 	}
 }
 
-func (repo Repository) Create(user models.User) error {
-	ctx := context.Background()
-
+func (repo Repository) Create(ctx context.Context, user models.User) error {
 	_, err := repo.db.Exec(ctx,
-		`	INSERT INTO users (nickname, email, fullname, about) 
-				VALUES ($1, $2, $3, $4)`,
+		`	INSERT INTO users (nickname, email, fullname, about, password_hash) 
+				VALUES ($1, $2, $3, $4, NULLIF($5, ''))`,
 		user.Nickname,
 		user.Email,
 		user.Fullname,
 		user.About,
+		user.PasswordHash,
 	)
 
 	pgxErr := codes.ExtractPgx4ErrorCode(err)
@@ -40,21 +39,27 @@ func (repo Repository) Create(user models.User) error {
 	return errors.WithStack(err)
 }
 
-func (repo Repository) UpdateByNickname(user models.User) (models.User, error) {
-	ctx := context.Background()
-
+func (repo Repository) UpdateByNickname(ctx context.Context, user models.User) (models.User, error) {
+	// sessions of user are revoked with password change, so leaked token doesn't outlive old password
 	row := repo.db.QueryRow(ctx,
-		`	UPDATE users
+		`	WITH revoked AS (
+					DELETE FROM sessions
+					WHERE $5 <> '' AND user_nickname = $1
+				)
+				UPDATE users
 				SET email=COALESCE(NULLIF($2, ''), email),
 					fullname=COALESCE(NULLIF($3, ''), fullname),
-					about=COALESCE(NULLIF($4, ''), about)
+					about=COALESCE(NULLIF($4, ''), about),
+					password_hash=COALESCE(NULLIF($5, ''), password_hash)
 				WHERE nickname = $1
 				RETURNING nickname, email, fullname, about`,
 		user.Nickname,
 		user.Email,
 		user.Fullname,
 		user.About,
+		user.PasswordHash,
 	)
+	user.PasswordHash = ""
 
 	if err := scanUser(row, &user); err != nil {
 		if err == pgx.ErrNoRows {
@@ -75,9 +80,7 @@ func (repo Repository) UpdateByNickname(user models.User) (models.User, error) {
 	return user, nil
 }
 
-func (repo Repository) GetByNickname(nickname string) (user models.User, err error) {
-	ctx := context.Background()
-
+func (repo Repository) GetByNickname(ctx context.Context, nickname string) (user models.User, err error) {
 	row := repo.db.QueryRow(ctx,
 		`	SELECT 	nickname,
 						email,
@@ -101,8 +104,8 @@ func (repo Repository) GetByNickname(nickname string) (user models.User, err err
 	return user, nil
 }
 
-func (repo Repository) GetWithSameNicknameAndEmail(nickname, email string) (users models.Users, err error) {
-	ctx := context.Background()
+func (repo Repository) GetWithSameNicknameAndEmail(ctx context.Context,
+	nickname, email string) (users models.Users, err error) {
 
 	rows, err := repo.db.Query(ctx,
 		`	SELECT 	nickname,
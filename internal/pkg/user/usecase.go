@@ -1,10 +1,13 @@
 package user
 
-import "github.com/nickeskov/db_forum/internal/pkg/models"
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+)
 
 type UseCase interface {
-	Create(user models.User) error
-	UpdateByNickname(user models.User) (models.User, error)
-	GetByNickname(nickname string) (models.User, error)
-	GetWithSameNicknameAndEmail(nickname, email string) (models.Users, error)
+	Create(ctx context.Context, user models.User) error
+	UpdateByNickname(ctx context.Context, user models.User) (models.User, error)
+	GetByNickname(ctx context.Context, nickname string) (models.User, error)
+	GetWithSameNicknameAndEmail(ctx context.Context, nickname, email string) (models.Users, error)
 }
@@ -0,0 +1,50 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/user"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const useCaseName = "user"
+
+// UseCase traces every method of wrapped usecase
+type UseCase struct {
+	useCase  user.UseCase
+	observer instrumentation.Observer
+}
+
+func NewUseCase(useCase user.UseCase, tracer trace.Tracer) UseCase {
+	return UseCase{
+		useCase:  useCase,
+		observer: instrumentation.NewObserver(useCaseName, "usecase", nil, tracer),
+	}
+}
+
+func (useCase UseCase) Create(ctx context.Context, user models.User) (err error) {
+	ctx, done := useCase.observer.Start(ctx, "Create")
+	defer func() { done(err) }()
+	return useCase.useCase.Create(ctx, user)
+}
+
+func (useCase UseCase) UpdateByNickname(ctx context.Context, user models.User) (_ models.User, err error) {
+	ctx, done := useCase.observer.Start(ctx, "UpdateByNickname")
+	defer func() { done(err) }()
+	return useCase.useCase.UpdateByNickname(ctx, user)
+}
+
+func (useCase UseCase) GetByNickname(ctx context.Context, nickname string) (_ models.User, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GetByNickname")
+	defer func() { done(err) }()
+	return useCase.useCase.GetByNickname(ctx, nickname)
+}
+
+func (useCase UseCase) GetWithSameNicknameAndEmail(ctx context.Context,
+	nickname, email string) (_ models.Users, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "GetWithSameNicknameAndEmail")
+	defer func() { done(err) }()
+	return useCase.useCase.GetWithSameNicknameAndEmail(ctx, nickname, email)
+}
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
 	"github.com/nickeskov/db_forum/internal/pkg/models"
 	"github.com/nickeskov/db_forum/internal/pkg/user"
 )
@@ -15,18 +17,46 @@ func NewUseCase(repository user.Repository) UseCase {
 	}
 }
 
-func (useCase UseCase) Create(user models.User) error {
-	return useCase.repository.Create(user)
+func (useCase UseCase) Create(ctx context.Context, user models.User) error {
+	user, err := hashPassword(user)
+	if err != nil {
+		return err
+	}
+
+	return useCase.repository.Create(ctx, user)
 }
 
-func (useCase UseCase) UpdateByNickname(user models.User) (models.User, error) {
-	return useCase.repository.UpdateByNickname(user)
+// UpdateByNickname updates not empty fields of user, password is replaced if it is set
+func (useCase UseCase) UpdateByNickname(ctx context.Context, user models.User) (models.User, error) {
+	user, err := hashPassword(user)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return useCase.repository.UpdateByNickname(ctx, user)
 }
 
-func (useCase UseCase) GetByNickname(nickname string) (user models.User, err error) {
-	return useCase.repository.GetByNickname(nickname)
+// hashPassword replaces password of user with its hash, so plain password never reaches repository
+func hashPassword(user models.User) (models.User, error) {
+	if user.Password == "" {
+		return user, nil
+	}
+
+	passwordHash, err := auth.HashPassword(user.Password)
+	if err != nil {
+		return models.User{}, err
+	}
+	user.Password, user.PasswordHash = "", passwordHash
+
+	return user, nil
 }
 
-func (useCase UseCase) GetWithSameNicknameAndEmail(nickname, email string) (users models.Users, err error) {
-	return useCase.repository.GetWithSameNicknameAndEmail(nickname, email)
+func (useCase UseCase) GetByNickname(ctx context.Context, nickname string) (user models.User, err error) {
+	return useCase.repository.GetByNickname(ctx, nickname)
+}
+
+func (useCase UseCase) GetWithSameNicknameAndEmail(ctx context.Context,
+	nickname, email string) (users models.Users, err error) {
+
+	return useCase.repository.GetWithSameNicknameAndEmail(ctx, nickname, email)
 }
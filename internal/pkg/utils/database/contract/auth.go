@@ -0,0 +1,128 @@
+package contract
+
+import (
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"testing"
+	"time"
+)
+
+// RunAuth checks auth.Repository contract
+func RunAuth(t *testing.T, newRepositories Factory) {
+	runCases(t, newRepositories, []testCase{
+		{"PasswordHashIsNotExposed", func(f fixture) {
+			userModel := models.User{
+				Nickname:     "John",
+				Email:        "john@example.com",
+				PasswordHash: "hash",
+			}
+			f.noError(f.repos.User.Create(f.ctx, userModel), "create user with password")
+
+			nickname, passwordHash, err := f.repos.Auth.GetPasswordHash(f.ctx, "JOHN")
+			f.noError(err, "get password hash")
+			f.expectEqual(nickname, "John", "canonical nickname")
+			f.expectEqual(passwordHash, "hash", "password hash")
+
+			got, err := f.repos.User.GetByNickname(f.ctx, "john")
+			f.noError(err, "get user")
+			f.expectEqual(got.PasswordHash, "", "password hash of user")
+		}},
+		{"PasswordHashIsSetByUpdate", func(f fixture) {
+			f.user("john")
+
+			updated, err := f.repos.User.UpdateByNickname(f.ctx, models.User{Nickname: "JOHN", PasswordHash: "hash"})
+			f.noError(err, "set password hash")
+			f.expectEqual(updated.PasswordHash, "", "password hash of updated user")
+
+			_, passwordHash, err := f.repos.Auth.GetPasswordHash(f.ctx, "john")
+			f.noError(err, "get password hash")
+			f.expectEqual(passwordHash, "hash", "password hash")
+
+			_, err = f.repos.User.UpdateByNickname(f.ctx, models.User{Nickname: "john", About: "about"})
+			f.noError(err, "update user without password")
+
+			_, passwordHash, err = f.repos.Auth.GetPasswordHash(f.ctx, "john")
+			f.noError(err, "get password hash after update")
+			f.expectEqual(passwordHash, "hash", "kept password hash")
+		}},
+		{"PasswordChangeRevokesSessions", func(f fixture) {
+			f.user("john")
+			f.user("jane")
+
+			now := time.Now()
+			for _, nickname := range []string{"john", "jane"} {
+				err := f.repos.Auth.CreateSession(f.ctx, []byte(nickname+" token hash"), nickname, now.Add(time.Hour))
+				f.noError(err, "create session")
+			}
+
+			_, err := f.repos.User.UpdateByNickname(f.ctx, models.User{Nickname: "john", About: "about"})
+			f.noError(err, "update user without password")
+			_, err = f.repos.Auth.GetSessionNickname(f.ctx, []byte("john token hash"), now)
+			f.noError(err, "get session after update without password")
+
+			_, err = f.repos.User.UpdateByNickname(f.ctx, models.User{Nickname: "JOHN", PasswordHash: "hash"})
+			f.noError(err, "change password hash")
+			_, err = f.repos.Auth.GetSessionNickname(f.ctx, []byte("john token hash"), now)
+			f.expectError(err, models.ErrDoesNotExist, "get session after password change")
+
+			nickname, err := f.repos.Auth.GetSessionNickname(f.ctx, []byte("jane token hash"), now)
+			f.noError(err, "get session of other user")
+			f.expectEqual(nickname, "jane", "session nickname of other user")
+		}},
+		{"PasswordHashOfUserWithoutPassword", func(f fixture) {
+			f.user("john")
+
+			_, passwordHash, err := f.repos.Auth.GetPasswordHash(f.ctx, "john")
+			f.noError(err, "get password hash")
+			f.expectEqual(passwordHash, "", "password hash")
+
+			_, _, err = f.repos.Auth.GetPasswordHash(f.ctx, "nobody")
+			f.expectError(err, models.ErrDoesNotExist, "get password hash of not existing user")
+		}},
+		{"Sessions", func(f fixture) {
+			f.user("John")
+
+			now := time.Now()
+			tokenHash := []byte("token hash")
+
+			err := f.repos.Auth.CreateSession(f.ctx, tokenHash, "John", now.Add(time.Hour))
+			f.noError(err, "create session")
+
+			nickname, err := f.repos.Auth.GetSessionNickname(f.ctx, tokenHash, now)
+			f.noError(err, "get session")
+			f.expectEqual(nickname, "John", "session nickname")
+
+			_, err = f.repos.Auth.GetSessionNickname(f.ctx, tokenHash, now.Add(2*time.Hour))
+			f.expectError(err, models.ErrDoesNotExist, "get expired session")
+
+			_, err = f.repos.Auth.GetSessionNickname(f.ctx, []byte("other hash"), now)
+			f.expectError(err, models.ErrDoesNotExist, "get not existing session")
+
+			f.noError(f.repos.Auth.DeleteSession(f.ctx, tokenHash), "delete session")
+
+			_, err = f.repos.Auth.GetSessionNickname(f.ctx, tokenHash, now)
+			f.expectError(err, models.ErrDoesNotExist, "get deleted session")
+
+			err = f.repos.Auth.DeleteSession(f.ctx, tokenHash)
+			f.expectError(err, models.ErrDoesNotExist, "delete deleted session")
+		}},
+		{"SessionOfNotExistingUser", func(f fixture) {
+			err := f.repos.Auth.CreateSession(f.ctx, []byte("token hash"), "nobody", time.Now().Add(time.Hour))
+			f.expectError(err, models.ErrDoesNotExist, "create session of not existing user")
+		}},
+		{"ExpiredSessionsAreRemovedOnLogin", func(f fixture) {
+			f.user("john")
+
+			now := time.Now()
+			expiredHash, freshHash := []byte("expired hash"), []byte("fresh hash")
+
+			err := f.repos.Auth.CreateSession(f.ctx, expiredHash, "john", now.Add(-time.Hour))
+			f.noError(err, "create expired session")
+			err = f.repos.Auth.CreateSession(f.ctx, freshHash, "john", now.Add(time.Hour))
+			f.noError(err, "create fresh session")
+
+			err = f.repos.Auth.DeleteSession(f.ctx, expiredHash)
+			f.expectError(err, models.ErrDoesNotExist, "delete expired session")
+			f.noError(f.repos.Auth.DeleteSession(f.ctx, freshHash), "delete fresh session")
+		}},
+	})
+}
@@ -0,0 +1,179 @@
+// Package contract contains conformance tests which every storage backend of forum repositories must pass.
+// Suite checks behaviour of postgres schema: citext keys, triggers maintained counters, votes and posts paths.
+package contract
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
+	"github.com/nickeskov/db_forum/internal/pkg/forum"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/post"
+	"github.com/nickeskov/db_forum/internal/pkg/search"
+	"github.com/nickeskov/db_forum/internal/pkg/thread"
+	"github.com/nickeskov/db_forum/internal/pkg/user"
+	"github.com/nickeskov/db_forum/internal/pkg/webhook"
+	"github.com/pkg/errors"
+	"testing"
+	"time"
+)
+
+type Repositories struct {
+	Auth    auth.Repository
+	User    user.Repository
+	Forum   forum.Repository
+	Thread  thread.Repository
+	Post    post.Repository
+	Search  search.Repository
+	Webhook webhook.Repository
+}
+
+// Factory returns repositories over empty storage, it is called before every test case.
+// Ids sequences may be not restarted, so tests rely only on ids order.
+type Factory func(t *testing.T) Repositories
+
+// Run runs all contract tests against repositories returned by newRepositories
+func Run(t *testing.T, newRepositories Factory) {
+	t.Run("User", func(t *testing.T) { RunUser(t, newRepositories) })
+	t.Run("Forum", func(t *testing.T) { RunForum(t, newRepositories) })
+	t.Run("Thread", func(t *testing.T) { RunThread(t, newRepositories) })
+	t.Run("Post", func(t *testing.T) { RunPost(t, newRepositories) })
+	t.Run("Search", func(t *testing.T) { RunSearch(t, newRepositories) })
+	t.Run("Auth", func(t *testing.T) { RunAuth(t, newRepositories) })
+	t.Run("Webhook", func(t *testing.T) { RunWebhook(t, newRepositories) })
+}
+
+type testCase struct {
+	name string
+	run  func(f fixture)
+}
+
+func runCases(t *testing.T, newRepositories Factory, cases []testCase) {
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(fixture{
+				t:     t,
+				ctx:   context.Background(),
+				repos: newRepositories(t),
+			})
+		})
+	}
+}
+
+// fixture creates test data and fails test on unexpected errors
+type fixture struct {
+	t     *testing.T
+	ctx   context.Context
+	repos Repositories
+}
+
+func (f fixture) user(nickname string) models.User {
+	f.t.Helper()
+
+	userModel := models.User{
+		Nickname: nickname,
+		Fullname: "Full " + nickname,
+		About:    "about " + nickname,
+		Email:    nickname + "@example.com",
+	}
+	if err := f.repos.User.Create(f.ctx, userModel); err != nil {
+		f.t.Fatalf("cannot create user %s: %+v", nickname, err)
+	}
+
+	return userModel
+}
+
+func (f fixture) forum(slug, owner string) models.Forum {
+	f.t.Helper()
+
+	forumModel, err := f.repos.Forum.Create(f.ctx, models.Forum{
+		Slug:  slug,
+		Title: "Forum " + slug,
+		User:  owner,
+	})
+	if err != nil {
+		f.t.Fatalf("cannot create forum %s: %+v", slug, err)
+	}
+
+	return forumModel
+}
+
+func (f fixture) thread(forumSlug, author, slug string, created time.Time) models.Thread {
+	f.t.Helper()
+
+	threadModel, err := f.repos.Thread.Create(f.ctx, models.Thread{
+		Title:   "Thread " + slug,
+		Author:  author,
+		Forum:   forumSlug,
+		Message: "message of thread " + slug,
+		Slug:    slug,
+		Created: created,
+	})
+	if err != nil {
+		f.t.Fatalf("cannot create thread %s: %+v", slug, err)
+	}
+
+	return threadModel
+}
+
+func (f fixture) posts(threadModel models.Thread, posts ...models.Post) models.Posts {
+	f.t.Helper()
+
+	created, err := f.repos.Post.CreatePostsInThread(f.ctx, threadModel, posts)
+	if err != nil {
+		f.t.Fatalf("cannot create posts in thread %d: %+v", threadModel.ID, err)
+	}
+	if len(created) != len(posts) {
+		f.t.Fatalf("expected %d created posts, got %d", len(posts), len(created))
+	}
+
+	return created
+}
+
+func (f fixture) getForum(slug string) models.Forum {
+	f.t.Helper()
+
+	forumModel, err := f.repos.Forum.GetBySlug(f.ctx, slug)
+	if err != nil {
+		f.t.Fatalf("cannot get forum %s: %+v", slug, err)
+	}
+
+	return forumModel
+}
+
+func (f fixture) noError(err error, action string) {
+	f.t.Helper()
+
+	if err != nil {
+		f.t.Fatalf("%s: unexpected error %+v", action, err)
+	}
+}
+
+func (f fixture) expectError(err, expected error, action string) {
+	f.t.Helper()
+
+	if !errors.Is(err, expected) {
+		f.t.Fatalf("%s: expected error %q, got %+v", action, expected, err)
+	}
+}
+
+func (f fixture) expectEqual(actual, expected interface{}, what string) {
+	f.t.Helper()
+
+	if actual != expected {
+		f.t.Fatalf("%s: expected %v, got %v", what, expected, actual)
+	}
+}
+
+func (f fixture) expectStrings(actual, expected []string, what string) {
+	f.t.Helper()
+
+	if len(actual) != len(expected) {
+		f.t.Fatalf("%s: expected %v, got %v", what, expected, actual)
+	}
+	for i := range actual {
+		if actual[i] != expected[i] {
+			f.t.Fatalf("%s: expected %v, got %v", what, expected, actual)
+		}
+	}
+}
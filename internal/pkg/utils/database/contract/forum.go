@@ -0,0 +1,161 @@
+package contract
+
+import (
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"testing"
+	"time"
+)
+
+// RunForum checks forum.Repository contract
+func RunForum(t *testing.T, newRepositories Factory) {
+	runCases(t, newRepositories, []testCase{
+		{"CreateAndGetIgnoringCase", func(f fixture) {
+			f.user("John")
+
+			created := f.forum("Go-Forum", "JOHN")
+			f.expectEqual(created.User, "John", "forum owner")
+
+			got := f.getForum("go-forum")
+			f.expectEqual(got.Slug, "Go-Forum", "forum slug")
+			f.expectEqual(got.User, "John", "forum owner")
+			f.expectEqual(got.Threads, int64(0), "forum threads")
+			f.expectEqual(got.Posts, int64(0), "forum posts")
+		}},
+		{"GetNotExisting", func(f fixture) {
+			_, err := f.repos.Forum.GetBySlug(f.ctx, "nothing")
+			f.expectError(err, models.ErrDoesNotExist, "get not existing forum")
+		}},
+		{"CreateWithNotExistingOwner", func(f fixture) {
+			_, err := f.repos.Forum.Create(f.ctx, models.Forum{Slug: "go", Title: "Go", User: "nobody"})
+			f.expectError(err, models.ErrBadForeign, "create forum with not existing owner")
+		}},
+		{"CreateWithSameSlug", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+
+			_, err := f.repos.Forum.Create(f.ctx, models.Forum{Slug: "GO", Title: "Go", User: "john"})
+			f.expectError(err, models.ErrConflict, "create forum with same slug")
+		}},
+		{"UsersOfNotExistingForum", func(f fixture) {
+			_, err := f.repos.Forum.GetForumUsersBySlug(f.ctx, "nothing", "", false, 10)
+			f.expectError(err, models.ErrDoesNotExist, "get users of not existing forum")
+		}},
+		{"UsersArePostersAndThreadAuthors", func(f fixture) {
+			for _, nickname := range []string{"owner", "a.bob", "Carl", "dave", "eve", "lurker"} {
+				f.user(nickname)
+			}
+			f.forum("go", "owner")
+			f.forum("other", "owner")
+
+			threadModel := f.thread("go", "DAVE", "", time.Now())
+			f.thread("go", "a.bob", "", time.Now())
+			f.posts(threadModel,
+				models.Post{Author: "carl", Message: "post"},
+				models.Post{Author: "eve", Message: "post"},
+				models.Post{Author: "Dave", Message: "post"},
+			)
+			otherThread := f.thread("other", "lurker", "", time.Now())
+			f.posts(otherThread, models.Post{Author: "owner", Message: "post"})
+
+			users, err := f.repos.Forum.GetForumUsersBySlug(f.ctx, "GO", "", false, 10)
+			f.noError(err, "get forum users")
+			f.expectStrings(nicknames(users), []string{"a.bob", "Carl", "dave", "eve"}, "forum users")
+
+			users, err = f.repos.Forum.GetForumUsersBySlug(f.ctx, "go", "", true, 2)
+			f.noError(err, "get forum users desc")
+			f.expectStrings(nicknames(users), []string{"eve", "dave"}, "forum users desc")
+
+			users, err = f.repos.Forum.GetForumUsersBySlug(f.ctx, "go", "carl", false, 10)
+			f.noError(err, "get forum users since")
+			f.expectStrings(nicknames(users), []string{"dave", "eve"}, "forum users since")
+
+			users, err = f.repos.Forum.GetForumUsersBySlug(f.ctx, "go", "Dave", true, 10)
+			f.noError(err, "get forum users since desc")
+			f.expectStrings(nicknames(users), []string{"Carl", "a.bob"}, "forum users since desc")
+
+			users, err = f.repos.Forum.GetForumUsersBySlug(f.ctx, "go", "eve", false, 10)
+			f.noError(err, "get forum users since last")
+			f.expectEqual(len(users), 0, "forum users since last count")
+		}},
+		{"OwnerRole", func(f fixture) {
+			f.user("Owner")
+			f.forum("Go", "owner")
+
+			role, err := f.repos.Forum.GetRole(f.ctx, "go", "OWNER")
+			f.noError(err, "get owner role")
+			f.expectEqual(role, models.ForumRole{Forum: "Go", Nickname: "Owner", Role: models.OwnerForumRole}, "owner role")
+
+			_, err = f.repos.Forum.GetRoles(f.ctx, "nothing")
+			f.expectError(err, models.ErrDoesNotExist, "get roles of not existing forum")
+		}},
+		{"GrantAndRevokeRole", func(f fixture) {
+			f.user("owner")
+			f.user("Bob")
+			f.user("alice")
+			f.forum("go", "owner")
+
+			granted, err := f.repos.Forum.SetRole(f.ctx,
+				models.ForumRole{Forum: "GO", Nickname: "bob", Role: models.MemberForumRole})
+			f.noError(err, "grant member role")
+			f.expectEqual(granted, models.ForumRole{Forum: "go", Nickname: "Bob", Role: models.MemberForumRole},
+				"granted role")
+
+			granted, err = f.repos.Forum.SetRole(f.ctx,
+				models.ForumRole{Forum: "go", Nickname: "BOB", Role: models.ModeratorForumRole})
+			f.noError(err, "replace role with moderator")
+			f.expectEqual(granted.Role, models.ModeratorForumRole, "replaced role")
+
+			_, err = f.repos.Forum.SetRole(f.ctx,
+				models.ForumRole{Forum: "go", Nickname: "alice", Role: models.BannedForumRole})
+			f.noError(err, "ban user")
+
+			roles, err := f.repos.Forum.GetRoles(f.ctx, "go")
+			f.noError(err, "get roles")
+			f.expectEqual(len(roles), 3, "roles count")
+			f.expectStrings([]string{roles[0].Nickname, roles[1].Nickname, roles[2].Nickname},
+				[]string{"alice", "Bob", "owner"}, "roles nicknames")
+			f.expectStrings([]string{roles[0].Role, roles[1].Role, roles[2].Role},
+				[]string{models.BannedForumRole, models.ModeratorForumRole, models.OwnerForumRole}, "roles")
+
+			f.noError(f.repos.Forum.DeleteRole(f.ctx, "GO", "bob"), "revoke role")
+
+			_, err = f.repos.Forum.GetRole(f.ctx, "go", "bob")
+			f.expectError(err, models.ErrDoesNotExist, "get revoked role")
+
+			err = f.repos.Forum.DeleteRole(f.ctx, "go", "bob")
+			f.expectError(err, models.ErrDoesNotExist, "revoke revoked role")
+		}},
+		{"OwnerRoleIsImmutable", func(f fixture) {
+			f.user("owner")
+			f.user("bob")
+			f.forum("go", "owner")
+
+			_, err := f.repos.Forum.SetRole(f.ctx,
+				models.ForumRole{Forum: "go", Nickname: "Owner", Role: models.BannedForumRole})
+			f.expectError(err, models.ErrConflict, "replace owner role")
+
+			_, err = f.repos.Forum.SetRole(f.ctx,
+				models.ForumRole{Forum: "go", Nickname: "bob", Role: models.OwnerForumRole})
+			f.expectError(err, models.ErrConflict, "grant owner role")
+
+			err = f.repos.Forum.DeleteRole(f.ctx, "go", "owner")
+			f.expectError(err, models.ErrConflict, "revoke owner role")
+
+			role, err := f.repos.Forum.GetRole(f.ctx, "go", "owner")
+			f.noError(err, "get owner role")
+			f.expectEqual(role.Role, models.OwnerForumRole, "owner role")
+		}},
+		{"RoleOfNotExisting", func(f fixture) {
+			f.user("owner")
+			f.forum("go", "owner")
+
+			_, err := f.repos.Forum.SetRole(f.ctx,
+				models.ForumRole{Forum: "go", Nickname: "nobody", Role: models.MemberForumRole})
+			f.expectError(err, models.ErrBadForeign, "grant role to not existing user")
+
+			_, err = f.repos.Forum.SetRole(f.ctx,
+				models.ForumRole{Forum: "nothing", Nickname: "owner", Role: models.MemberForumRole})
+			f.expectError(err, models.ErrBadForeign, "grant role in not existing forum")
+		}},
+	})
+}
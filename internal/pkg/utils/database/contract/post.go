@@ -0,0 +1,467 @@
+package contract
+
+import (
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/post"
+	"testing"
+)
+
+// RunPost checks post.Repository contract
+func RunPost(t *testing.T, newRepositories Factory) {
+	runCases(t, newRepositories, []testCase{
+		{"CreateAndGet", func(f fixture) {
+			f.user("John")
+			f.forum("Go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+
+			created := f.posts(threadModel,
+				models.Post{Author: "john", Message: "first"},
+				models.Post{Author: "JOHN", Message: "second"},
+			)
+			f.expectEqual(created[0].ID < created[1].ID, true, "ids are increasing")
+			f.expectEqual(created[0].Created.Equal(created[1].Created), true, "posts of batch have same created")
+
+			reply := f.posts(threadModel, models.Post{Author: "john", Message: "reply", Parent: created[0].ID})[0]
+			f.expectEqual(reply.Parent, created[0].ID, "reply parent")
+			f.expectEqual(reply.Thread, threadModel.ID, "reply thread")
+			f.expectEqual(reply.Forum, "Go", "reply forum")
+
+			got, err := f.repos.Post.GetPostByID(f.ctx, reply.ID)
+			f.noError(err, "get post")
+			f.expectEqual(got.Message, "reply", "post message")
+			f.expectEqual(got.Parent, created[0].ID, "post parent")
+			f.expectEqual(got.IsEdited, false, "post is edited")
+
+			f.expectEqual(f.getForum("go").Posts, int64(3), "forum posts")
+		}},
+		{"CreateEmpty", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+
+			created, err := f.repos.Post.CreatePostsInThread(f.ctx, threadModel, models.Posts{})
+			f.noError(err, "create empty posts")
+			f.expectEqual(len(created), 0, "created posts count")
+		}},
+		{"GetNotExisting", func(f fixture) {
+			_, err := f.repos.Post.GetPostByID(f.ctx, 1<<40)
+			f.expectError(err, models.ErrDoesNotExist, "get not existing post")
+		}},
+		{"CreateWithBadParent", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+			otherThread := f.thread("go", "john", "other", testTime)
+			otherPost := f.posts(otherThread, models.Post{Author: "john", Message: "other"})[0]
+			existing := f.posts(threadModel, models.Post{Author: "john", Message: "existing"})[0]
+
+			_, err := f.repos.Post.CreatePostsInThread(f.ctx, threadModel, models.Posts{
+				{Author: "john", Message: "ok", Parent: existing.ID},
+				{Author: "john", Message: "bad", Parent: 1 << 40},
+			})
+			f.expectError(err, models.ErrConflict, "create post with not existing parent")
+
+			_, err = f.repos.Post.CreatePostsInThread(f.ctx, threadModel, models.Posts{
+				{Author: "john", Message: "bad", Parent: otherPost.ID},
+			})
+			f.expectError(err, models.ErrConflict, "create post with parent from other thread")
+
+			f.expectPosts(threadModel, nil, post.FlatSort, false, 10, []string{"existing"})
+			f.expectEqual(f.getForum("go").Posts, int64(2), "forum posts")
+		}},
+		{"CreateWithNotExistingAuthor", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+
+			_, err := f.repos.Post.CreatePostsInThread(f.ctx, threadModel, models.Posts{
+				{Author: "john", Message: "ok"},
+				{Author: "nobody", Message: "bad"},
+			})
+			f.expectError(err, models.ErrDoesNotExist, "create post with not existing author")
+
+			f.expectPosts(threadModel, nil, post.FlatSort, false, 10, []string{})
+			f.expectEqual(f.getForum("go").Posts, int64(0), "forum posts")
+		}},
+		{"CreateInLockedThread", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+
+			for _, state := range []string{models.LockedThreadState, models.ArchivedThreadState} {
+				_, err := f.repos.Thread.UpdateByID(f.ctx, threadModel.ID, models.ThreadUpdate{State: state})
+				f.noError(err, "set thread state "+state)
+
+				// caller read thread before it was locked
+				_, err = f.repos.Post.CreatePostsInThread(f.ctx, threadModel, models.Posts{
+					{Author: "john", Message: "late"},
+				})
+				f.expectError(err, models.ErrAccessDenied, "create post in "+state+" thread")
+			}
+
+			f.expectPosts(threadModel, nil, post.FlatSort, false, 10, []string{})
+			f.expectEqual(f.getForum("go").Posts, int64(0), "forum posts")
+		}},
+		{"Update", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+			created := f.posts(threadModel, models.Post{Author: "john", Message: "message"})[0]
+
+			updated, err := f.repos.Post.UpdatePostByID(f.ctx, models.Post{ID: created.ID})
+			f.noError(err, "update post without message")
+			f.expectEqual(updated.Message, "message", "not updated message")
+			f.expectEqual(updated.IsEdited, false, "post is edited after empty update")
+
+			updated, err = f.repos.Post.UpdatePostByID(f.ctx, models.Post{ID: created.ID, Message: "message"})
+			f.noError(err, "update post with same message")
+			f.expectEqual(updated.IsEdited, false, "post is edited after same message")
+
+			updated, err = f.repos.Post.UpdatePostByID(f.ctx, models.Post{ID: created.ID, Message: "new"})
+			f.noError(err, "update post")
+			f.expectEqual(updated.Message, "new", "updated message")
+			f.expectEqual(updated.IsEdited, true, "post is edited")
+			f.expectEqual(updated.Author, "john", "updated post author")
+
+			got, err := f.repos.Post.GetPostByID(f.ctx, created.ID)
+			f.noError(err, "get updated post")
+			f.expectEqual(got.IsEdited, true, "stored post is edited")
+		}},
+		{"Revisions", func(f fixture) {
+			f.user("john")
+			f.user("Moderator")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+			created := f.posts(threadModel, models.Post{Author: "john", Message: "v1"})[0]
+
+			revisions, err := f.repos.Post.GetPostRevisions(f.ctx, created.ID)
+			f.noError(err, "get revisions of not edited post")
+			f.expectEqual(len(revisions), 0, "revisions count")
+
+			_, err = f.repos.Post.UpdatePostByID(f.ctx, models.Post{ID: created.ID, Message: "v2"})
+			f.noError(err, "update post by author")
+			_, err = f.repos.Post.UpdatePostByID(f.ctx, models.Post{ID: created.ID, Message: "v2"})
+			f.noError(err, "update post with same message")
+			_, err = f.repos.Post.UpdatePostByID(f.ctx,
+				models.Post{ID: created.ID, Author: "moderator", Message: "v3"})
+			f.noError(err, "update post by other user")
+
+			_, err = f.repos.Post.UpdatePostByID(f.ctx, models.Post{ID: created.ID, Author: "nobody", Message: "v4"})
+			f.expectError(err, models.ErrDoesNotExist, "update post by not existing user")
+
+			revisions, err = f.repos.Post.GetPostRevisions(f.ctx, created.ID)
+			f.noError(err, "get revisions")
+			f.expectEqual(len(revisions), 2, "revisions count")
+
+			for i, expected := range []models.PostRevision{
+				{Post: created.ID, Number: 1, Author: "john", Message: "v1"},
+				{Post: created.ID, Number: 2, Author: "moderator", Message: "v2"},
+			} {
+				revision := revisions[i]
+				f.expectEqual(revision.Post, expected.Post, "revision post")
+				f.expectEqual(revision.Number, expected.Number, "revision number")
+				f.expectEqual(revision.Author, expected.Author, "revision author")
+				f.expectEqual(revision.Message, expected.Message, "revision message")
+				f.expectEqual(revision.Created.IsZero(), false, "revision created is zero")
+
+				got, err := f.repos.Post.GetPostRevision(f.ctx, created.ID, expected.Number)
+				f.noError(err, "get revision")
+				f.expectEqual(got.Message, expected.Message, "got revision message")
+			}
+
+			got, err := f.repos.Post.GetPostByID(f.ctx, created.ID)
+			f.noError(err, "get edited post")
+			f.expectEqual(got.Message, "v3", "edited post message")
+
+			_, err = f.repos.Post.GetPostRevision(f.ctx, created.ID, 3)
+			f.expectError(err, models.ErrDoesNotExist, "get not existing revision")
+		}},
+		{"UpdateNotExisting", func(f fixture) {
+			_, err := f.repos.Post.UpdatePostByID(f.ctx, models.Post{ID: 1 << 40, Message: "new"})
+			f.expectError(err, models.ErrDoesNotExist, "update not existing post")
+		}},
+		{"SoftDelete", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+			root := f.posts(threadModel, models.Post{Author: "john", Message: "root"})[0]
+			f.posts(threadModel, models.Post{Author: "john", Message: "reply", Parent: root.ID})
+
+			for i := 0; i < 2; i++ { // second delete does not change counters
+				deleted, err := f.repos.Post.DeletePostByID(f.ctx, root.ID)
+				f.noError(err, "delete post")
+				f.expectEqual(deleted.IsDeleted, true, "post is deleted")
+				f.expectEqual(deleted.Message, "root", "message of deleted post is kept")
+				f.expectEqual(f.getForum("go").Posts, int64(1), "forum posts after delete")
+			}
+
+			got, err := f.repos.Post.GetPostByID(f.ctx, root.ID)
+			f.noError(err, "get deleted post")
+			f.expectEqual(got.IsDeleted, true, "stored post is deleted")
+
+			_, err = f.repos.Post.UpdatePostByID(f.ctx, models.Post{ID: root.ID, Message: "edited"})
+			f.expectError(err, models.ErrConflict, "update deleted post")
+			revisions, err := f.repos.Post.GetPostRevisions(f.ctx, root.ID)
+			f.noError(err, "get revisions of deleted post")
+			f.expectEqual(len(revisions), 0, "revisions of deleted post")
+
+			// deleted post keeps its place in tree
+			f.expectPosts(threadModel, nil, post.TreeSort, false, 10, []string{"root", "reply"})
+			f.expectPosts(threadModel, nil, post.ParentTreeSort, false, 10, []string{"root", "reply"})
+
+			f.posts(threadModel, models.Post{Author: "john", Message: "late reply", Parent: root.ID})
+			f.expectEqual(f.getForum("go").Posts, int64(2), "forum posts after reply to deleted post")
+
+			restored, err := f.repos.Post.RestorePostByID(f.ctx, root.ID)
+			f.noError(err, "restore post")
+			f.expectEqual(restored.IsDeleted, false, "post is restored")
+			f.expectEqual(f.getForum("go").Posts, int64(3), "forum posts after restore")
+
+			_, err = f.repos.Post.RestorePostByID(f.ctx, root.ID)
+			f.noError(err, "restore not deleted post")
+			f.expectEqual(f.getForum("go").Posts, int64(3), "forum posts after second restore")
+		}},
+		{"SoftDeleteNotExisting", func(f fixture) {
+			_, err := f.repos.Post.DeletePostByID(f.ctx, 1<<40)
+			f.expectError(err, models.ErrDoesNotExist, "delete not existing post")
+
+			_, err = f.repos.Post.RestorePostByID(f.ctx, 1<<40)
+			f.expectError(err, models.ErrDoesNotExist, "restore not existing post")
+		}},
+		{"InvalidSort", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+
+			_, err := f.repos.Post.GetSortedPostsByThreadSlugOrID(f.ctx, threadModel.ID, nil, "random", false, 10)
+			f.expectError(err, models.ErrInvalid, "get posts with unknown sort")
+		}},
+		{"SortedPagination", runSortedPagination},
+		{"Subtree", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+
+			r1 := f.posts(threadModel, models.Post{Author: "john", Message: "r1"})[0]
+			level1 := f.posts(threadModel,
+				models.Post{Author: "john", Message: "c11", Parent: r1.ID},
+				models.Post{Author: "john", Message: "c12", Parent: r1.ID},
+				models.Post{Author: "john", Message: "c13", Parent: r1.ID},
+			)
+			c111 := f.posts(threadModel, models.Post{Author: "john", Message: "c111", Parent: level1[0].ID})[0]
+			c1111 := f.posts(threadModel, models.Post{Author: "john", Message: "c1111", Parent: c111.ID})[0]
+			f.posts(threadModel, models.Post{Author: "john", Message: "r2"})
+
+			messages := func(posts models.Posts, err error) []string {
+				f.t.Helper()
+				f.noError(err, "get posts")
+				result := make([]string, 0, len(posts))
+				for _, postModel := range posts {
+					result = append(result, postModel.Message)
+				}
+				return result
+			}
+			since := func(id int64) *int64 {
+				return &id
+			}
+
+			f.expectStrings(messages(f.repos.Post.GetPostChildren(f.ctx, r1.ID, nil, false, 10)),
+				[]string{"c11", "c12", "c13"}, "children")
+			f.expectStrings(messages(f.repos.Post.GetPostChildren(f.ctx, r1.ID, nil, true, 2)),
+				[]string{"c13", "c12"}, "children desc")
+			f.expectStrings(messages(f.repos.Post.GetPostChildren(f.ctx, r1.ID, since(level1[0].ID), false, 10)),
+				[]string{"c12", "c13"}, "children since")
+			f.expectStrings(messages(f.repos.Post.GetPostChildren(f.ctx, c1111.ID, nil, false, 10)),
+				[]string{}, "children of leaf")
+
+			f.expectStrings(messages(f.repos.Post.GetPostDescendants(f.ctx, r1.ID, 0, nil, false, 10)),
+				[]string{"c11", "c111", "c1111", "c12", "c13"}, "descendants")
+			f.expectStrings(messages(f.repos.Post.GetPostDescendants(f.ctx, r1.ID, 2, nil, false, 10)),
+				[]string{"c11", "c111", "c12", "c13"}, "descendants with depth")
+			f.expectStrings(messages(f.repos.Post.GetPostDescendants(f.ctx, r1.ID, 0, nil, true, 3)),
+				[]string{"c13", "c12", "c1111"}, "descendants desc")
+			f.expectStrings(messages(f.repos.Post.GetPostDescendants(f.ctx, r1.ID, 0, since(c111.ID), false, 2)),
+				[]string{"c1111", "c12"}, "descendants since")
+			f.expectStrings(messages(f.repos.Post.GetPostDescendants(f.ctx, level1[0].ID, 0, nil, false, 10)),
+				[]string{"c111", "c1111"}, "descendants of child")
+
+			f.expectStrings(messages(f.repos.Post.GetPostAncestors(f.ctx, c1111.ID)),
+				[]string{"r1", "c11", "c111"}, "ancestors")
+			f.expectStrings(messages(f.repos.Post.GetPostAncestors(f.ctx, r1.ID)),
+				[]string{}, "ancestors of root")
+
+			_, err := f.repos.Post.GetPostChildren(f.ctx, 1<<60, nil, false, 10)
+			f.expectError(err, models.ErrDoesNotExist, "children of not existing post")
+			_, err = f.repos.Post.GetPostDescendants(f.ctx, 1<<60, 0, nil, false, 10)
+			f.expectError(err, models.ErrDoesNotExist, "descendants of not existing post")
+			_, err = f.repos.Post.GetPostAncestors(f.ctx, 1<<60)
+			f.expectError(err, models.ErrDoesNotExist, "ancestors of not existing post")
+		}},
+		{"Split", func(f fixture) {
+			f.user("john")
+			f.user("jack")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+
+			roots := f.posts(threadModel, models.Post{Author: "john", Message: "r1"})
+			children := f.posts(threadModel,
+				models.Post{Author: "john", Message: "c11", Parent: roots[0].ID},
+				models.Post{Author: "jack", Message: "c12", Parent: roots[0].ID},
+			)
+			f.posts(threadModel, models.Post{Author: "john", Message: "c121", Parent: children[1].ID})
+
+			newThread, err := f.repos.Post.SplitPostByID(f.ctx, children[1].ID,
+				models.Thread{Title: "offtopic", Slug: "offtopic"}, false)
+			f.noError(err, "split post")
+			f.expectEqual(newThread.Author, "jack", "new thread author")
+			f.expectEqual(newThread.Forum, "go", "new thread forum")
+			f.expectEqual(newThread.Message, "c12", "new thread message")
+			f.expectEqual(newThread.State, models.OpenThreadState, "new thread state")
+
+			got, err := f.repos.Thread.GetBySlug(f.ctx, "offtopic")
+			f.noError(err, "get new thread")
+			f.expectEqual(got.ID, newThread.ID, "new thread id")
+
+			f.expectPosts(threadModel, nil, post.TreeSort, false, 10, []string{"r1", "c11"})
+			f.expectPosts(newThread, nil, post.TreeSort, false, 10, []string{"c12", "c121"})
+			f.expectPosts(newThread, nil, post.ParentTreeSort, false, 1, []string{"c12", "c121"})
+
+			splitRoot, err := f.repos.Post.GetPostByID(f.ctx, children[1].ID)
+			f.noError(err, "get split post")
+			f.expectEqual(splitRoot.Parent, int64(0), "split post parent")
+			f.expectEqual(splitRoot.Thread, newThread.ID, "split post thread")
+
+			// new replies are built on rewritten paths
+			f.posts(newThread, models.Post{Author: "john", Message: "c122", Parent: children[1].ID})
+			f.expectPosts(newThread, nil, post.TreeSort, false, 10, []string{"c12", "c121", "c122"})
+
+			forumModel := f.getForum("go")
+			f.expectEqual(forumModel.Threads, int64(2), "forum threads")
+			f.expectEqual(forumModel.Posts, int64(5), "forum posts")
+		}},
+		{"SplitWithStub", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+
+			roots := f.posts(threadModel,
+				models.Post{Author: "john", Message: "r1"},
+				models.Post{Author: "john", Message: "r2"},
+			)
+			f.posts(threadModel, models.Post{Author: "john", Message: "c11", Parent: roots[0].ID})
+
+			newThread, err := f.repos.Post.SplitPostByID(f.ctx, roots[0].ID, models.Thread{Title: "offtopic"}, true)
+			f.noError(err, "split post")
+
+			stub := fmt.Sprintf(models.MovedPostMessageFormat, newThread.ID)
+			f.expectPosts(threadModel, nil, post.TreeSort, false, 10, []string{"r2", stub})
+			f.expectPosts(newThread, nil, post.TreeSort, false, 10, []string{"r1", "c11"})
+			f.expectEqual(f.getForum("go").Posts, int64(4), "forum posts")
+		}},
+		{"SplitErrors", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "generics", testTime)
+			posts := f.posts(threadModel, models.Post{Author: "john", Message: "r1"})
+
+			_, err := f.repos.Post.SplitPostByID(f.ctx, 1<<60, models.Thread{Title: "t"}, false)
+			f.expectError(err, models.ErrDoesNotExist, "split not existing post")
+
+			_, err = f.repos.Post.SplitPostByID(f.ctx, posts[0].ID, models.Thread{Title: "t", Slug: "GENERICS"}, true)
+			f.expectError(err, models.ErrConflict, "split post into thread with existing slug")
+
+			f.expectPosts(threadModel, nil, post.FlatSort, false, 10, []string{"r1"})
+			f.expectEqual(f.getForum("go").Threads, int64(1), "forum threads")
+		}},
+	})
+}
+
+// runSortedPagination checks sorting on the tree, ids are increasing in order of creation:
+//
+//	r1
+//	├── c11
+//	│   └── c111
+//	└── c12
+//	r2
+//	└── c21
+//	r3
+func runSortedPagination(f fixture) {
+	f.user("john")
+	f.forum("go", "john")
+	threadModel := f.thread("go", "john", "generics", testTime)
+	otherThread := f.thread("go", "john", "other", testTime)
+
+	ids := make(map[string]int64)
+	create := func(posts ...models.Post) {
+		for _, created := range f.posts(threadModel, posts...) {
+			ids[created.Message] = created.ID
+		}
+	}
+
+	create(models.Post{Author: "john", Message: "r1"}, models.Post{Author: "john", Message: "r2"})
+	f.posts(otherThread, models.Post{Author: "john", Message: "other"})
+	create(models.Post{Author: "john", Message: "c11", Parent: ids["r1"]})
+	create(models.Post{Author: "john", Message: "c12", Parent: ids["r1"]},
+		models.Post{Author: "john", Message: "c21", Parent: ids["r2"]})
+	create(models.Post{Author: "john", Message: "c111", Parent: ids["c11"]})
+	create(models.Post{Author: "john", Message: "r3"})
+
+	since := func(message string) *int64 {
+		id := ids[message]
+		return &id
+	}
+
+	cases := []struct {
+		sort     post.PostsSortType
+		since    *int64
+		desc     bool
+		limit    int64
+		expected []string
+	}{
+		{post.FlatSort, nil, false, 10, []string{"r1", "r2", "c11", "c12", "c21", "c111", "r3"}},
+		{post.FlatSort, nil, true, 3, []string{"r3", "c111", "c21"}},
+		{post.FlatSort, since("c11"), false, 2, []string{"c12", "c21"}},
+		{post.FlatSort, since("c11"), true, 10, []string{"r2", "r1"}},
+
+		{post.TreeSort, nil, false, 10, []string{"r1", "c11", "c111", "c12", "r2", "c21", "r3"}},
+		{post.TreeSort, nil, true, 10, []string{"r3", "c21", "r2", "c12", "c111", "c11", "r1"}},
+		{post.TreeSort, nil, false, 2, []string{"r1", "c11"}},
+		{post.TreeSort, since("c111"), false, 3, []string{"c12", "r2", "c21"}},
+		{post.TreeSort, since("c12"), true, 3, []string{"c111", "c11", "r1"}},
+		{post.TreeSort, since("r3"), false, 10, []string{}},
+
+		{post.ParentTreeSort, nil, false, 10, []string{"r1", "c11", "c111", "c12", "r2", "c21", "r3"}},
+		{post.ParentTreeSort, nil, false, 2, []string{"r1", "c11", "c111", "c12", "r2", "c21"}},
+		{post.ParentTreeSort, nil, true, 2, []string{"r3", "r2", "c21"}},
+		{post.ParentTreeSort, nil, true, 3, []string{"r3", "r2", "c21", "r1", "c11", "c111", "c12"}},
+		{post.ParentTreeSort, since("c21"), false, 1, []string{"r3"}},
+		{post.ParentTreeSort, since("r3"), true, 1, []string{"r2", "c21"}},
+		{post.ParentTreeSort, since("c111"), true, 10, []string{}},
+	}
+
+	for _, tc := range cases {
+		f.expectPosts(threadModel, tc.since, tc.sort, tc.desc, tc.limit, tc.expected)
+	}
+}
+
+func (f fixture) expectPosts(threadModel models.Thread, sincePostID *int64,
+	sort post.PostsSortType, desc bool, limit int64, expected []string) {
+
+	f.t.Helper()
+
+	sinceDescription := "<nil>"
+	if sincePostID != nil {
+		sinceDescription = fmt.Sprint(*sincePostID)
+	}
+	description := fmt.Sprintf("posts sort=%s since=%s desc=%t limit=%d", sort, sinceDescription, desc, limit)
+
+	posts, err := f.repos.Post.GetSortedPostsByThreadSlugOrID(f.ctx, threadModel.ID, sincePostID, sort, desc, limit)
+	f.noError(err, "get "+description)
+
+	messages := make([]string, 0, len(posts))
+	for _, postModel := range posts {
+		messages = append(messages, postModel.Message)
+	}
+	f.expectStrings(messages, expected, description)
+}
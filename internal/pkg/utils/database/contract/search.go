@@ -0,0 +1,149 @@
+package contract
+
+import (
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/search"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RunSearch checks search.Repository contract, ranks are backend specific, so only their order is checked
+func RunSearch(t *testing.T, newRepositories Factory) {
+	runCases(t, newRepositories, []testCase{
+		{"Filters", func(f fixture) {
+			f.user("john")
+			f.user("jack")
+			f.forum("go", "john")
+			f.forum("rust", "john")
+
+			goThread := f.thread("go", "john", "go-generics", testTime)
+			rustThread := f.thread("rust", "jack", "rust-generics", testTime.Add(time.Hour))
+			f.posts(goThread,
+				models.Post{Author: "jack", Message: "generics are finally here"},
+				models.Post{Author: "john", Message: "nothing interesting"},
+			)
+			deleted := f.posts(rustThread, models.Post{Author: "jack", Message: "generics with traits"})[0]
+			f.posts(rustThread, models.Post{Author: "john", Message: "traits are generics too"})
+
+			_, err := f.repos.Post.DeletePostByID(f.ctx, deleted.ID)
+			f.noError(err, "delete post")
+
+			until := testTime.Add(time.Minute)
+
+			cases := []struct {
+				name     string
+				query    search.Query
+				expected []string
+			}{
+				{"all", search.Query{Text: "GENERICS"}, []string{
+					"post:generics are finally here", "post:traits are generics too",
+					"thread:Thread go-generics", "thread:Thread rust-generics",
+				}},
+				{"all words", search.Query{Text: "generics traits"}, []string{
+					"post:traits are generics too",
+				}},
+				{"forum", search.Query{Text: "generics", Forum: "GO"}, []string{
+					"post:generics are finally here", "thread:Thread go-generics",
+				}},
+				{"author", search.Query{Text: "generics", Author: "Jack"}, []string{
+					"post:generics are finally here", "thread:Thread rust-generics",
+				}},
+				{"until", search.Query{Text: "generics", Until: &until}, []string{
+					"thread:Thread go-generics",
+				}},
+				{"nothing", search.Query{Text: "python"}, []string{}},
+			}
+
+			for _, tc := range cases {
+				tc.query.Limit = 10
+				results, err := f.repos.Search.Search(f.ctx, tc.query)
+				f.noError(err, "search "+tc.name)
+				f.expectStrings(sortedSearchResults(results), tc.expected, "search "+tc.name)
+			}
+		}},
+		{"SnippetIsHighlighted", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "", testTime)
+			f.posts(threadModel, models.Post{Author: "john", Message: "channels and goroutines"})
+
+			results, err := f.repos.Search.Search(f.ctx, search.Query{Text: "goroutines", Limit: 10})
+			f.noError(err, "search")
+			f.expectEqual(len(results), 1, "results count")
+			f.expectEqual(strings.Contains(results[0].Snippet, "<b>goroutines</b>"), true,
+				"snippet "+results[0].Snippet+" has highlighted word")
+		}},
+		{"SnippetIsEscaped", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "", testTime)
+			f.posts(threadModel, models.Post{
+				Author:  "john",
+				Message: `goroutines <script>alert("x")</script> & channels`,
+			})
+
+			results, err := f.repos.Search.Search(f.ctx, search.Query{Text: "goroutines", Limit: 10})
+			f.noError(err, "search")
+			f.expectEqual(len(results), 1, "results count")
+			f.expectEqual(strings.Contains(results[0].Snippet, "<b>goroutines</b>"), true,
+				"snippet "+results[0].Snippet+" has highlighted word")
+			f.expectEqual(strings.Contains(results[0].Snippet, "<script>"), false,
+				"snippet "+results[0].Snippet+" has script tag")
+			f.expectEqual(strings.Contains(results[0].Snippet, "&lt;script&gt;"), true,
+				"snippet "+results[0].Snippet+" has escaped script tag")
+		}},
+		{"CursorPagination", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			threadModel := f.thread("go", "john", "", testTime)
+
+			for i := 0; i < 7; i++ {
+				f.posts(threadModel, models.Post{
+					Author:  "john",
+					Message: strings.Repeat("word ", i+1) + strings.Repeat("other ", 7-i),
+				})
+			}
+
+			all, err := f.repos.Search.Search(f.ctx, search.Query{Text: "word", Limit: 100})
+			f.noError(err, "search all")
+			f.expectEqual(len(all), 7, "results count")
+
+			var paged models.SearchResults
+			query := search.Query{Text: "word", Limit: 3}
+			for page := 0; page < 5; page++ {
+				results, err := f.repos.Search.Search(f.ctx, query)
+				f.noError(err, "search page")
+				if len(results) == 0 {
+					break
+				}
+				paged = append(paged, results...)
+
+				last := results[len(results)-1]
+				query.After = &search.Cursor{Rank: last.Rank, Type: last.Type, ID: last.ID}
+			}
+
+			f.expectEqual(len(paged), len(all), "paged results count")
+			for i := range all {
+				f.expectEqual(paged[i].ID, all[i].ID, "paged result id")
+				if i > 0 {
+					f.expectEqual(all[i].Rank <= all[i-1].Rank, true, "results are ordered by rank")
+				}
+			}
+		}},
+	})
+}
+
+func sortedSearchResults(results models.SearchResults) []string {
+	keys := make([]string, 0, len(results))
+	for _, result := range results {
+		text := result.Title
+		if result.Type == models.PostSearchResult {
+			text = strings.NewReplacer("<b>", "", "</b>", "").Replace(result.Snippet)
+		}
+		keys = append(keys, result.Type+":"+text)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,431 @@
+package contract
+
+import (
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/post"
+	"testing"
+	"time"
+)
+
+// RunThread checks thread.Repository contract
+func RunThread(t *testing.T, newRepositories Factory) {
+	runCases(t, newRepositories, []testCase{
+		{"CreateAndGet", func(f fixture) {
+			f.user("John")
+			f.forum("Go", "john")
+
+			created := f.thread("go", "JOHN", "Generics", testTime)
+			f.expectEqual(created.Author, "John", "thread author")
+			f.expectEqual(created.Forum, "Go", "thread forum")
+			f.expectEqual(created.Created.Equal(testTime), true, "thread created equals "+testTime.String())
+
+			got, err := f.repos.Thread.GetByID(f.ctx, created.ID)
+			f.noError(err, "get thread by id")
+			f.expectEqual(got.Slug, "Generics", "thread slug")
+			f.expectEqual(got.Title, created.Title, "thread title")
+			f.expectEqual(got.Message, created.Message, "thread message")
+
+			got, err = f.repos.Thread.GetBySlug(f.ctx, "GENERICS")
+			f.noError(err, "get thread by slug")
+			f.expectEqual(got.ID, created.ID, "thread id")
+
+			f.expectEqual(f.getForum("go").Threads, int64(1), "forum threads")
+		}},
+		{"CreateWithoutSlug", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+
+			first := f.thread("go", "john", "", testTime)
+			second := f.thread("go", "john", "", testTime)
+			f.expectEqual(first.ID < second.ID, true, "ids are increasing")
+		}},
+		{"GetNotExisting", func(f fixture) {
+			_, err := f.repos.Thread.GetByID(f.ctx, 1<<30)
+			f.expectError(err, models.ErrDoesNotExist, "get not existing thread by id")
+
+			_, err = f.repos.Thread.GetBySlug(f.ctx, "nothing")
+			f.expectError(err, models.ErrDoesNotExist, "get not existing thread by slug")
+		}},
+		{"CreateWithBadForeign", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+
+			_, err := f.repos.Thread.Create(f.ctx, models.Thread{Title: "t", Author: "nobody", Forum: "go"})
+			f.expectError(err, models.ErrBadForeign, "create thread with not existing author")
+
+			_, err = f.repos.Thread.Create(f.ctx, models.Thread{Title: "t", Author: "john", Forum: "nothing"})
+			f.expectError(err, models.ErrBadForeign, "create thread in not existing forum")
+		}},
+		{"CreateWithSameSlug", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			f.thread("go", "john", "generics", testTime)
+
+			_, err := f.repos.Thread.Create(f.ctx, models.Thread{
+				Title:  "t",
+				Author: "john",
+				Forum:  "go",
+				Slug:   "GENERICS",
+			})
+			f.expectError(err, models.ErrConflict, "create thread with same slug")
+			f.expectEqual(f.getForum("go").Threads, int64(1), "forum threads")
+		}},
+		{"UpdatePartially", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			created := f.thread("go", "john", "generics", testTime)
+
+			updated, err := f.repos.Thread.UpdateByID(f.ctx, created.ID, models.ThreadUpdate{Title: "new title"})
+			f.noError(err, "update thread by id")
+			f.expectEqual(updated.Title, "new title", "updated title")
+			f.expectEqual(updated.Message, created.Message, "not updated message")
+
+			updated, err = f.repos.Thread.UpdateBySlug(f.ctx, "Generics", models.ThreadUpdate{Message: "new message"})
+			f.noError(err, "update thread by slug")
+			f.expectEqual(updated.ID, created.ID, "updated thread id")
+			f.expectEqual(updated.Title, "new title", "not updated title")
+			f.expectEqual(updated.Message, "new message", "updated message")
+			f.expectEqual(updated.State, models.OpenThreadState, "not updated state")
+		}},
+		{"UpdateStateAndPinned", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			created := f.thread("go", "john", "generics", testTime)
+			f.expectEqual(created.State, models.OpenThreadState, "created thread state")
+			f.expectEqual(created.IsPinned, false, "created thread pinned")
+
+			pinned := true
+			updated, err := f.repos.Thread.UpdateByID(f.ctx, created.ID,
+				models.ThreadUpdate{State: models.LockedThreadState, Pinned: &pinned})
+			f.noError(err, "lock and pin thread")
+			f.expectEqual(updated.State, models.LockedThreadState, "updated state")
+			f.expectEqual(updated.IsPinned, true, "updated pinned")
+			f.expectEqual(updated.Title, created.Title, "not updated title")
+
+			pinned = false
+			updated, err = f.repos.Thread.UpdateBySlug(f.ctx, "generics", models.ThreadUpdate{Pinned: &pinned})
+			f.noError(err, "unpin thread")
+			f.expectEqual(updated.State, models.LockedThreadState, "not updated state")
+			f.expectEqual(updated.IsPinned, false, "unpinned")
+
+			got, err := f.repos.Thread.GetByID(f.ctx, created.ID)
+			f.noError(err, "get updated thread")
+			f.expectEqual(got.State, models.LockedThreadState, "stored state")
+		}},
+		{"UpdateNotExisting", func(f fixture) {
+			_, err := f.repos.Thread.UpdateByID(f.ctx, 1<<30, models.ThreadUpdate{Title: "title"})
+			f.expectError(err, models.ErrDoesNotExist, "update not existing thread by id")
+
+			_, err = f.repos.Thread.UpdateBySlug(f.ctx, "nothing", models.ThreadUpdate{Title: "title"})
+			f.expectError(err, models.ErrDoesNotExist, "update not existing thread by slug")
+		}},
+		{"VoteRecasting", func(f fixture) {
+			f.user("john")
+			f.user("jack")
+			f.forum("go", "john")
+			created := f.thread("go", "john", "generics", testTime)
+
+			votes := []struct {
+				bySlug   bool
+				nickname string
+				voice    int16
+				expected int32
+			}{
+				{false, "john", 1, 1},
+				{false, "john", 1, 1},  // same vote does not change anything
+				{true, "JOHN", -1, -1}, // vote is recast ignoring nickname case
+				{true, "jack", -1, -2}, // other user votes
+				{false, "jack", 1, 0},  // recast to opposite voice
+				{true, "john", 1, 2},   // both recast
+			}
+
+			for i, vote := range votes {
+				var updated models.Thread
+				var err error
+				if vote.bySlug {
+					updated, err = f.repos.Thread.VoteBySlug(f.ctx, "Generics",
+						models.Vote{Nickname: vote.nickname, Voice: vote.voice})
+				} else {
+					updated, err = f.repos.Thread.VoteByID(f.ctx, created.ID,
+						models.Vote{Nickname: vote.nickname, Voice: vote.voice})
+				}
+				f.noError(err, "vote")
+				f.expectEqual(updated.ID, created.ID, "voted thread id")
+				f.expectEqual(updated.Votes, vote.expected, fmt.Sprintf("votes after vote #%d", i))
+			}
+
+			got, err := f.repos.Thread.GetByID(f.ctx, created.ID)
+			f.noError(err, "get voted thread")
+			f.expectEqual(got.Votes, int32(2), "stored votes")
+		}},
+		{"VoteWithBadForeign", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			created := f.thread("go", "john", "generics", testTime)
+
+			_, err := f.repos.Thread.VoteByID(f.ctx, 1<<30, models.Vote{Nickname: "john", Voice: 1})
+			f.expectError(err, models.ErrDoesNotExist, "vote for not existing thread by id")
+
+			_, err = f.repos.Thread.VoteBySlug(f.ctx, "nothing", models.Vote{Nickname: "john", Voice: 1})
+			f.expectError(err, models.ErrDoesNotExist, "vote for not existing thread by slug")
+
+			_, err = f.repos.Thread.VoteByID(f.ctx, created.ID, models.Vote{Nickname: "nobody", Voice: 1})
+			f.expectError(err, models.ErrDoesNotExist, "vote by not existing user by id")
+
+			_, err = f.repos.Thread.VoteBySlug(f.ctx, "generics", models.Vote{Nickname: "nobody", Voice: 1})
+			f.expectError(err, models.ErrDoesNotExist, "vote by not existing user by slug")
+		}},
+		{"VoteInArchivedThread", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			created := f.thread("go", "john", "generics", testTime)
+
+			_, err := f.repos.Thread.VoteByID(f.ctx, created.ID, models.Vote{Nickname: "john", Voice: 1})
+			f.noError(err, "vote for open thread")
+
+			_, err = f.repos.Thread.UpdateByID(f.ctx, created.ID, models.ThreadUpdate{State: models.ArchivedThreadState})
+			f.noError(err, "archive thread")
+
+			_, err = f.repos.Thread.VoteByID(f.ctx, created.ID, models.Vote{Nickname: "john", Voice: -1})
+			f.expectError(err, models.ErrAccessDenied, "vote for archived thread by id")
+
+			_, err = f.repos.Thread.VoteBySlug(f.ctx, "generics", models.Vote{Nickname: "john", Voice: -1})
+			f.expectError(err, models.ErrAccessDenied, "vote for archived thread by slug")
+
+			got, err := f.repos.Thread.GetByID(f.ctx, created.ID)
+			f.noError(err, "get archived thread")
+			f.expectEqual(got.Votes, int32(1), "votes of archived thread")
+		}},
+		{"MoveToOtherForum", func(f fixture) {
+			f.user("john")
+			f.user("jack")
+			f.user("jane")
+			f.forum("go", "john")
+			f.forum("rust", "john")
+			moved := f.thread("go", "jack", "generics", testTime)
+			stayed := f.thread("go", "john", "modules", testTime)
+			posts := f.posts(moved,
+				models.Post{Author: "jane", Message: "root"},
+				models.Post{Author: "john", Message: "deleted"},
+			)
+			f.posts(moved, models.Post{Author: "jane", Message: "child", Parent: posts[0].ID})
+			f.posts(stayed, models.Post{Author: "jane", Message: "other"})
+			_, err := f.repos.Post.DeletePostByID(f.ctx, posts[1].ID)
+			f.noError(err, "delete post")
+
+			updated, err := f.repos.Thread.MoveByID(f.ctx, moved.ID, "RUST")
+			f.noError(err, "move thread")
+			f.expectEqual(updated.Forum, "rust", "moved thread forum")
+
+			got, err := f.repos.Post.GetPostByID(f.ctx, posts[0].ID)
+			f.noError(err, "get moved post")
+			f.expectEqual(got.Forum, "rust", "moved post forum")
+
+			goForum, rustForum := f.getForum("go"), f.getForum("rust")
+			f.expectEqual(goForum.Threads, int64(1), "source forum threads")
+			f.expectEqual(goForum.Posts, int64(1), "source forum posts")
+			f.expectEqual(rustForum.Threads, int64(1), "target forum threads")
+			f.expectEqual(rustForum.Posts, int64(2), "target forum posts")
+
+			users, err := f.repos.Forum.GetForumUsersBySlug(f.ctx, "go", "", false, 10)
+			f.noError(err, "get source forum users")
+			f.expectStrings(nicknames(users), []string{"jane", "john"}, "source forum users")
+
+			users, err = f.repos.Forum.GetForumUsersBySlug(f.ctx, "rust", "", false, 10)
+			f.noError(err, "get target forum users")
+			f.expectStrings(nicknames(users), []string{"jack", "jane", "john"}, "target forum users")
+
+			f.expectPosts(updated, nil, post.TreeSort, false, 10, []string{"root", "child", "deleted"})
+		}},
+		{"MoveErrors", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			created := f.thread("go", "john", "generics", testTime)
+
+			_, err := f.repos.Thread.MoveByID(f.ctx, 1<<30, "go")
+			f.expectError(err, models.ErrDoesNotExist, "move not existing thread")
+
+			_, err = f.repos.Thread.MoveByID(f.ctx, created.ID, "nothing")
+			f.expectError(err, models.ErrBadForeign, "move thread to not existing forum")
+
+			updated, err := f.repos.Thread.MoveByID(f.ctx, created.ID, "GO")
+			f.noError(err, "move thread to same forum")
+			f.expectEqual(updated.Forum, "go", "thread forum")
+			f.expectEqual(f.getForum("go").Threads, int64(1), "forum threads")
+		}},
+		{"MergeThreads", func(f fixture) {
+			f.user("john")
+			f.user("jack")
+			f.forum("go", "john")
+			f.forum("rust", "john")
+			target := f.thread("go", "john", "generics", testTime)
+			source := f.thread("rust", "jack", "traits", testTime)
+
+			targetPosts := f.posts(target, models.Post{Author: "john", Message: "t1"})
+			sourcePosts := f.posts(source, models.Post{Author: "jack", Message: "s1"})
+			f.posts(source, models.Post{Author: "jack", Message: "s11", Parent: sourcePosts[0].ID})
+			f.posts(target, models.Post{Author: "john", Message: "t11", Parent: targetPosts[0].ID})
+			_, err := f.repos.Thread.VoteByID(f.ctx, source.ID, models.Vote{Nickname: "john", Voice: 1})
+			f.noError(err, "vote for source thread")
+
+			merged, err := f.repos.Thread.MergeByID(f.ctx, source.ID, target.ID)
+			f.noError(err, "merge threads")
+			f.expectEqual(merged.ID, target.ID, "merged thread id")
+
+			_, err = f.repos.Thread.GetByID(f.ctx, source.ID)
+			f.expectError(err, models.ErrDoesNotExist, "get merged source thread")
+
+			f.expectPosts(merged, nil, post.TreeSort, false, 10, []string{"t1", "t11", "s1", "s11"})
+			f.expectPosts(merged, nil, post.FlatSort, false, 10, []string{"t1", "s1", "s11", "t11"})
+
+			// replies to merged posts are created in target thread
+			f.posts(merged, models.Post{Author: "john", Message: "s12", Parent: sourcePosts[0].ID})
+			f.expectPosts(merged, nil, post.ParentTreeSort, false, 1, []string{"t1", "t11"})
+			f.expectPosts(merged, nil, post.ParentTreeSort, true, 1, []string{"s1", "s11", "s12"})
+
+			goForum, rustForum := f.getForum("go"), f.getForum("rust")
+			f.expectEqual(goForum.Threads, int64(1), "target forum threads")
+			f.expectEqual(goForum.Posts, int64(5), "target forum posts")
+			f.expectEqual(rustForum.Threads, int64(0), "source forum threads")
+			f.expectEqual(rustForum.Posts, int64(0), "source forum posts")
+
+			users, err := f.repos.Forum.GetForumUsersBySlug(f.ctx, "rust", "", false, 10)
+			f.noError(err, "get source forum users")
+			f.expectEqual(len(users), 0, "source forum users")
+
+			users, err = f.repos.Forum.GetForumUsersBySlug(f.ctx, "go", "", false, 10)
+			f.noError(err, "get target forum users")
+			f.expectStrings(nicknames(users), []string{"jack", "john"}, "target forum users")
+		}},
+		{"MergeErrors", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			created := f.thread("go", "john", "generics", testTime)
+
+			_, err := f.repos.Thread.MergeByID(f.ctx, created.ID, 1<<30)
+			f.expectError(err, models.ErrDoesNotExist, "merge into not existing thread")
+
+			_, err = f.repos.Thread.MergeByID(f.ctx, 1<<30, created.ID)
+			f.expectError(err, models.ErrDoesNotExist, "merge not existing thread")
+
+			_, err = f.repos.Thread.MergeByID(f.ctx, created.ID, created.ID)
+			f.expectError(err, models.ErrConflict, "merge thread into itself")
+		}},
+		{"ThreadsOfNotExistingForum", func(f fixture) {
+			_, err := f.repos.Thread.GetThreadsByForumSlug(f.ctx, "nothing", nil, false, 10)
+			f.expectError(err, models.ErrDoesNotExist, "get threads of not existing forum")
+		}},
+		{"ThreadsOfEmptyForum", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+
+			threads, err := f.repos.Thread.GetThreadsByForumSlug(f.ctx, "go", nil, false, 10)
+			f.noError(err, "get threads of empty forum")
+			f.expectEqual(len(threads), 0, "threads count")
+		}},
+		{"ThreadsPagination", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+			f.forum("other", "john")
+
+			for i, slug := range []string{"t2", "t0", "t3", "t1"} {
+				created := testTime.Add(time.Duration(slug[1]-'0') * time.Hour)
+				f.thread("go", "john", slug, created)
+				f.thread("other", "john", fmt.Sprintf("other%d", i), created)
+			}
+
+			since := testTime.Add(time.Hour)
+
+			cases := []struct {
+				since    *time.Time
+				desc     bool
+				limit    int32
+				expected []string
+			}{
+				{nil, false, 10, []string{"t0", "t1", "t2", "t3"}},
+				{nil, true, 10, []string{"t3", "t2", "t1", "t0"}},
+				{nil, false, 2, []string{"t0", "t1"}},
+				{nil, true, 1, []string{"t3"}},
+				{&since, false, 10, []string{"t1", "t2", "t3"}}, // since is inclusive
+				{&since, true, 10, []string{"t1", "t0"}},
+				{&since, false, 1, []string{"t1"}},
+			}
+
+			for _, tc := range cases {
+				threads, err := f.repos.Thread.GetThreadsByForumSlug(f.ctx, "GO", tc.since, tc.desc, tc.limit)
+				f.noError(err, "get threads")
+				f.expectStrings(threadSlugs(threads), tc.expected, "threads")
+			}
+		}},
+		{"PinnedThreadsFirst", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+
+			for _, slug := range []string{"t0", "t1", "t2", "t3"} {
+				f.thread("go", "john", slug, testTime.Add(time.Duration(slug[1]-'0')*time.Hour))
+			}
+
+			pinned := true
+			_, err := f.repos.Thread.UpdateBySlug(f.ctx, "t2", models.ThreadUpdate{Pinned: &pinned})
+			f.noError(err, "pin thread")
+
+			threads, err := f.repos.Thread.GetThreadsByForumSlug(f.ctx, "go", nil, false, 10)
+			f.noError(err, "get threads")
+			f.expectStrings(threadSlugs(threads), []string{"t2", "t0", "t1", "t3"}, "threads")
+
+			threads, err = f.repos.Thread.GetThreadsByForumSlug(f.ctx, "go", nil, true, 10)
+			f.noError(err, "get threads desc")
+			f.expectStrings(threadSlugs(threads), []string{"t2", "t3", "t1", "t0"}, "threads desc")
+		}},
+		{"ThreadsAfterWithSameCreated", func(f fixture) {
+			f.user("john")
+			f.forum("go", "john")
+
+			// t1 and t2 share created time, id keeps their order stable between pages
+			for _, slug := range []string{"t0", "t1", "t2", "t3"} {
+				created := testTime
+				if slug == "t3" {
+					created = testTime.Add(time.Hour)
+				}
+				f.thread("go", "john", slug, created)
+			}
+
+			pinned := true
+			_, err := f.repos.Thread.UpdateBySlug(f.ctx, "t0", models.ThreadUpdate{Pinned: &pinned})
+			f.noError(err, "pin thread")
+
+			for desc, expected := range map[bool][]string{
+				false: {"t0", "t1", "t2", "t3"},
+				true:  {"t0", "t3", "t2", "t1"},
+			} {
+				threads, err := f.repos.Thread.GetThreadsByForumSlug(f.ctx, "go", nil, desc, 1)
+				f.noError(err, "get first page")
+
+				listed := threadSlugs(threads)
+				for len(threads) != 0 {
+					threads, err = f.repos.Thread.GetThreadsByForumSlugAfter(f.ctx, "GO", threads[0], desc, 1)
+					f.noError(err, "get next page")
+					listed = append(listed, threadSlugs(threads)...)
+				}
+
+				f.expectStrings(listed, expected, fmt.Sprintf("threads pages desc=%t", desc))
+			}
+		}},
+		{"ThreadsAfterOfNotExistingForum", func(f fixture) {
+			_, err := f.repos.Thread.GetThreadsByForumSlugAfter(f.ctx, "nothing", models.Thread{}, false, 10)
+			f.expectError(err, models.ErrDoesNotExist, "get threads of not existing forum")
+		}},
+	})
+}
+
+// testTime has milliseconds precision as TIMESTAMP(3) column
+var testTime = time.Date(2020, time.June, 1, 12, 0, 0, 123e6, time.UTC)
+
+func threadSlugs(threads models.Threads) []string {
+	slugs := make([]string, 0, len(threads))
+	for _, threadModel := range threads {
+		slugs = append(slugs, threadModel.Slug)
+	}
+	return slugs
+}
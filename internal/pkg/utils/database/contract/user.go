@@ -0,0 +1,102 @@
+package contract
+
+import (
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"sort"
+	"testing"
+)
+
+// RunUser checks user.Repository contract
+func RunUser(t *testing.T, newRepositories Factory) {
+	runCases(t, newRepositories, []testCase{
+		{"CreateAndGetIgnoringCase", func(f fixture) {
+			created := f.user("John.Doe")
+
+			got, err := f.repos.User.GetByNickname(f.ctx, "john.DOE")
+			f.noError(err, "get user")
+			f.expectEqual(got, created, "user")
+		}},
+		{"GetNotExisting", func(f fixture) {
+			_, err := f.repos.User.GetByNickname(f.ctx, "nobody")
+			f.expectError(err, models.ErrDoesNotExist, "get not existing user")
+		}},
+		{"CreateWithSameNickname", func(f fixture) {
+			f.user("john")
+
+			err := f.repos.User.Create(f.ctx, models.User{Nickname: "JOHN", Email: "other@example.com"})
+			f.expectError(err, models.ErrAlreadyExist, "create user with same nickname")
+		}},
+		{"CreateWithSameEmail", func(f fixture) {
+			f.user("john")
+
+			err := f.repos.User.Create(f.ctx, models.User{Nickname: "jack", Email: "JOHN@example.com"})
+			f.expectError(err, models.ErrAlreadyExist, "create user with same email")
+		}},
+		{"GetWithSameNicknameAndEmail", func(f fixture) {
+			f.user("john")
+			f.user("jack")
+			f.user("jane")
+
+			users, err := f.repos.User.GetWithSameNicknameAndEmail(f.ctx, "JOHN", "Jack@Example.com")
+			f.noError(err, "get users with same nickname and email")
+			f.expectStrings(sortedNicknames(users), []string{"jack", "john"}, "conflicting users")
+
+			users, err = f.repos.User.GetWithSameNicknameAndEmail(f.ctx, "nobody", "nobody@example.com")
+			f.noError(err, "get users with other nickname and email")
+			f.expectEqual(len(users), 0, "conflicting users count")
+		}},
+		{"UpdatePartially", func(f fixture) {
+			created := f.user("john")
+
+			updated, err := f.repos.User.UpdateByNickname(f.ctx, models.User{Nickname: "JOHN", About: "new about"})
+			f.noError(err, "update user")
+
+			expected := created
+			expected.About = "new about"
+			f.expectEqual(updated, expected, "updated user")
+
+			got, err := f.repos.User.GetByNickname(f.ctx, "john")
+			f.noError(err, "get updated user")
+			f.expectEqual(got, expected, "stored user")
+		}},
+		{"UpdateEmailAndReuseOldOne", func(f fixture) {
+			f.user("john")
+			f.user("jack")
+
+			_, err := f.repos.User.UpdateByNickname(f.ctx, models.User{Nickname: "john", Email: "new@example.com"})
+			f.noError(err, "update email")
+
+			// old email is free now
+			_, err = f.repos.User.UpdateByNickname(f.ctx, models.User{Nickname: "jack", Email: "john@example.com"})
+			f.noError(err, "update email to freed one")
+		}},
+		{"UpdateNotExisting", func(f fixture) {
+			_, err := f.repos.User.UpdateByNickname(f.ctx, models.User{Nickname: "nobody", About: "about"})
+			f.expectError(err, models.ErrDoesNotExist, "update not existing user")
+		}},
+		{"UpdateWithTakenEmail", func(f fixture) {
+			f.user("john")
+			f.user("jack")
+
+			_, err := f.repos.User.UpdateByNickname(f.ctx, models.User{Nickname: "john", Email: "JACK@example.com"})
+			f.expectError(err, models.ErrConflict, "update user with taken email")
+		}},
+	})
+}
+
+func sortedNicknames(users models.Users) []string {
+	nicknames := make([]string, 0, len(users))
+	for _, userModel := range users {
+		nicknames = append(nicknames, userModel.Nickname)
+	}
+	sort.Strings(nicknames)
+	return nicknames
+}
+
+func nicknames(users models.Users) []string {
+	result := make([]string, 0, len(users))
+	for _, userModel := range users {
+		result = append(result, userModel.Nickname)
+	}
+	return result
+}
@@ -0,0 +1,373 @@
+package contract
+
+import (
+	"encoding/json"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	webhookUseCase "github.com/nickeskov/db_forum/internal/pkg/webhook/usecase"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// RunWebhook checks webhook.Repository contract and events written by thread and post repositories
+func RunWebhook(t *testing.T, newRepositories Factory) {
+	runCases(t, newRepositories, []testCase{
+		{"CreateGetDelete", func(f fixture) {
+			f.user("john")
+			f.forum("Forum", "john")
+
+			_, err := f.repos.Webhook.Create(f.ctx, models.Webhook{
+				Forum:  "nowhere",
+				URL:    "http://localhost/hook",
+				Events: []string{models.PostCreatedWebhookEvent},
+			})
+			f.expectError(err, models.ErrBadForeign, "create webhook of not existing forum")
+
+			created := f.webhook("forum", models.PostCreatedWebhookEvent, models.ThreadVotedWebhookEvent)
+			f.expectEqual(created.Forum, "Forum", "canonical forum slug")
+
+			got, err := f.repos.Webhook.GetByID(f.ctx, created.ID)
+			f.noError(err, "get webhook")
+			f.expectEqual(got.URL, created.URL, "webhook url")
+			f.expectEqual(got.Secret, "secret", "webhook secret")
+			f.expectStrings(got.Events, created.Events, "webhook events")
+
+			hooks, err := f.repos.Webhook.GetByForumSlug(f.ctx, "FORUM")
+			f.noError(err, "get forum webhooks")
+			f.expectEqual(len(hooks), 1, "forum webhooks count")
+			f.expectEqual(hooks[0].ID, created.ID, "forum webhook id")
+
+			_, err = f.repos.Webhook.GetByForumSlug(f.ctx, "nowhere")
+			f.expectError(err, models.ErrDoesNotExist, "get webhooks of not existing forum")
+
+			f.noError(f.repos.Webhook.DeleteByID(f.ctx, created.ID), "delete webhook")
+
+			_, err = f.repos.Webhook.GetByID(f.ctx, created.ID)
+			f.expectError(err, models.ErrDoesNotExist, "get deleted webhook")
+			f.expectError(f.repos.Webhook.DeleteByID(f.ctx, created.ID), models.ErrDoesNotExist,
+				"delete deleted webhook")
+
+			hooks, err = f.repos.Webhook.GetByForumSlug(f.ctx, "forum")
+			f.noError(err, "get forum webhooks after delete")
+			f.expectEqual(len(hooks), 0, "forum webhooks count after delete")
+		}},
+		{"EventsAreWrittenWithData", func(f fixture) {
+			f.user("john")
+			f.forum("forum", "john")
+			f.forum("other", "john")
+
+			hook := f.webhook("forum", models.PostCreatedWebhookEvent, models.ThreadVotedWebhookEvent)
+			otherHook := f.webhook("other", models.ThreadCreatedWebhookEvent)
+
+			threadModel := f.thread("forum", "john", "thread", time.Now())
+			posts := f.posts(threadModel,
+				models.Post{Author: "john", Message: "first"},
+				models.Post{Author: "john", Message: "second"},
+			)
+
+			// failed batch must not leave events of its inserted posts
+			_, err := f.repos.Post.CreatePostsInThread(f.ctx, threadModel, models.Posts{
+				{Author: "john", Message: "third"},
+				{Author: "john", Message: "orphan", Parent: posts[1].ID + 1000},
+			})
+			f.expectError(err, models.ErrConflict, "create posts with not existing parent")
+
+			_, err = f.repos.Post.UpdatePostByID(f.ctx, models.Post{ID: posts[0].ID, Message: "edited"})
+			f.noError(err, "update post")
+
+			_, err = f.repos.Thread.VoteByID(f.ctx, threadModel.ID, models.Vote{Nickname: "john", Voice: -1})
+			f.noError(err, "vote")
+
+			deliveries, err := f.repos.Webhook.GetDeliveries(f.ctx, hook.ID, nil, false, 100)
+			f.noError(err, "get deliveries")
+			f.expectEqual(len(deliveries), 3, "deliveries count")
+
+			for i, postModel := range posts {
+				f.expectEqual(deliveries[i].Event, models.PostCreatedWebhookEvent, "post delivery event")
+				f.expectEqual(deliveries[i].Status, models.PendingWebhookDelivery, "post delivery status")
+				f.expectEqual(deliveries[i].Attempts, int32(0), "post delivery attempts")
+
+				var payload models.Post
+				f.noError(json.Unmarshal(deliveries[i].Payload, &payload), "unmarshal post payload")
+				f.expectEqual(payload.ID, postModel.ID, "post payload id")
+				f.expectEqual(payload.Message, postModel.Message, "post payload message")
+			}
+
+			f.expectEqual(deliveries[2].Event, models.ThreadVotedWebhookEvent, "vote delivery event")
+			var votes models.ThreadVotes
+			f.noError(json.Unmarshal(deliveries[2].Payload, &votes), "unmarshal vote payload")
+			f.expectEqual(votes, models.ThreadVotes{Thread: threadModel.ID, Votes: -1, Nickname: "john", Voice: -1},
+				"vote payload")
+
+			since := deliveries[0].ID
+			deliveries, err = f.repos.Webhook.GetDeliveries(f.ctx, hook.ID, &since, true, 100)
+			f.noError(err, "get deliveries with since desc")
+			f.expectEqual(len(deliveries), 0, "deliveries before the first one")
+
+			deliveries, err = f.repos.Webhook.GetDeliveries(f.ctx, otherHook.ID, nil, false, 100)
+			f.noError(err, "get deliveries of other forum webhook")
+			f.expectEqual(len(deliveries), 0, "deliveries of other forum webhook")
+
+			_, err = f.repos.Webhook.GetDeliveries(f.ctx, otherHook.ID+1000, nil, false, 100)
+			f.expectError(err, models.ErrDoesNotExist, "get deliveries of not existing webhook")
+		}},
+		{"ClaimAndSaveAttempt", func(f fixture) {
+			f.user("john")
+			f.forum("forum", "john")
+
+			hook := f.webhook("forum", models.ThreadCreatedWebhookEvent)
+			threadModel := f.thread("forum", "john", "thread", time.Now())
+
+			now := time.Now().Add(time.Second)
+			dispatches, err := f.repos.Webhook.ClaimDeliveries(f.ctx, now, time.Minute, 10)
+			f.noError(err, "claim deliveries")
+			f.expectEqual(len(dispatches), 1, "claimed deliveries count")
+			f.expectEqual(dispatches[0].URL, hook.URL, "claimed delivery url")
+			f.expectEqual(dispatches[0].Secret, "secret", "claimed delivery secret")
+			f.expectEqual(dispatches[0].Forum, "forum", "claimed delivery forum")
+
+			var payload models.Thread
+			f.noError(json.Unmarshal(dispatches[0].Delivery.Payload, &payload), "unmarshal thread payload")
+			f.expectEqual(payload.ID, threadModel.ID, "thread payload id")
+
+			dispatches2, err := f.repos.Webhook.ClaimDeliveries(f.ctx, now, time.Minute, 10)
+			f.noError(err, "claim deliveries again")
+			f.expectEqual(len(dispatches2), 0, "deliveries claimed twice")
+
+			delivery := dispatches[0].Delivery
+			delivery.Attempts = 1
+			delivery.ResponseStatus = http.StatusBadGateway
+			delivery.Error = "bad gateway"
+			delivery.NextAttempt = now.Add(time.Hour)
+			f.noError(f.repos.Webhook.SaveAttempt(f.ctx, delivery), "save failed attempt")
+
+			dispatches, err = f.repos.Webhook.ClaimDeliveries(f.ctx, now.Add(2*time.Minute), time.Minute, 10)
+			f.noError(err, "claim deliveries before next attempt")
+			f.expectEqual(len(dispatches), 0, "deliveries claimed before next attempt")
+
+			dispatches, err = f.repos.Webhook.ClaimDeliveries(f.ctx, now.Add(2*time.Hour), time.Minute, 10)
+			f.noError(err, "claim deliveries after next attempt")
+			f.expectEqual(len(dispatches), 1, "deliveries claimed after next attempt")
+			f.expectEqual(dispatches[0].Delivery.Attempts, int32(1), "claimed delivery attempts")
+			f.expectEqual(dispatches[0].Delivery.ResponseStatus, int32(http.StatusBadGateway),
+				"claimed delivery response status")
+
+			delivered := now.Add(2 * time.Hour)
+			delivery = dispatches[0].Delivery
+			delivery.Status = models.DeliveredWebhookDelivery
+			delivery.Attempts = 2
+			delivery.ResponseStatus = http.StatusOK
+			delivery.Error = ""
+			delivery.Delivered = &delivered
+			f.noError(f.repos.Webhook.SaveAttempt(f.ctx, delivery), "save delivered attempt")
+
+			deliveries, err := f.repos.Webhook.GetDeliveries(f.ctx, hook.ID, nil, false, 100)
+			f.noError(err, "get deliveries")
+			f.expectEqual(len(deliveries), 1, "deliveries count")
+			f.expectEqual(deliveries[0].Status, models.DeliveredWebhookDelivery, "delivery status")
+			f.expectEqual(deliveries[0].Error, "", "delivery error")
+			if deliveries[0].Delivered == nil {
+				f.t.Fatalf("delivered time is not saved")
+			}
+
+			dispatches, err = f.repos.Webhook.ClaimDeliveries(f.ctx, now.Add(24*time.Hour), time.Minute, 10)
+			f.noError(err, "claim delivered deliveries")
+			f.expectEqual(len(dispatches), 0, "delivered deliveries claimed")
+
+			f.noError(f.repos.Webhook.DeleteByID(f.ctx, hook.ID), "delete webhook")
+			f.expectError(f.repos.Webhook.SaveAttempt(f.ctx, delivery), models.ErrDoesNotExist,
+				"save attempt of deleted webhook delivery")
+		}},
+		{"DispatcherSignsAndRetries", func(f fixture) {
+			f.user("john")
+			f.forum("forum", "john")
+
+			var mu sync.Mutex
+			var requests []*http.Request
+			var bodies [][]byte
+
+			standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				requests = append(requests, r)
+				bodies = append(bodies, body)
+				if len(requests) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer standIn.Close()
+
+			hook, err := f.repos.Webhook.Create(f.ctx, models.Webhook{
+				Forum:  "forum",
+				URL:    standIn.URL,
+				Secret: "secret",
+				Events: []string{models.ThreadCreatedWebhookEvent},
+			})
+			f.noError(err, "create webhook")
+			threadModel := f.thread("forum", "john", "thread", time.Now())
+
+			dispatcher := webhookUseCase.NewDispatcher(f.repos.Webhook, 5*time.Second, 10, 3,
+				time.Minute, time.Hour, nil).WithDialControl(nil)
+
+			now := time.Now().Add(time.Second)
+			sent, err := dispatcher.DispatchDue(f.ctx, now)
+			f.noError(err, "dispatch")
+			f.expectEqual(sent, 1, "dispatched deliveries")
+
+			sent, err = dispatcher.DispatchDue(f.ctx, now.Add(30*time.Second))
+			f.noError(err, "dispatch before backoff")
+			f.expectEqual(sent, 0, "dispatched deliveries before backoff")
+
+			sent, err = dispatcher.DispatchDue(f.ctx, now.Add(2*time.Minute))
+			f.noError(err, "dispatch after backoff")
+			f.expectEqual(sent, 1, "dispatched deliveries after backoff")
+
+			f.expectEqual(len(requests), 2, "stand-in requests")
+			for i, request := range requests {
+				f.expectEqual(request.Header.Get(webhookUseCase.SignatureHeader),
+					webhookUseCase.Sign("secret", bodies[i]), "signature")
+				f.expectEqual(request.Header.Get(webhookUseCase.EventHeader), models.ThreadCreatedWebhookEvent,
+					"event header")
+			}
+
+			var payload models.WebhookPayload
+			f.noError(json.Unmarshal(bodies[1], &payload), "unmarshal webhook payload")
+			f.expectEqual(payload.Event, models.ThreadCreatedWebhookEvent, "payload event")
+			f.expectEqual(payload.Forum, "forum", "payload forum")
+
+			var payloadThread models.Thread
+			f.noError(json.Unmarshal(payload.Data, &payloadThread), "unmarshal payload data")
+			f.expectEqual(payloadThread.ID, threadModel.ID, "payload thread id")
+
+			deliveries, err := f.repos.Webhook.GetDeliveries(f.ctx, hook.ID, nil, false, 100)
+			f.noError(err, "get deliveries")
+			f.expectEqual(len(deliveries), 1, "deliveries count")
+			f.expectEqual(payload.Delivery, deliveries[0].ID, "payload delivery id")
+			f.expectEqual(deliveries[0].Status, models.DeliveredWebhookDelivery, "delivery status")
+			f.expectEqual(deliveries[0].Attempts, int32(2), "delivery attempts")
+			f.expectEqual(deliveries[0].ResponseStatus, int32(http.StatusNoContent), "delivery response status")
+		}},
+		{"DispatcherFailsAfterMaxAttempts", func(f fixture) {
+			f.user("john")
+			f.forum("forum", "john")
+
+			standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "broken", http.StatusInternalServerError)
+			}))
+			defer standIn.Close()
+
+			hook, err := f.repos.Webhook.Create(f.ctx, models.Webhook{
+				Forum:  "forum",
+				URL:    standIn.URL,
+				Secret: "secret",
+				Events: []string{models.ThreadCreatedWebhookEvent},
+			})
+			f.noError(err, "create webhook")
+			f.thread("forum", "john", "thread", time.Now())
+
+			dispatcher := webhookUseCase.NewDispatcher(f.repos.Webhook, 5*time.Second, 10, 2,
+				time.Minute, time.Hour, nil).WithDialControl(nil)
+
+			now := time.Now().Add(time.Second)
+			for i := 0; i < 3; i++ {
+				_, err := dispatcher.DispatchDue(f.ctx, now.Add(time.Duration(i)*time.Hour))
+				f.noError(err, "dispatch")
+			}
+
+			deliveries, err := f.repos.Webhook.GetDeliveries(f.ctx, hook.ID, nil, false, 100)
+			f.noError(err, "get deliveries")
+			f.expectEqual(deliveries[0].Status, models.FailedWebhookDelivery, "delivery status")
+			f.expectEqual(deliveries[0].Attempts, int32(2), "delivery attempts")
+			f.expectEqual(deliveries[0].ResponseStatus, int32(http.StatusInternalServerError),
+				"delivery response status")
+			// response body is not stored
+			f.expectEqual(deliveries[0].Error, "unexpected response status 500", "delivery error")
+		}},
+		{"DispatcherDoesNotFollowRedirects", func(f fixture) {
+			f.user("john")
+			f.forum("forum", "john")
+
+			var requests []string
+			standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.Path)
+				http.Redirect(w, r, "/internal", http.StatusFound)
+			}))
+			defer standIn.Close()
+
+			hook, err := f.repos.Webhook.Create(f.ctx, models.Webhook{
+				Forum:  "forum",
+				URL:    standIn.URL + "/hook",
+				Secret: "secret",
+				Events: []string{models.ThreadCreatedWebhookEvent},
+			})
+			f.noError(err, "create webhook")
+			f.thread("forum", "john", "thread", time.Now())
+
+			dispatcher := webhookUseCase.NewDispatcher(f.repos.Webhook, 5*time.Second, 10, 2,
+				time.Minute, time.Hour, nil).WithDialControl(nil)
+			_, err = dispatcher.DispatchDue(f.ctx, time.Now().Add(time.Second))
+			f.noError(err, "dispatch")
+
+			f.expectEqual(len(requests), 1, "stand-in requests")
+			deliveries, err := f.repos.Webhook.GetDeliveries(f.ctx, hook.ID, nil, false, 100)
+			f.noError(err, "get deliveries")
+			f.expectEqual(deliveries[0].Status, models.PendingWebhookDelivery, "delivery status")
+			f.expectEqual(deliveries[0].ResponseStatus, int32(http.StatusFound), "delivery response status")
+		}},
+		{"DispatcherRefusesNotPublicAddresses", func(f fixture) {
+			f.user("john")
+			f.forum("forum", "john")
+
+			requests := 0
+			standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+			}))
+			defer standIn.Close()
+
+			// webhook could be stored before its host was resolved to loopback address
+			hook, err := f.repos.Webhook.Create(f.ctx, models.Webhook{
+				Forum:  "forum",
+				URL:    standIn.URL,
+				Secret: "secret",
+				Events: []string{models.ThreadCreatedWebhookEvent},
+			})
+			f.noError(err, "create webhook")
+			f.thread("forum", "john", "thread", time.Now())
+
+			dispatcher := webhookUseCase.NewDispatcher(f.repos.Webhook, 5*time.Second, 10, 2,
+				time.Minute, time.Hour, nil)
+			_, err = dispatcher.DispatchDue(f.ctx, time.Now().Add(time.Second))
+			f.noError(err, "dispatch")
+
+			f.expectEqual(requests, 0, "stand-in requests")
+			deliveries, err := f.repos.Webhook.GetDeliveries(f.ctx, hook.ID, nil, false, 100)
+			f.noError(err, "get deliveries")
+			f.expectEqual(deliveries[0].Status, models.PendingWebhookDelivery, "delivery status")
+			f.expectEqual(deliveries[0].ResponseStatus, int32(0), "delivery response status")
+		}},
+	})
+}
+
+func (f fixture) webhook(forumSlug string, events ...string) models.Webhook {
+	f.t.Helper()
+
+	hook, err := f.repos.Webhook.Create(f.ctx, models.Webhook{
+		Forum:  forumSlug,
+		URL:    "http://localhost/hooks/" + forumSlug,
+		Secret: "secret",
+		Events: events,
+	})
+	if err != nil {
+		f.t.Fatalf("cannot create webhook of forum %s: %+v", forumSlug, err)
+	}
+
+	return hook
+}
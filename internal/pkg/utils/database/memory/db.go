@@ -0,0 +1,345 @@
+package memory
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DB is in-memory storage which mimics postgres schema from migrations:
+// citext keys, posts materialized path and triggers which maintain forum counters,
+// forum users and thread votes. All table fields must be accessed under DB lock.
+type DB struct {
+	sync.RWMutex
+
+	Users           []*models.User
+	UsersByNickname map[string]*models.User
+	UsersByEmail    map[string]*models.User
+	PasswordHashes  map[string]string
+
+	Sessions map[string]Session
+
+	Forums     map[string]*models.Forum
+	ForumUsers map[string]map[string]bool
+	ForumRoles map[string]map[string]models.ForumRole
+
+	Threads       map[int32]*models.Thread
+	ThreadsBySlug map[string]*models.Thread
+	Votes         map[VoteKey]int16
+
+	Posts         map[int64]*Post
+	ThreadPosts   map[int32][]*Post
+	PostRevisions map[int64]models.PostRevisions
+
+	Webhooks          map[int64]*models.Webhook
+	WebhookDeliveries map[int64]*models.WebhookDelivery
+
+	lastThreadID          int32
+	lastPostID            int64
+	lastWebhookID         int64
+	lastWebhookDeliveryID int64
+}
+
+type VoteKey struct {
+	ThreadID int32
+	Nickname string
+}
+
+type Session struct {
+	Nickname string
+	Expires  time.Time
+}
+
+type Post struct {
+	models.Post
+	Path []int64
+}
+
+func NewDB() *DB {
+	db := new(DB)
+	db.Truncate()
+	return db
+}
+
+// Truncate removes all rows, but like TRUNCATE in postgres does not restart id sequences
+func (db *DB) Truncate() {
+	db.Users = nil
+	db.UsersByNickname = make(map[string]*models.User)
+	db.UsersByEmail = make(map[string]*models.User)
+	db.PasswordHashes = make(map[string]string)
+
+	db.Sessions = make(map[string]Session)
+
+	db.Forums = make(map[string]*models.Forum)
+	db.ForumUsers = make(map[string]map[string]bool)
+	db.ForumRoles = make(map[string]map[string]models.ForumRole)
+
+	db.Threads = make(map[int32]*models.Thread)
+	db.ThreadsBySlug = make(map[string]*models.Thread)
+	db.Votes = make(map[VoteKey]int16)
+
+	db.Posts = make(map[int64]*Post)
+	db.ThreadPosts = make(map[int32][]*Post)
+	db.PostRevisions = make(map[int64]models.PostRevisions)
+
+	db.Webhooks = make(map[int64]*models.Webhook)
+	db.WebhookDeliveries = make(map[int64]*models.WebhookDelivery)
+}
+
+// CIKey converts citext value to map key
+func CIKey(value string) string {
+	return strings.ToLower(value)
+}
+
+// Timestamp rounds time as TIMESTAMP(3) column does
+func Timestamp(t time.Time) time.Time {
+	return t.Round(time.Millisecond)
+}
+
+// CheckContext returns error if request is already cancelled, like pgx does before query
+func CheckContext(ctx context.Context) error {
+	return errors.WithStack(ctx.Err())
+}
+
+// InsertThread assigns id to thread, stores it and runs threads triggers.
+// Thread author and forum must be already resolved to canonical values.
+func (db *DB) InsertThread(thread models.Thread) models.Thread {
+	db.lastThreadID++
+	thread.ID = db.lastThreadID
+	thread.Created = Timestamp(thread.Created)
+
+	stored := thread
+	db.Threads[stored.ID] = &stored
+	if stored.Slug != "" {
+		db.ThreadsBySlug[CIKey(stored.Slug)] = &stored
+	}
+
+	db.addForumUser(stored.Forum, stored.Author)
+	db.Forums[CIKey(stored.Forum)].Threads++
+
+	return thread
+}
+
+// UpsertVote inserts or updates vote and recalculates thread votes like insert_vote and update_vote triggers
+func (db *DB) UpsertVote(threadID int32, vote models.Vote) {
+	key := VoteKey{ThreadID: threadID, Nickname: CIKey(vote.Nickname)}
+
+	oldVoice := db.Votes[key]
+	db.Votes[key] = vote.Voice
+	db.Threads[threadID].Votes += int32(vote.Voice - oldVoice)
+}
+
+// NextPostID reserves id for new post like BIGSERIAL does
+func (db *DB) NextPostID() int64 {
+	db.lastPostID++
+	return db.lastPostID
+}
+
+// PostPath builds materialized path for new post or returns ErrConflict if parent is not in the thread
+func (db *DB) PostPath(threadID int32, id, parentID int64) ([]int64, error) {
+	if parentID == 0 {
+		return []int64{id}, nil
+	}
+
+	parent, ok := db.Posts[parentID]
+	if !ok || parent.Thread != threadID {
+		return nil, models.ErrConflict
+	}
+
+	path := make([]int64, 0, len(parent.Path)+1)
+	path = append(path, parent.Path...)
+	return append(path, id), nil
+}
+
+// InsertPost stores post with path and runs posts triggers
+func (db *DB) InsertPost(post Post) {
+	post.Created = Timestamp(post.Created)
+
+	stored := post
+	db.Posts[stored.ID] = &stored
+	db.ThreadPosts[stored.Thread] = append(db.ThreadPosts[stored.Thread], &stored)
+
+	db.addForumUser(stored.Forum, stored.Author)
+	db.Forums[CIKey(stored.Forum)].Posts++
+}
+
+// SetPostDeleted marks post as deleted or restores it and adjusts forum posts counter
+func (db *DB) SetPostDeleted(stored *Post, isDeleted bool) {
+	if stored.IsDeleted == isDeleted {
+		return
+	}
+
+	stored.IsDeleted = isDeleted
+	if isDeleted {
+		db.Forums[CIKey(stored.Forum)].Posts--
+	} else {
+		db.Forums[CIKey(stored.Forum)].Posts++
+	}
+}
+
+// MoveThread moves thread with its posts to other forum and adjusts forum counters and users
+func (db *DB) MoveThread(stored *models.Thread, forum *models.Forum) {
+	oldForum := db.Forums[CIKey(stored.Forum)]
+
+	stored.Forum = forum.Slug
+	oldForum.Threads--
+	forum.Threads++
+	db.addForumUser(forum.Slug, stored.Author)
+
+	db.movePosts(db.ThreadPosts[stored.ID], stored.ID, forum.Slug)
+
+	db.removeInactiveForumUsers(oldForum.Slug, db.threadAuthors(stored.ID, stored.Author))
+}
+
+// MergeThreads moves posts of source thread to target thread keeping their paths and deletes source thread.
+// Paths are built from global post ids, so they stay valid in target thread.
+func (db *DB) MergeThreads(source, target *models.Thread) {
+	sourcePosts := db.ThreadPosts[source.ID]
+	authors := db.threadAuthors(source.ID, source.Author)
+
+	db.movePosts(sourcePosts, target.ID, target.Forum)
+
+	targetPosts := append(db.ThreadPosts[target.ID], sourcePosts...)
+	sort.Slice(targetPosts, func(i, j int) bool {
+		return targetPosts[i].ID < targetPosts[j].ID
+	})
+	db.ThreadPosts[target.ID] = targetPosts
+
+	db.deleteThread(source)
+	db.removeInactiveForumUsers(source.Forum, authors)
+}
+
+// SplitPosts moves post with all its descendants to other thread of the same forum,
+// paths of moved posts are cut to start from the post, which becomes root post of thread
+func (db *DB) SplitPosts(root *Post, threadID int32) {
+	oldThreadID := root.Thread
+	rootPath := root.Path
+
+	keptPosts := make([]*Post, 0)
+	for _, stored := range db.ThreadPosts[oldThreadID] {
+		if !HasPathPrefix(stored.Path, rootPath) {
+			keptPosts = append(keptPosts, stored)
+			continue
+		}
+
+		stored.Thread = threadID
+		stored.Path = append([]int64(nil), stored.Path[len(rootPath)-1:]...)
+		db.ThreadPosts[threadID] = append(db.ThreadPosts[threadID], stored)
+	}
+	db.ThreadPosts[oldThreadID] = keptPosts
+
+	root.Parent = 0
+}
+
+func (db *DB) movePosts(posts []*Post, threadID int32, forumSlug string) {
+	for _, stored := range posts {
+		if CIKey(stored.Forum) != CIKey(forumSlug) {
+			if !stored.IsDeleted {
+				db.Forums[CIKey(stored.Forum)].Posts--
+				db.Forums[CIKey(forumSlug)].Posts++
+			}
+			stored.Forum = forumSlug
+		}
+		stored.Thread = threadID
+		db.addForumUser(forumSlug, stored.Author)
+	}
+}
+
+func (db *DB) deleteThread(stored *models.Thread) {
+	delete(db.Threads, stored.ID)
+	if stored.Slug != "" {
+		delete(db.ThreadsBySlug, CIKey(stored.Slug))
+	}
+	delete(db.ThreadPosts, stored.ID)
+	for key := range db.Votes {
+		if key.ThreadID == stored.ID {
+			delete(db.Votes, key)
+		}
+	}
+
+	db.Forums[CIKey(stored.Forum)].Threads--
+}
+
+// threadAuthors returns nicknames of thread author and authors of its posts
+func (db *DB) threadAuthors(threadID int32, author string) []string {
+	authors := []string{author}
+	for _, stored := range db.ThreadPosts[threadID] {
+		authors = append(authors, stored.Author)
+	}
+	return authors
+}
+
+// removeInactiveForumUsers removes users without threads and posts in forum from forum users
+func (db *DB) removeInactiveForumUsers(forumSlug string, nicknames []string) {
+	forumKey := CIKey(forumSlug)
+
+	inactive := make(map[string]bool)
+	for _, nickname := range nicknames {
+		inactive[CIKey(nickname)] = true
+	}
+
+	for _, stored := range db.Threads {
+		if CIKey(stored.Forum) == forumKey {
+			delete(inactive, CIKey(stored.Author))
+		}
+	}
+	for _, stored := range db.Posts {
+		if CIKey(stored.Forum) == forumKey {
+			delete(inactive, CIKey(stored.Author))
+		}
+	}
+
+	for nickname := range inactive {
+		delete(db.ForumUsers[forumKey], nickname)
+	}
+}
+
+func (db *DB) addForumUser(forumSlug, nickname string) {
+	forumKey := CIKey(forumSlug)
+
+	forumUsers, ok := db.ForumUsers[forumKey]
+	if !ok {
+		forumUsers = make(map[string]bool)
+		db.ForumUsers[forumKey] = forumUsers
+	}
+
+	forumUsers[CIKey(nickname)] = true
+}
+
+// HasPathPrefix reports whether post with path is prefix post itself or its descendant
+func HasPathPrefix(path, prefix []int64) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ComparePaths compares posts paths like postgres compares BIGINT[] values
+func ComparePaths(left, right []int64) int {
+	for i := 0; i < len(left) && i < len(right); i++ {
+		switch {
+		case left[i] < right[i]:
+			return -1
+		case left[i] > right[i]:
+			return 1
+		}
+	}
+
+	switch {
+	case len(left) < len(right):
+		return -1
+	case len(left) > len(right):
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"encoding/json"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// InsertWebhook assigns id to webhook and stores it.
+// Webhook forum must be already resolved to canonical value.
+func (db *DB) InsertWebhook(webhook models.Webhook) models.Webhook {
+	db.lastWebhookID++
+	webhook.ID = db.lastWebhookID
+	webhook.Created = Timestamp(time.Now())
+	webhook.Events = append([]string(nil), webhook.Events...)
+
+	stored := webhook
+	db.Webhooks[stored.ID] = &stored
+
+	return webhook
+}
+
+// DeleteWebhook removes webhook with its deliveries like ON DELETE CASCADE does
+func (db *DB) DeleteWebhook(id int64) {
+	delete(db.Webhooks, id)
+	for deliveryID, stored := range db.WebhookDeliveries {
+		if stored.Webhook == id {
+			delete(db.WebhookDeliveries, deliveryID)
+		}
+	}
+}
+
+// InsertWebhookEvents adds pending delivery of every payload to each webhook of forum subscribed to event,
+// it must be called under the same lock as data change, so events are stored atomically with it
+func (db *DB) InsertWebhookEvents(forumSlug, event string, payloads ...interface{}) error {
+	var webhooks []*models.Webhook
+	for _, stored := range db.Webhooks {
+		if CIKey(stored.Forum) == CIKey(forumSlug) && hasEvent(stored.Events, event) {
+			webhooks = append(webhooks, stored)
+		}
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	now := Timestamp(time.Now())
+	for _, payload := range payloads {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		for _, webhook := range webhooks {
+			db.lastWebhookDeliveryID++
+			db.WebhookDeliveries[db.lastWebhookDeliveryID] = &models.WebhookDelivery{
+				ID:          db.lastWebhookDeliveryID,
+				Webhook:     webhook.ID,
+				Event:       event,
+				Payload:     data,
+				Status:      models.PendingWebhookDelivery,
+				NextAttempt: now,
+				Created:     now,
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasEvent(events []string, event string) bool {
+	for _, subscribed := range events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+// Package instrumentation is shared by decorators which trace and measure methods of usecases and repositories.
+package instrumentation
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// Observer starts span for every call of component method and observes its latency.
+// Durations and tracer are optional, nil value disables them.
+type Observer struct {
+	component string
+	layer     string
+	durations *prometheus.HistogramVec
+	tracer    trace.Tracer
+}
+
+// NewObserver creates observer of component layer, e.g. "post" "repository".
+// Durations must have component and method labels.
+func NewObserver(component, layer string, durations *prometheus.HistogramVec, tracer trace.Tracer) Observer {
+	return Observer{
+		component: component,
+		layer:     layer,
+		durations: durations,
+		tracer:    tracer,
+	}
+}
+
+// Start starts observing method call, returned func must be called with result error when method ends.
+// Span is started only inside of recorded request, so background jobs don't produce a trace per call.
+func (observer Observer) Start(ctx context.Context, method string) (context.Context, func(err error)) {
+	start := time.Now()
+
+	var span trace.Span
+	if observer.tracer != nil && trace.SpanFromContext(ctx).IsRecording() {
+		ctx, span = observer.tracer.Start(ctx, observer.component+"."+observer.layer+"/"+method)
+	}
+
+	return ctx, func(err error) {
+		if observer.durations != nil {
+			observer.durations.WithLabelValues(observer.component, method).Observe(time.Since(start).Seconds())
+		}
+		if span == nil {
+			return
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
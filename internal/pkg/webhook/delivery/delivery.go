@@ -0,0 +1,198 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/nickeskov/db_forum/internal/pkg/auth"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/utils"
+	"github.com/nickeskov/db_forum/internal/pkg/webhook"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"net/http"
+	"strconv"
+)
+
+type Delivery struct {
+	useCase webhook.UseCase
+	guard   auth.Guard
+	utils   httpUtils.Utils
+}
+
+func NewDelivery(useCase webhook.UseCase, guard auth.Guard, logger logger.Logger) Delivery {
+	return Delivery{
+		useCase: useCase,
+		guard:   guard,
+		utils:   httpUtils.NewDeliveryUtils(logger),
+	}
+}
+
+func (delivery Delivery) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	data, err := delivery.utils.ReadAllDataFromBody(w, r)
+	if err != nil {
+		return
+	}
+
+	var newWebhook models.Webhook
+
+	if err := json.Unmarshal(data, &newWebhook); err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	newWebhook.Forum = slug
+
+	if validationErr := newWebhook.Validate(); validationErr != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, validationErr.Error())
+		return
+	}
+
+	if err := delivery.guard.CheckForumOwner(r.Context(), slug); err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return
+	}
+
+	createdWebhook, err := delivery.useCase.Create(r.Context(), newWebhook)
+	switch {
+	case errors.Is(err, models.ErrBadForeign):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("forum with slug=%s does not exist", slug))
+
+	case errors.Is(err, models.ErrValidation):
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest,
+			fmt.Sprintf("host of url=%s is not resolved to public addresses", newWebhook.URL))
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(createdWebhook)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusCreated, data)
+	}
+}
+
+func (delivery Delivery) GetForumWebhooks(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	if err := delivery.guard.CheckForumOwner(r.Context(), slug); err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return
+	}
+
+	hooks, err := delivery.useCase.GetByForumSlug(r.Context(), slug)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("forum with slug=%s does not exist", slug))
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(hooks)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+func (delivery Delivery) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, ok := delivery.getOwnedWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	err := delivery.useCase.DeleteByID(r.Context(), hook.ID)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("webhook with id=%d does not exist", hook.ID))
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(hook)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+func (delivery Delivery) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	hook, ok := delivery.getOwnedWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	sinceID, desc, limit := utils.ParseSinceDescLimit(r.URL.Query())
+
+	deliveries, err := delivery.useCase.GetDeliveries(r.Context(), hook.ID, sinceID, desc, limit)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("webhook with id=%d does not exist", hook.ID))
+
+	case errors.Is(err, models.ErrInvalid):
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, models.ErrInvalid.Error())
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+
+	default:
+		data, err := json.Marshal(deliveries)
+		if err != nil {
+			delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		delivery.utils.WriteResponse(w, r, http.StatusOK, data)
+	}
+}
+
+// getOwnedWebhook returns webhook from id path param if caller is owner of its forum, otherwise writes error
+func (delivery Delivery) getOwnedWebhook(w http.ResponseWriter, r *http.Request) (models.Webhook, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		delivery.utils.WriteResponseError(w, r, http.StatusBadRequest, err.Error())
+		return models.Webhook{}, false
+	}
+
+	hook, err := delivery.useCase.GetByID(r.Context(), id)
+	switch {
+	case errors.Is(err, models.ErrDoesNotExist):
+		delivery.utils.WriteResponseError(w, r, http.StatusNotFound,
+			fmt.Sprintf("webhook with id=%d does not exist", id))
+		return models.Webhook{}, false
+
+	case err != nil:
+		delivery.utils.WriteResponseError(w, r, http.StatusInternalServerError,
+			fmt.Sprintf("%+v", err))
+		return models.Webhook{}, false
+	}
+
+	if err := delivery.guard.CheckForumOwner(r.Context(), hook.Forum); err != nil {
+		auth.WriteAccessError(delivery.utils, w, r, err)
+		return models.Webhook{}, false
+	}
+
+	return hook, true
+}
@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"time"
+)
+
+// Dispatch is claimed delivery with target of its webhook
+type Dispatch struct {
+	Delivery models.WebhookDelivery
+	Forum    string
+	URL      string
+	Secret   string
+}
+
+type Repository interface {
+	Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	GetByID(ctx context.Context, id int64) (models.Webhook, error)
+	GetByForumSlug(ctx context.Context, forumSlug string) (models.Webhooks, error)
+	DeleteByID(ctx context.Context, id int64) error
+	GetDeliveries(ctx context.Context, webhookID int64, sinceID *int64,
+		desc bool, limit int64) (models.WebhookDeliveries, error)
+	// ClaimDeliveries returns due pending deliveries and postpones them by lease,
+	// so concurrent dispatchers don't send the same delivery while it is in flight
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int32) ([]Dispatch, error)
+	// SaveAttempt stores status, attempts, next attempt and result of the last attempt of delivery
+	SaveAttempt(ctx context.Context, delivery models.WebhookDelivery) error
+}
@@ -0,0 +1,76 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"github.com/nickeskov/db_forum/internal/pkg/webhook"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const repositoryName = "webhook"
+
+// Repository traces every method of wrapped repository and observes its latency
+type Repository struct {
+	repository webhook.Repository
+	observer   instrumentation.Observer
+}
+
+// NewRepository wraps repository, durations must have repository and method labels.
+// Durations and tracer may be nil.
+func NewRepository(repository webhook.Repository, durations *prometheus.HistogramVec,
+	tracer trace.Tracer) Repository {
+
+	return Repository{
+		repository: repository,
+		observer:   instrumentation.NewObserver(repositoryName, "repository", durations, tracer),
+	}
+}
+
+func (repo Repository) Create(ctx context.Context, webhook models.Webhook) (_ models.Webhook, err error) {
+	ctx, done := repo.observer.Start(ctx, "Create")
+	defer func() { done(err) }()
+	return repo.repository.Create(ctx, webhook)
+}
+
+func (repo Repository) GetByID(ctx context.Context, id int64) (_ models.Webhook, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetByID")
+	defer func() { done(err) }()
+	return repo.repository.GetByID(ctx, id)
+}
+
+func (repo Repository) GetByForumSlug(ctx context.Context, forumSlug string) (_ models.Webhooks, err error) {
+	ctx, done := repo.observer.Start(ctx, "GetByForumSlug")
+	defer func() { done(err) }()
+	return repo.repository.GetByForumSlug(ctx, forumSlug)
+}
+
+func (repo Repository) DeleteByID(ctx context.Context, id int64) (err error) {
+	ctx, done := repo.observer.Start(ctx, "DeleteByID")
+	defer func() { done(err) }()
+	return repo.repository.DeleteByID(ctx, id)
+}
+
+func (repo Repository) GetDeliveries(ctx context.Context,
+	webhookID int64, sinceID *int64, desc bool, limit int64) (_ models.WebhookDeliveries, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "GetDeliveries")
+	defer func() { done(err) }()
+	return repo.repository.GetDeliveries(ctx, webhookID, sinceID, desc, limit)
+}
+
+func (repo Repository) ClaimDeliveries(ctx context.Context,
+	now time.Time, lease time.Duration, limit int32) (_ []webhook.Dispatch, err error) {
+
+	ctx, done := repo.observer.Start(ctx, "ClaimDeliveries")
+	defer func() { done(err) }()
+	return repo.repository.ClaimDeliveries(ctx, now, lease, limit)
+}
+
+func (repo Repository) SaveAttempt(ctx context.Context, delivery models.WebhookDelivery) (err error) {
+	ctx, done := repo.observer.Start(ctx, "SaveAttempt")
+	defer func() { done(err) }()
+	return repo.repository.SaveAttempt(ctx, delivery)
+}
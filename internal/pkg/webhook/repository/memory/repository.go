@@ -0,0 +1,215 @@
+package memory
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	memoryDB "github.com/nickeskov/db_forum/internal/pkg/utils/database/memory"
+	"github.com/nickeskov/db_forum/internal/pkg/webhook"
+	"sort"
+	"time"
+)
+
+type Repository struct {
+	db *memoryDB.DB
+}
+
+func NewRepository(db *memoryDB.DB) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) Create(ctx context.Context, hook models.Webhook) (models.Webhook, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Webhook{}, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	forum, ok := repo.db.Forums[memoryDB.CIKey(hook.Forum)]
+	if !ok {
+		return models.Webhook{}, models.ErrBadForeign
+	}
+	hook.Forum = forum.Slug
+
+	return repo.db.InsertWebhook(hook), nil
+}
+
+func (repo Repository) GetByID(ctx context.Context, id int64) (models.Webhook, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return models.Webhook{}, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	stored, ok := repo.db.Webhooks[id]
+	if !ok {
+		return models.Webhook{}, models.ErrDoesNotExist
+	}
+
+	return copyWebhook(stored), nil
+}
+
+func (repo Repository) GetByForumSlug(ctx context.Context, forumSlug string) (models.Webhooks, error) {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	slugKey := memoryDB.CIKey(forumSlug)
+	if _, ok := repo.db.Forums[slugKey]; !ok {
+		return nil, models.ErrDoesNotExist
+	}
+
+	hooks := make(models.Webhooks, 0)
+	for _, stored := range repo.db.Webhooks {
+		if memoryDB.CIKey(stored.Forum) == slugKey {
+			hooks = append(hooks, copyWebhook(stored))
+		}
+	}
+
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].ID < hooks[j].ID
+	})
+
+	return hooks, nil
+}
+
+func (repo Repository) DeleteByID(ctx context.Context, id int64) error {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	if _, ok := repo.db.Webhooks[id]; !ok {
+		return models.ErrDoesNotExist
+	}
+
+	repo.db.DeleteWebhook(id)
+	return nil
+}
+
+func (repo Repository) GetDeliveries(ctx context.Context, webhookID int64, sinceID *int64,
+	desc bool, limit int64) (models.WebhookDeliveries, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, models.ErrInvalid
+	}
+
+	repo.db.RLock()
+	defer repo.db.RUnlock()
+
+	if _, ok := repo.db.Webhooks[webhookID]; !ok {
+		return nil, models.ErrDoesNotExist
+	}
+
+	deliveries := make(models.WebhookDeliveries, 0)
+	for _, stored := range repo.db.WebhookDeliveries {
+		if stored.Webhook != webhookID {
+			continue
+		}
+		if sinceID != nil && ((desc && stored.ID >= *sinceID) || (!desc && stored.ID <= *sinceID)) {
+			continue
+		}
+		deliveries = append(deliveries, *stored)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if desc {
+			return deliveries[i].ID > deliveries[j].ID
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+
+	if int64(len(deliveries)) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (repo Repository) ClaimDeliveries(ctx context.Context, now time.Time,
+	lease time.Duration, limit int32) ([]webhook.Dispatch, error) {
+
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	var due []*models.WebhookDelivery
+	for _, stored := range repo.db.WebhookDeliveries {
+		if stored.Status == models.PendingWebhookDelivery && !stored.NextAttempt.After(now) {
+			due = append(due, stored)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttempt.Equal(due[j].NextAttempt) {
+			return due[i].NextAttempt.Before(due[j].NextAttempt)
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	if len(due) > int(limit) {
+		due = due[:limit]
+	}
+
+	dispatches := make([]webhook.Dispatch, 0, len(due))
+	for _, stored := range due {
+		stored.NextAttempt = memoryDB.Timestamp(now.Add(lease))
+
+		hook := repo.db.Webhooks[stored.Webhook]
+		dispatches = append(dispatches, webhook.Dispatch{
+			Delivery: *stored,
+			Forum:    hook.Forum,
+			URL:      hook.URL,
+			Secret:   hook.Secret,
+		})
+	}
+
+	return dispatches, nil
+}
+
+func (repo Repository) SaveAttempt(ctx context.Context, delivery models.WebhookDelivery) error {
+	if err := memoryDB.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	repo.db.Lock()
+	defer repo.db.Unlock()
+
+	stored, ok := repo.db.WebhookDeliveries[delivery.ID]
+	if !ok {
+		return models.ErrDoesNotExist // webhook was deleted with its deliveries
+	}
+
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttempt = memoryDB.Timestamp(delivery.NextAttempt)
+	stored.ResponseStatus = delivery.ResponseStatus
+	stored.Error = delivery.Error
+	stored.Delivered = nil
+	if delivery.Delivered != nil {
+		delivered := memoryDB.Timestamp(*delivery.Delivered)
+		stored.Delivered = &delivered
+	}
+
+	return nil
+}
+
+func copyWebhook(stored *models.Webhook) models.Webhook {
+	hook := *stored
+	hook.Events = append([]string(nil), stored.Events...)
+	return hook
+}
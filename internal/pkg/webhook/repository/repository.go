@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/database/driver/pgx/codes"
+	"github.com/nickeskov/db_forum/internal/pkg/webhook"
+	sqlHelpers "github.com/nickeskov/db_forum/pkg/sql"
+	pgx4Helpers "github.com/nickeskov/db_forum/pkg/sql/pgx/v4"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return Repository{
+		db: db,
+	}
+}
+
+func (repo Repository) Create(ctx context.Context, hook models.Webhook) (models.Webhook, error) {
+	err := repo.db.QueryRow(ctx, `
+			INSERT INTO webhooks (forum_slug, url, secret, events)
+			VALUES ((SELECT slug FROM forums WHERE slug = $1), $2, $3, $4)
+			RETURNING id, forum_slug, created`,
+		hook.Forum,
+		hook.URL,
+		hook.Secret,
+		hook.Events,
+	).Scan(
+		&hook.ID,
+		&hook.Forum,
+		&hook.Created,
+	)
+
+	if pgxErr := codes.ExtractPgx4ErrorCode(err); pgxErr != nil {
+		switch pgxErr.Error() {
+		case codes.ErrCodeForeignKey, codes.ErrCodeNotNull:
+			return models.Webhook{}, models.ErrBadForeign
+		}
+	}
+	if err != nil {
+		return models.Webhook{}, errors.Wrapf(err, "some error while creating webhook=%+v", hook)
+	}
+
+	return hook, nil
+}
+
+func (repo Repository) GetByID(ctx context.Context, id int64) (models.Webhook, error) {
+	var hook models.Webhook
+
+	row := repo.db.QueryRow(ctx, `
+			SELECT id, forum_slug, url, secret, events, created
+			FROM webhooks
+			WHERE id = $1`,
+		id,
+	)
+
+	err := scanWebhook(row, &hook)
+	switch {
+	case err == pgx.ErrNoRows:
+		return models.Webhook{}, models.ErrDoesNotExist
+	case err != nil:
+		return models.Webhook{}, errors.Wrapf(err, "some error while getting webhook with id=%d", id)
+	}
+
+	return hook, nil
+}
+
+func (repo Repository) GetByForumSlug(ctx context.Context, forumSlug string) (models.Webhooks, error) {
+	rows, err := repo.db.Query(ctx, `
+			SELECT id, forum_slug, url, secret, events, created
+			FROM webhooks
+			WHERE forum_slug = $1
+			ORDER BY id`,
+		forumSlug,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "some error while getting webhooks of forumSlug=%s", forumSlug)
+	}
+	defer rows.Close()
+
+	hooks := make(models.Webhooks, 0)
+	for rows.Next() {
+		var hook models.Webhook
+		if err := scanWebhook(rows, &hook); err != nil {
+			return nil, errors.Wrapf(err, "some error while scanning webhooks of forumSlug=%s", forumSlug)
+		}
+		hooks = append(hooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(hooks) == 0 {
+		if err := checkForumExists(ctx, repo.db, forumSlug); err != nil {
+			return nil, err
+		}
+	}
+
+	return hooks, nil
+}
+
+func (repo Repository) DeleteByID(ctx context.Context, id int64) error {
+	tag, err := repo.db.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrapf(err, "some error while deleting webhook with id=%d", id)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return models.ErrDoesNotExist
+	}
+
+	return nil
+}
+
+func (repo Repository) GetDeliveries(ctx context.Context, webhookID int64, sinceID *int64,
+	desc bool, limit int64) (models.WebhookDeliveries, error) {
+
+	var rows pgx.Rows
+	var err error
+
+	if sinceID != nil {
+		rows, err = repo.db.Query(ctx, sqlGetDeliveriesWithSince[desc], webhookID, *sinceID, limit)
+	} else {
+		rows, err = repo.db.Query(ctx, sqlGetDeliveries[desc], webhookID, limit)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "some error while getting deliveries of webhookID=%d", webhookID)
+	}
+	defer rows.Close()
+
+	deliveries := make(models.WebhookDeliveries, 0)
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := scanDelivery(rows, &delivery); err != nil {
+			return nil, errors.Wrapf(err, "some error while scanning deliveries of webhookID=%d", webhookID)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(deliveries) == 0 {
+		var exists bool
+		err := repo.db.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM webhooks WHERE id = $1)`, webhookID).Scan(&exists)
+		switch {
+		case err != nil:
+			return nil, errors.WithStack(err)
+		case !exists:
+			return nil, models.ErrDoesNotExist
+		}
+	}
+
+	return deliveries, nil
+}
+
+func (repo Repository) ClaimDeliveries(ctx context.Context, now time.Time,
+	lease time.Duration, limit int32) ([]webhook.Dispatch, error) {
+
+	rows, err := repo.db.Query(ctx, sqlClaimDeliveries, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "some error while claiming webhook deliveries")
+	}
+	defer rows.Close()
+
+	var dispatches []webhook.Dispatch
+	for rows.Next() {
+		var dispatch webhook.Dispatch
+		var responseStatus *int32
+		var deliveryError *string
+
+		err := rows.Scan(
+			&dispatch.Delivery.ID,
+			&dispatch.Delivery.Webhook,
+			&dispatch.Delivery.Event,
+			&dispatch.Delivery.Payload,
+			&dispatch.Delivery.Status,
+			&dispatch.Delivery.Attempts,
+			&dispatch.Delivery.NextAttempt,
+			&responseStatus,
+			&deliveryError,
+			&dispatch.Delivery.Created,
+			&dispatch.Delivery.Delivered,
+			&dispatch.Forum,
+			&dispatch.URL,
+			&dispatch.Secret,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "some error while scanning claimed webhook deliveries")
+		}
+		setNullable(&dispatch.Delivery, responseStatus, deliveryError)
+
+		dispatches = append(dispatches, dispatch)
+	}
+
+	return dispatches, errors.WithStack(rows.Err())
+}
+
+func (repo Repository) SaveAttempt(ctx context.Context, delivery models.WebhookDelivery) error {
+	tag, err := repo.db.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET status          = $2,
+				attempts        = $3,
+				next_attempt    = $4,
+				response_status = NULLIF($5, 0),
+				error           = NULLIF($6, ''),
+				delivered       = $7
+			WHERE id = $1`,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttempt,
+		delivery.ResponseStatus,
+		delivery.Error,
+		delivery.Delivered,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "some error while saving attempt of webhook delivery=%d", delivery.ID)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return models.ErrDoesNotExist // webhook was deleted with its deliveries
+	}
+
+	return nil
+}
+
+// InsertEvents adds pending delivery of every payload to each webhook of forum subscribed to event,
+// it is called with transaction of data change, so events are committed or rolled back together with data
+func InsertEvents(ctx context.Context, executer pgx4Helpers.Executer, forumSlug, event string,
+	payloads ...interface{}) error {
+
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	encoded := make([]string, 0, len(payloads))
+	for _, payload := range payloads {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		encoded = append(encoded, string(data))
+	}
+
+	if _, err := executer.Exec(ctx, sqlInsertEvents, forumSlug, event, encoded); err != nil {
+		return errors.Wrapf(err, "some error while inserting %s webhook events of forumSlug=%s", event, forumSlug)
+	}
+
+	return nil
+}
+
+func checkForumExists(ctx context.Context, querier pgx4Helpers.Querier, forumSlug string) error {
+	var exists bool
+	err := querier.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM forums WHERE slug = $1)`, forumSlug).Scan(&exists)
+	switch {
+	case err != nil:
+		return errors.WithStack(err)
+	case !exists:
+		return models.ErrDoesNotExist
+	}
+
+	return nil
+}
+
+func scanWebhook(scanner sqlHelpers.Scanner, hookDst *models.Webhook) error {
+	return scanner.Scan(
+		&hookDst.ID,
+		&hookDst.Forum,
+		&hookDst.URL,
+		&hookDst.Secret,
+		&hookDst.Events,
+		&hookDst.Created,
+	)
+}
+
+func scanDelivery(scanner sqlHelpers.Scanner, deliveryDst *models.WebhookDelivery) error {
+	var responseStatus *int32
+	var deliveryError *string
+
+	err := scanner.Scan(
+		&deliveryDst.ID,
+		&deliveryDst.Webhook,
+		&deliveryDst.Event,
+		&deliveryDst.Payload,
+		&deliveryDst.Status,
+		&deliveryDst.Attempts,
+		&deliveryDst.NextAttempt,
+		&responseStatus,
+		&deliveryError,
+		&deliveryDst.Created,
+		&deliveryDst.Delivered,
+	)
+	if err != nil {
+		return err
+	}
+
+	setNullable(deliveryDst, responseStatus, deliveryError)
+	return nil
+}
+
+func setNullable(deliveryDst *models.WebhookDelivery, responseStatus *int32, deliveryError *string) {
+	if responseStatus != nil {
+		deliveryDst.ResponseStatus = *responseStatus
+	}
+	if deliveryError != nil {
+		deliveryDst.Error = *deliveryError
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+const sqlSelectDelivery = `
+		SELECT id,
+			   webhook_id,
+			   event,
+			   payload,
+			   status,
+			   attempts,
+			   next_attempt,
+			   response_status,
+			   error,
+			   created,
+			   delivered
+		FROM webhook_deliveries`
+
+var sqlGetDeliveriesWithSince = map[bool]string{
+	true: sqlSelectDelivery + `
+		WHERE webhook_id = $1
+		  AND id < $2
+		ORDER BY id DESC
+		LIMIT $3`,
+
+	false: sqlSelectDelivery + `
+		WHERE webhook_id = $1
+		  AND id > $2
+		ORDER BY id
+		LIMIT $3`,
+}
+
+var sqlGetDeliveries = map[bool]string{
+	true: sqlSelectDelivery + `
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+		LIMIT $2`,
+
+	false: sqlSelectDelivery + `
+		WHERE webhook_id = $1
+		ORDER BY id
+		LIMIT $2`,
+}
+
+const sqlClaimDeliveries = `
+		UPDATE webhook_deliveries d
+		SET next_attempt = $2
+		FROM webhooks w
+		WHERE w.id = d.webhook_id
+		  AND d.id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending'
+			  AND next_attempt <= $1
+			ORDER BY next_attempt, id
+			LIMIT $3
+				FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id,
+				  d.webhook_id,
+				  d.event,
+				  d.payload,
+				  d.status,
+				  d.attempts,
+				  d.next_attempt,
+				  d.response_status,
+				  d.error,
+				  d.created,
+				  d.delivered,
+				  w.forum_slug,
+				  w.url,
+				  w.secret`
+
+const sqlInsertEvents = `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT w.id, $2::TEXT, p.payload::JSONB
+		FROM webhooks w
+				 CROSS JOIN UNNEST($3::TEXT[]) WITH ORDINALITY AS p(payload, n)
+		WHERE w.forum_slug = $1
+		  AND $2 = ANY (w.events)
+		ORDER BY p.n, w.id`
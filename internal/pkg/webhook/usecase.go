@@ -0,0 +1,15 @@
+package webhook
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+)
+
+type UseCase interface {
+	Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	GetByID(ctx context.Context, id int64) (models.Webhook, error)
+	GetByForumSlug(ctx context.Context, forumSlug string) (models.Webhooks, error)
+	DeleteByID(ctx context.Context, id int64) error
+	GetDeliveries(ctx context.Context, webhookID int64,
+		sinceID, desc, limit string) (models.WebhookDeliveries, error)
+}
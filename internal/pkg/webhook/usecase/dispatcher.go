@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/webhook"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"github.com/pkg/errors"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Forum-Event"
+	DeliveryHeader  = "X-Forum-Delivery"
+	SignatureHeader = "X-Forum-Signature"
+)
+
+// Dispatcher sends pending webhook deliveries, failed attempts are retried with exponential backoff
+type Dispatcher struct {
+	repository  webhook.Repository
+	client      *http.Client
+	batchSize   int32
+	maxAttempts int32
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	logger      logger.Logger
+}
+
+func NewDispatcher(repository webhook.Repository, requestTimeout time.Duration, batchSize, maxAttempts int32,
+	backoffBase, backoffMax time.Duration, logger logger.Logger) Dispatcher {
+
+	return Dispatcher{
+		repository:  repository,
+		client:      newClient(requestTimeout, int(batchSize), httpUtils.PublicDialControl),
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		backoffBase: backoffBase,
+		backoffMax:  backoffMax,
+		logger:      logger,
+	}
+}
+
+// newClient creates client of webhook receivers. Webhook urls are set by forum owners, so requests must not
+// reach internal services: dialed addresses are checked by control, proxy isn't used and redirects aren't followed.
+func newClient(timeout time.Duration, maxIdleConns int,
+	control func(network, address string, conn syscall.RawConn) error) *http.Client {
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: control,
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        maxIdleConns,
+			IdleConnTimeout:     time.Minute,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Timeout: timeout,
+	}
+}
+
+// WithDialControl returns dispatcher which checks dialed addresses by control instead of
+// httpUtils.PublicDialControl, nil control allows any address, e.g. local receivers of tests
+func (dispatcher Dispatcher) WithDialControl(
+	control func(network, address string, conn syscall.RawConn) error) Dispatcher {
+
+	dispatcher.client = newClient(dispatcher.client.Timeout, int(dispatcher.batchSize), control)
+	return dispatcher
+}
+
+// Sign returns value of signature header of body, receiver compares it with HMAC-SHA256 of body made with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run sends due deliveries every period until ctx is done
+func (dispatcher Dispatcher) Run(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		for {
+			sent, err := dispatcher.DispatchDue(ctx, time.Now())
+			if err != nil && ctx.Err() == nil {
+				dispatcher.logger.LogError(err, "cannot dispatch webhook deliveries")
+			}
+			// full batch means that more deliveries may be due already
+			if err != nil || sent < int(dispatcher.batchSize) {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue claims deliveries which are due at now, sends them concurrently and saves results,
+// it returns number of claimed deliveries
+func (dispatcher Dispatcher) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	// claimed deliveries are not claimed again until request timeout expires, even if instance dies
+	lease := 2 * dispatcher.client.Timeout
+	dispatches, err := dispatcher.repository.ClaimDeliveries(ctx, now, lease, dispatcher.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(dispatches))
+
+	for _, dispatch := range dispatches {
+		wg.Add(1)
+		go func(dispatch webhook.Dispatch) {
+			defer wg.Done()
+
+			delivery := dispatcher.send(ctx, dispatch)
+			// webhook may be deleted with its deliveries while request was in flight
+			if err := dispatcher.repository.SaveAttempt(ctx, delivery); err != nil &&
+				!errors.Is(err, models.ErrDoesNotExist) {
+				errs <- err
+			}
+		}(dispatch)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	return len(dispatches), <-errs
+}
+
+// send makes attempt of delivery and returns delivery with its result
+func (dispatcher Dispatcher) send(ctx context.Context, dispatch webhook.Dispatch) models.WebhookDelivery {
+	delivery := dispatch.Delivery
+	delivery.Attempts++
+	delivery.ResponseStatus = 0
+	delivery.Error = ""
+
+	responseStatus, err := dispatcher.post(ctx, dispatch)
+	delivery.ResponseStatus = int32(responseStatus)
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliveredWebhookDelivery
+		delivery.Delivered = &now
+		return delivery
+
+	case delivery.Attempts >= dispatcher.maxAttempts:
+		delivery.Status = models.FailedWebhookDelivery
+	default:
+		delivery.Status = models.PendingWebhookDelivery
+	}
+
+	delivery.Error = err.Error()
+	delivery.NextAttempt = now.Add(dispatcher.Backoff(delivery.Attempts))
+
+	return delivery
+}
+
+// post sends delivery and returns status of response, response without 2xx status is error.
+// Response body is not stored, so webhook can't be used to read responses of other servers.
+func (dispatcher Dispatcher) post(ctx context.Context, dispatch webhook.Dispatch) (int, error) {
+	body, err := models.WebhookPayload{
+		Delivery: dispatch.Delivery.ID,
+		Event:    dispatch.Delivery.Event,
+		Forum:    dispatch.Forum,
+		Data:     dispatch.Delivery.Payload,
+	}.MarshalJSON()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, dispatch.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, dispatch.Delivery.Event)
+	request.Header.Set(DeliveryHeader, fmt.Sprint(dispatch.Delivery.ID))
+	request.Header.Set(SignatureHeader, Sign(dispatch.Secret, body))
+
+	response, err := dispatcher.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	_ = response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, errors.Errorf("unexpected response status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
+
+// Backoff returns delay after failed attempt with number attempts
+func (dispatcher Dispatcher) Backoff(attempts int32) time.Duration {
+	delay := dispatcher.backoffBase
+	for i := int32(1); i < attempts && delay < dispatcher.backoffMax; i++ {
+		delay *= 2
+	}
+
+	if delay > dispatcher.backoffMax {
+		delay = dispatcher.backoffMax
+	}
+
+	return delay
+}
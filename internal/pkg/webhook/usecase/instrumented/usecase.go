@@ -0,0 +1,56 @@
+package instrumented
+
+import (
+	"context"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/utils/instrumentation"
+	"github.com/nickeskov/db_forum/internal/pkg/webhook"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const useCaseName = "webhook"
+
+// UseCase traces every method of wrapped usecase
+type UseCase struct {
+	useCase  webhook.UseCase
+	observer instrumentation.Observer
+}
+
+func NewUseCase(useCase webhook.UseCase, tracer trace.Tracer) UseCase {
+	return UseCase{
+		useCase:  useCase,
+		observer: instrumentation.NewObserver(useCaseName, "usecase", nil, tracer),
+	}
+}
+
+func (useCase UseCase) Create(ctx context.Context, webhook models.Webhook) (_ models.Webhook, err error) {
+	ctx, done := useCase.observer.Start(ctx, "Create")
+	defer func() { done(err) }()
+	return useCase.useCase.Create(ctx, webhook)
+}
+
+func (useCase UseCase) GetByID(ctx context.Context, id int64) (_ models.Webhook, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GetByID")
+	defer func() { done(err) }()
+	return useCase.useCase.GetByID(ctx, id)
+}
+
+func (useCase UseCase) GetByForumSlug(ctx context.Context, forumSlug string) (_ models.Webhooks, err error) {
+	ctx, done := useCase.observer.Start(ctx, "GetByForumSlug")
+	defer func() { done(err) }()
+	return useCase.useCase.GetByForumSlug(ctx, forumSlug)
+}
+
+func (useCase UseCase) DeleteByID(ctx context.Context, id int64) (err error) {
+	ctx, done := useCase.observer.Start(ctx, "DeleteByID")
+	defer func() { done(err) }()
+	return useCase.useCase.DeleteByID(ctx, id)
+}
+
+func (useCase UseCase) GetDeliveries(ctx context.Context,
+	webhookID int64, sinceID, desc, limit string) (_ models.WebhookDeliveries, err error) {
+
+	ctx, done := useCase.observer.Start(ctx, "GetDeliveries")
+	defer func() { done(err) }()
+	return useCase.useCase.GetDeliveries(ctx, webhookID, sinceID, desc, limit)
+}
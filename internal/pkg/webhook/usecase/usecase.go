@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/internal/pkg/webhook"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/pkg/errors"
+	"net/url"
+	"strconv"
+)
+
+const secretSize = 32
+
+type UseCase struct {
+	repository webhook.Repository
+}
+
+func NewUseCase(repository webhook.Repository) UseCase {
+	return UseCase{
+		repository: repository,
+	}
+}
+
+// Create registers webhook, random secret is generated if it is empty.
+// Secret is returned only by Create. Host of url must resolve only to public addresses,
+// otherwise models.ErrValidation is returned.
+func (useCase UseCase) Create(ctx context.Context, hook models.Webhook) (models.Webhook, error) {
+	if err := hook.Validate(); err != nil {
+		return models.Webhook{}, err
+	}
+
+	// url is already validated
+	if hookURL, _ := url.Parse(hook.URL); httpUtils.CheckPublicHost(ctx, hookURL.Hostname()) != nil {
+		return models.Webhook{}, models.ErrValidation
+	}
+
+	if hook.Secret == "" {
+		secret := make([]byte, secretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return models.Webhook{}, errors.WithStack(err)
+		}
+		hook.Secret = hex.EncodeToString(secret)
+	}
+
+	return useCase.repository.Create(ctx, hook)
+}
+
+func (useCase UseCase) GetByID(ctx context.Context, id int64) (models.Webhook, error) {
+	hook, err := useCase.repository.GetByID(ctx, id)
+	hook.Secret = ""
+	return hook, err
+}
+
+func (useCase UseCase) GetByForumSlug(ctx context.Context, forumSlug string) (models.Webhooks, error) {
+	hooks, err := useCase.repository.GetByForumSlug(ctx, forumSlug)
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	return hooks, err
+}
+
+func (useCase UseCase) DeleteByID(ctx context.Context, id int64) error {
+	return useCase.repository.DeleteByID(ctx, id)
+}
+
+func (useCase UseCase) GetDeliveries(ctx context.Context, webhookID int64,
+	sinceID, desc, limit string) (models.WebhookDeliveries, error) {
+
+	var sinceIDPtr *int64
+	if sinceID != "" {
+		sinceIDInt, err := strconv.ParseInt(sinceID, 10, 64)
+		if err != nil {
+			return nil, models.ErrInvalid
+		}
+		sinceIDPtr = &sinceIDInt
+	}
+
+	descBool, boolErr := strconv.ParseBool(desc)
+	limitInt, intErr := strconv.ParseInt(limit, 10, 64)
+	if boolErr != nil || intErr != nil || limitInt < 0 {
+		return nil, models.ErrInvalid
+	}
+
+	return useCase.repository.GetDeliveries(ctx, webhookID, sinceIDPtr, descBool, limitInt)
+}
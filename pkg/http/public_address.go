@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"net"
+	"syscall"
+)
+
+// ErrNotPublicAddress is returned for address of loopback, private, link-local or other not public network
+var ErrNotPublicAddress = errors.New("address is not public")
+
+// reservedNetworks are not public networks which are not recognized by net.IP methods
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // this network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved and broadcast
+	"64:ff9b::/96",  // NAT64, it maps to IPv4 addresses which may be private
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// IsPublicIP reports whether ip is public unicast address, so request to it can't reach internal services
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsMulticast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckPublicHost resolves host and returns ErrNotPublicAddress if any of its addresses is not public
+func CheckPublicHost(ctx context.Context, host string) error {
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return errors.Wrapf(err, "cannot resolve host %s", host)
+	}
+
+	for _, ip := range ips {
+		if !IsPublicIP(ip) {
+			return errors.Wrapf(ErrNotPublicAddress, "host %s has address %s", host, ip)
+		}
+	}
+
+	return nil
+}
+
+// PublicDialControl is net.Dialer Control which refuses connections to not public addresses.
+// It checks address which is really dialed, so host can't be rebound to internal address after CheckPublicHost.
+func PublicDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if ip := net.ParseIP(host); !IsPublicIP(ip) {
+		return errors.Wrapf(ErrNotPublicAddress, "connection to %s is refused", host)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for _, test := range []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"0.1.2.3", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"64:ff9b::a00:1", false},
+	} {
+		if public := IsPublicIP(net.ParseIP(test.ip)); public != test.public {
+			t.Errorf("IsPublicIP(%s) = %v, expected %v", test.ip, public, test.public)
+		}
+	}
+
+	if IsPublicIP(nil) {
+		t.Errorf("nil ip is public")
+	}
+}
+
+func TestPublicDialControl(t *testing.T) {
+	for _, test := range []struct {
+		address string
+		public  bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:80", true},
+		{"127.0.0.1:5432", false},
+		{"[::1]:80", false},
+		{"169.254.169.254:80", false},
+	} {
+		err := PublicDialControl("tcp", test.address, nil)
+		if test.public && err != nil {
+			t.Errorf("connection to %s is refused: %v", test.address, err)
+		}
+		if !test.public && !errors.Is(err, ErrNotPublicAddress) {
+			t.Errorf("connection to %s is not refused: %v", test.address, err)
+		}
+	}
+}
+
+func TestCheckPublicHost(t *testing.T) {
+	for _, host := range []string{"localhost", "127.0.0.1", "10.0.0.1", "::1"} {
+		if err := CheckPublicHost(context.Background(), host); err == nil {
+			t.Errorf("host %s is public", host)
+		}
+	}
+}
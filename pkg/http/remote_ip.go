@@ -0,0 +1,85 @@
+package http
+
+import (
+	"github.com/pkg/errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies are networks of reverse proxies, X-Forwarded-For is honored only when request came from them
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses CIDRs or single IPs
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, errors.Errorf("invalid proxy address %q", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid proxy network %q", value)
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
+
+func (proxies TrustedProxies) contains(ip net.IP) bool {
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteIP returns client address of request. If connection came from trusted proxy, X-Forwarded-For
+// is walked from right to left and the first address which is not trusted proxy is returned,
+// because only the right part of header is appended by our proxies and the left part may be forged by client.
+func (proxies TrustedProxies) RemoteIP(r *http.Request) string {
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+
+	ip := net.ParseIP(remoteIP)
+	if ip == nil || !proxies.contains(ip) {
+		return remoteIP
+	}
+
+	var forwarded []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(header, ",")...)
+	}
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		hopIP := net.ParseIP(hop)
+		if hopIP == nil {
+			// malformed hop is not trusted, the last valid address is returned
+			return remoteIP
+		}
+
+		remoteIP = hop
+		if !proxies.contains(hopIP) {
+			break
+		}
+	}
+
+	return remoteIP
+}
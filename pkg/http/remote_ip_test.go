@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	for _, test := range []struct {
+		values []string
+		valid  bool
+	}{
+		{nil, true},
+		{[]string{"10.0.0.1", " 10.1.0.0/16 ", "fd00::1", "fd00::/8"}, true},
+		{[]string{"proxy.local"}, false},
+		{[]string{"10.0.0.0/33"}, false},
+		{[]string{""}, false},
+	} {
+		_, err := ParseTrustedProxies(test.values)
+		if (err == nil) != test.valid {
+			t.Errorf("expected valid %v for %q, got error %v", test.valid, test.values, err)
+		}
+	}
+}
+
+func TestRemoteIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.1", "192.168.0.0/16", "fd00::/8"})
+	if err != nil {
+		t.Fatalf("cannot parse trusted proxies: %+v", err)
+	}
+
+	for _, test := range []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		expected   string
+	}{
+		{"DirectClient", "203.0.113.7:5555", nil, "203.0.113.7"},
+		// client which is not trusted proxy can't set its address
+		{"SpoofedByDirectClient", "203.0.113.7:5555", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"TrustedProxyWithoutHeader", "10.0.0.1:5555", nil, "10.0.0.1"},
+		{"TrustedProxy", "10.0.0.1:5555", []string{"203.0.113.7"}, "203.0.113.7"},
+		{"ChainOfTrustedProxies", "10.0.0.1:5555", []string{"203.0.113.7, 192.168.1.1"}, "203.0.113.7"},
+		// client prepends forged address, proxy appends real client address
+		{"SpoofedBehindTrustedProxy", "10.0.0.1:5555", []string{"198.51.100.1, 203.0.113.7"}, "203.0.113.7"},
+		{"SpoofedTrustedProxyAddress", "10.0.0.1:5555", []string{"10.0.0.1, 203.0.113.7"}, "203.0.113.7"},
+		{"SpoofedInSeparateHeader", "10.0.0.1:5555", []string{"198.51.100.1", "203.0.113.7"}, "203.0.113.7"},
+		{"AllHopsAreTrusted", "10.0.0.1:5555", []string{"192.168.1.2, 192.168.1.1"}, "192.168.1.2"},
+		{"MalformedHop", "10.0.0.1:5555", []string{"203.0.113.7, unknown"}, "10.0.0.1"},
+		{"MalformedHopBeforeClient", "10.0.0.1:5555", []string{"unknown, 203.0.113.7"}, "203.0.113.7"},
+		{"MalformedHopAfterTrustedProxy", "10.0.0.1:5555", []string{"garbage, 192.168.1.1"}, "192.168.1.1"},
+		{"IPv6TrustedProxy", "[fd00::1]:5555", []string{"2001:db8::7"}, "2001:db8::7"},
+		{"IPv6Client", "[2001:db8::7]:5555", []string{"198.51.100.1"}, "2001:db8::7"},
+		{"RemoteAddrWithoutPort", "10.0.0.1", []string{"203.0.113.7"}, "203.0.113.7"},
+		{"NotIPRemoteAddr", "@", []string{"203.0.113.7"}, "@"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/service/status", nil)
+			r.RemoteAddr = test.remoteAddr
+			for _, header := range test.forwarded {
+				r.Header.Add("X-Forwarded-For", header)
+			}
+
+			if remoteIP := proxies.RemoteIP(r); remoteIP != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, remoteIP)
+			}
+		})
+	}
+}
+
+func TestRemoteIPWithoutTrustedProxies(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/service/status", nil)
+	r.RemoteAddr = "10.0.0.1:5555"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+	if remoteIP := TrustedProxies(nil).RemoteIP(r); remoteIP != "10.0.0.1" {
+		t.Fatalf("expected connection address, got %s", remoteIP)
+	}
+}
@@ -2,14 +2,26 @@ package http
 
 import (
 	"encoding/json"
-	"github.com/nickeskov/db_forum/internal/pkg/models"
+	"github.com/nickeskov/db_forum/pkg/requestid"
 	"net/http"
 )
 
+// errorResponse is body of error response, it has the same json fields as models.Error
+type errorResponse struct {
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// WriteResponseError writes error body with msg, request id is taken from X-Request-ID response header
 func WriteResponseError(w http.ResponseWriter, code int, msg string) error {
+	errorModel := errorResponse{
+		Message:   msg,
+		RequestID: w.Header().Get(requestid.Header),
+	}
+
 	w.WriteHeader(code)
 
-	data, err := json.Marshal(models.NewError(msg))
+	data, err := json.Marshal(errorModel)
 	if err != nil {
 		code = http.StatusInternalServerError
 		http.Error(w, http.StatusText(code), code)
@@ -1,6 +1,11 @@
 package http
 
-import "net/http"
+import (
+	"bufio"
+	"github.com/pkg/errors"
+	"net"
+	"net/http"
+)
 
 type ResponseWriter struct {
 	http.ResponseWriter
@@ -44,3 +49,18 @@ func (w *ResponseWriter) TryFlusher() (http.Flusher, bool) {
 	flusher, ok := w.ResponseWriter.(http.Flusher)
 	return flusher, ok
 }
+
+// Hijack takes over connection, e.g. for websocket, response status is reported as 101 Switching Protocols
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		w.statusCode = http.StatusSwitchingProtocols
+	}
+
+	return conn, rw, err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"net/http"
 	"reflect"
@@ -15,7 +16,7 @@ type Logger interface {
 	GetRequestIdKey() RequestIDKeyType
 	GetRequestIdFromContext(ctx context.Context) string
 	StartRequest(r http.Request, requestId string)
-	EndRequest(start time.Time, ctx context.Context)
+	LogAccess(ctx context.Context, entry AccessEntry)
 	HttpInfo(ctx context.Context, msg string, status int)
 	HttpLogWarning(ctx context.Context, pkg string, funcName string, warn string)
 	HttpLogError(ctx context.Context, pkg string, funcName string, err error)
@@ -26,6 +27,18 @@ type Logger interface {
 
 type RequestIDKeyType interface{}
 
+// AccessEntry is access log line of finished request
+type AccessEntry struct {
+	Method    string
+	Route     string
+	URI       string
+	Status    int
+	Bytes     int
+	Latency   time.Duration
+	RemoteIP  string
+	UserAgent string
+}
+
 type SimpleLogger struct {
 	*logrus.Logger
 	requestIDKey RequestIDKeyType
@@ -69,6 +82,17 @@ func (logger SimpleLogger) GetRequestIdFromContext(ctx context.Context) string {
 	return requestId
 }
 
+// withRequest returns entry with request id and trace id of current span if request is traced
+func (logger SimpleLogger) withRequest(ctx context.Context) *logrus.Entry {
+	fields := logrus.Fields{
+		"id": logger.GetRequestIdFromContext(ctx),
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields["trace_id"] = spanContext.TraceID().String()
+	}
+	return logger.WithFields(fields)
+}
+
 func (logger SimpleLogger) StartRequest(r http.Request, requestId string) {
 	logger.WithFields(logrus.Fields{
 		"id":         requestId,
@@ -76,34 +100,43 @@ func (logger SimpleLogger) StartRequest(r http.Request, requestId string) {
 		"req_URI":    r.RequestURI,
 		"method":     r.Method,
 		"user_agent": r.UserAgent(),
-	}).Info("request started")
+	}).Debug("request started")
 }
 
-func (logger SimpleLogger) EndRequest(start time.Time, ctx context.Context) {
-	logger.WithFields(logrus.Fields{
-		"id":              logger.GetRequestIdFromContext(ctx),
-		"elapsed_time,μs": time.Since(start).Microseconds(),
-	}).Info("request ended")
+// LogAccess logs finished request, responses with 5xx status are logged with warning level
+func (logger SimpleLogger) LogAccess(ctx context.Context, entry AccessEntry) {
+	level := logrus.InfoLevel
+	if entry.Status >= http.StatusInternalServerError {
+		level = logrus.WarnLevel
+	}
+
+	logger.withRequest(ctx).WithFields(logrus.Fields{
+		"method":          entry.Method,
+		"route":           entry.Route,
+		"req_URI":         entry.URI,
+		"status":          entry.Status,
+		"bytes":           entry.Bytes,
+		"elapsed_time,μs": entry.Latency.Microseconds(),
+		"usr_addr":        entry.RemoteIP,
+		"user_agent":      entry.UserAgent,
+	}).Log(level, "request ended")
 }
 
 func (logger SimpleLogger) HttpInfo(ctx context.Context, msg string, status int) {
-	logger.WithFields(logrus.Fields{
-		"id":     logger.GetRequestIdFromContext(ctx),
+	logger.withRequest(ctx).WithFields(logrus.Fields{
 		"status": status,
 	}).Info(msg)
 }
 
 func (logger SimpleLogger) HttpLogWarning(ctx context.Context, pkg string, funcName string, warn string) {
-	logger.WithFields(logrus.Fields{
-		"id":       logger.GetRequestIdFromContext(ctx),
+	logger.withRequest(ctx).WithFields(logrus.Fields{
 		"package":  pkg,
 		"function": funcName,
 	}).Warn(warn)
 }
 
 func (logger SimpleLogger) HttpLogError(ctx context.Context, pkg string, funcName string, err error) {
-	logger.WithFields(logrus.Fields{
-		"id":       logger.GetRequestIdFromContext(ctx),
+	logger.withRequest(ctx).WithFields(logrus.Fields{
 		"package":  pkg,
 		"function": funcName,
 	}).Error(errors.Cause(err))
@@ -116,8 +149,7 @@ func (logger SimpleLogger) HttpLogCallerError(ctx context.Context, objForPkgPath
 	frames := runtime.CallersFrames(pc[:n])
 	callerFrame, _ := frames.Next()
 
-	logger.WithFields(logrus.Fields{
-		"id":       logger.GetRequestIdFromContext(ctx),
+	logger.withRequest(ctx).WithFields(logrus.Fields{
 		"package":  reflect.TypeOf(objForPkgPath).PkgPath(),
 		"file":     callerFrame.File,
 		"function": callerFrame.Function,
@@ -126,9 +158,7 @@ func (logger SimpleLogger) HttpLogCallerError(ctx context.Context, objForPkgPath
 }
 
 func (logger SimpleLogger) HttpLogInfo(ctx context.Context, msg string) {
-	logger.WithFields(logrus.Fields{
-		"id": logger.GetRequestIdFromContext(ctx),
-	}).Info(msg)
+	logger.withRequest(ctx).Info(msg)
 }
 
 func (logger SimpleLogger) LogError(err error, msg string) {
@@ -1,39 +1,107 @@
 package middleware
 
 import (
-	"context"
-	crand "crypto/rand"
-	"fmt"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
 	"github.com/nickeskov/db_forum/pkg/logger"
-	"math/big"
-	"math/rand"
+	"github.com/nickeskov/db_forum/pkg/requestid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
+	"sync"
 	"time"
 )
 
-func CreateAccessLogMiddleware(log logger.Logger) func(next http.Handler) http.Handler {
+// CreateAccessLogMiddleware logs every request with its route, status, response size, latency and client address.
+// Request id is taken from X-Request-ID header if it is valid, otherwise trace id of server span
+// started by tracing middleware is used or new one is generated.
+// Id is put to context and echoed in X-Request-ID response header.
+// Successful requests are sampled: first sampleInitial of them are logged every second and every
+// sampleThereafter one after that, zero sampleInitial disables sampling. Failed requests are always logged.
+func CreateAccessLogMiddleware(log logger.Logger, proxies httpUtils.TrustedProxies,
+	sampleInitial, sampleThereafter int) func(next http.Handler) http.Handler {
+
+	sampler := newAccessSampler(sampleInitial, sampleThereafter)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			rand.Seed(time.Now().UnixNano())
-			ctx := r.Context()
-			nBig, err := crand.Int(crand.Reader, big.NewInt(99999999))
-			if err != nil {
-				log.HttpLogCallerError(ctx, ctx, err)
+			start := time.Now()
+
+			// request without valid request id gets trace id as request id,
+			// so log entries of traced request are found by trace id
+			span := trace.SpanFromContext(r.Context())
+			requestID := r.Header.Get(requestid.Header)
+			if !requestid.Valid(requestID) {
+				if spanContext := span.SpanContext(); spanContext.IsValid() {
+					requestID = spanContext.TraceID().String()
+				} else {
+					requestID = requestid.New()
+				}
 			}
-			requestID := fmt.Sprintf("%016x", nBig.String())[:6]
+			w.Header().Set(requestid.Header, requestID)
+			span.SetAttributes(attribute.String("request.id", requestID))
 
 			log.StartRequest(*r, requestID)
 
-			start := time.Now()
-			ctx = context.WithValue(
-				ctx,
-				log.GetRequestIdKey(),
-				requestID,
-			)
+			ctx := requestid.NewContext(r.Context(), requestID)
+			writer := &httpUtils.ResponseWriter{ResponseWriter: w}
 
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(writer, r.WithContext(ctx))
 
-			log.EndRequest(start, ctx)
+			statusCode := writer.GetStatusCode()
+			if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+			if statusCode < http.StatusBadRequest && !sampler.sample(start) {
+				return
+			}
+
+			log.LogAccess(ctx, logger.AccessEntry{
+				Method:    r.Method,
+				Route:     routeTemplate(r),
+				URI:       r.RequestURI,
+				Status:    statusCode,
+				Bytes:     writer.GetResponseLength(),
+				Latency:   time.Since(start),
+				RemoteIP:  proxies.RemoteIP(r),
+				UserAgent: r.UserAgent(),
+			})
 		})
 	}
 }
+
+// accessSampler counts requests in one second windows, so logging costs are limited under high load
+type accessSampler struct {
+	initial    int
+	thereafter int
+
+	mu     sync.Mutex
+	window int64
+	count  int
+}
+
+func newAccessSampler(initial, thereafter int) *accessSampler {
+	return &accessSampler{
+		initial:    initial,
+		thereafter: thereafter,
+	}
+}
+
+func (sampler *accessSampler) sample(now time.Time) bool {
+	if sampler.initial <= 0 {
+		return true
+	}
+
+	sampler.mu.Lock()
+	defer sampler.mu.Unlock()
+
+	if window := now.Unix(); window != sampler.window {
+		sampler.window = window
+		sampler.count = 0
+	}
+	sampler.count++
+
+	if sampler.count <= sampler.initial {
+		return true
+	}
+	return sampler.thereafter > 0 && (sampler.count-sampler.initial)%sampler.thereafter == 0
+}
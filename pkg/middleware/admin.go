@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"net/http"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+// CreateAdminTokenMiddleware allows requests only with admin token in X-Admin-Token header.
+// Empty token disables admin routes at all.
+func CreateAdminTokenMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestToken := r.Header.Get(AdminTokenHeader)
+
+			if token == "" || subtle.ConstantTimeCompare([]byte(requestToken), []byte(token)) != 1 {
+				_ = httpUtils.WriteResponseError(w, http.StatusForbidden, "admin token is required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
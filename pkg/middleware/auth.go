@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/nickeskov/db_forum/pkg/logger"
+	"net/http"
+	"strings"
+)
+
+const (
+	AuthorizationHeader = "Authorization"
+	bearerScheme        = "Bearer"
+)
+
+type authenticatedNicknameKey struct{}
+
+// BearerToken returns token from "Authorization: Bearer <token>" header
+func BearerToken(r *http.Request) (string, bool) {
+	fields := strings.Fields(r.Header.Get(AuthorizationHeader))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], bearerScheme) {
+		return "", false
+	}
+	return fields[1], true
+}
+
+// AuthenticatedNickname returns nickname of caller put into context by auth middleware
+func AuthenticatedNickname(ctx context.Context) (string, bool) {
+	nickname, ok := ctx.Value(authenticatedNicknameKey{}).(string)
+	return nickname, ok
+}
+
+func WithAuthenticatedNickname(ctx context.Context, nickname string) context.Context {
+	return context.WithValue(ctx, authenticatedNicknameKey{}, nickname)
+}
+
+// CreateAuthMiddleware puts nickname of bearer token owner into request context.
+// Requests without token pass as anonymous, requests with unknown or expired token are rejected,
+// so client learns that it must login again. Authenticate must return error matching invalidTokenErr for bad token.
+// Token is not checked for routes of skipRoutes keyed by RouteKey, e.g. login, so client with stale token
+// isn't locked out of them.
+func CreateAuthMiddleware(authenticate func(ctx context.Context, token string) (string, error),
+	invalidTokenErr error, skipRoutes map[string]bool, log logger.Logger) func(http.Handler) http.Handler {
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := BearerToken(r)
+			if !ok || skipRoutes[RouteKey(r.Method, routeTemplate(r))] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+
+			nickname, err := authenticate(ctx, token)
+			switch {
+			case errors.Is(err, invalidTokenErr):
+				w.Header().Set("WWW-Authenticate", bearerScheme)
+				_ = httpUtils.WriteResponseError(w, http.StatusUnauthorized, "invalid or expired session token")
+
+			case err != nil:
+				log.HttpLogCallerError(ctx, err, err)
+				_ = httpUtils.WriteResponseError(w, http.StatusInternalServerError, err.Error())
+
+			default:
+				next.ServeHTTP(w, r.WithContext(WithAuthenticatedNickname(ctx, nickname)))
+			}
+		})
+	}
+}
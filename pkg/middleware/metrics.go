@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// CreateMetricsMiddleware counts requests and observes their latency and response size
+// by method, mux route template and status code. Metrics names start with namespace.
+func CreateMetricsMiddleware(registerer prometheus.Registerer, namespace string) func(http.Handler) http.Handler {
+	labels := []string{"method", "route", "status"}
+
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Count of handled http requests.",
+	}, labels)
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of http requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, labels)
+	sizes := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_response_size_bytes",
+		Help:      "Size of http responses body.",
+		Buckets:   prometheus.ExponentialBuckets(100, 10, 6),
+	}, labels)
+	registerer.MustRegister(requests, durations, sizes)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			writer := &httpUtils.ResponseWriter{ResponseWriter: w}
+
+			next.ServeHTTP(writer, r)
+
+			route := routeTemplate(r)
+
+			statusCode := writer.GetStatusCode()
+			if statusCode == 0 {
+				statusCode = http.StatusOK // server sends 200 if handler writes nothing
+			}
+			status := strconv.Itoa(statusCode)
+
+			requests.WithLabelValues(r.Method, route, status).Inc()
+			durations.WithLabelValues(r.Method, route, status).Observe(time.Since(start).Seconds())
+			sizes.WithLabelValues(r.Method, route, status).Observe(float64(writer.GetResponseLength()))
+		})
+	}
+}
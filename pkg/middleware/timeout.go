@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"net/http"
+	"time"
+)
+
+// RouteKey builds key for per route settings, e.g. "POST /api/thread/{slug_or_id}/create"
+func RouteKey(method, pathTemplate string) string {
+	return method + " " + pathTemplate
+}
+
+// routeTemplate returns path template of matched mux route or "unknown"
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if pathTemplate, err := route.GetPathTemplate(); err == nil {
+			return pathTemplate
+		}
+	}
+	return "unknown"
+}
+
+// CreateRouteTimeoutMiddleware sets deadline for request context, so all database queries
+// of the request are cancelled when it expires. Timeout is looked up in routeTimeouts by RouteKey
+// of matched mux route, otherwise defaultTimeout is used. Zero timeout means no deadline.
+func CreateRouteTimeoutMiddleware(defaultTimeout time.Duration,
+	routeTimeouts map[string]time.Duration) func(http.Handler) http.Handler {
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := defaultTimeout
+
+			if route := mux.CurrentRoute(r); route != nil {
+				if pathTemplate, err := route.GetPathTemplate(); err == nil {
+					if routeTimeout, ok := routeTimeouts[RouteKey(r.Method, pathTemplate)]; ok {
+						timeout = routeTimeout
+					}
+				}
+			}
+
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
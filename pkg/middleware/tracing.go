@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	httpUtils "github.com/nickeskov/db_forum/pkg/http"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// CreateTracingMiddleware starts server span of every request, parent is taken from traceparent header.
+// It must wrap access log middleware, which takes request id from span.
+func CreateTracingMiddleware(tracer trace.Tracer) func(http.Handler) http.Handler {
+	propagator := propagation.TraceContext{}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := routeTemplate(r)
+
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Method+" "+route, trace.WithSpanKind(trace.SpanKindServer))
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("http.target", r.URL.RequestURI()),
+				attribute.String("http.user_agent", r.UserAgent()),
+			)
+
+			writer := &httpUtils.ResponseWriter{ResponseWriter: w}
+
+			next.ServeHTTP(writer, r.WithContext(ctx))
+
+			statusCode := writer.GetStatusCode()
+			if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+			span.SetAttributes(attribute.Int("http.status_code", statusCode))
+			if statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(statusCode))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Package requestid generates and validates request ids and passes them in context,
+// so log entries, error responses and database sessions of one request can be matched.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header is accepted from client and echoed in response
+const Header = "X-Request-ID"
+
+// maxLength keeps id short enough for postgres application_name, which is limited by 63 bytes
+const maxLength = 48
+
+// ContextKey is key of request id in context, logger is created with it as request id key
+type ContextKey struct{}
+
+// New returns random UUID version 4
+func New() string {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		panic(err) // crypto/rand fails only if system random source is broken
+	}
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+
+	return string(buf[:])
+}
+
+// Valid reports whether id from client may be used as request id: it must be not longer than 48 characters
+// of letters, digits, '-', '_', '.' and ':', so it is safe to put it to logs and headers
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for _, c := range []byte(id) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ContextKey{}, id)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ContextKey{}).(string)
+	return id, ok
+}
@@ -0,0 +1,64 @@
+package requestid
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	cases := []struct {
+		name  string
+		id    string
+		valid bool
+	}{
+		{"UUID", "0f8fad5b-d9cb-469f-a165-70867728950e", true},
+		{"AllowedPunctuation", "req_1.2:3-4", true},
+		{"SingleChar", "a", true},
+		{"MaxLength", strings.Repeat("a", maxLength), true},
+		{"TooLong", strings.Repeat("a", maxLength+1), false},
+		{"Empty", "", false},
+		{"Space", "req 1", false},
+		{"NewLine", "req\nlevel=error", false},
+		{"CarriageReturn", "req\r\nX-Admin-Token: 1", false},
+		{"Quote", `req"1`, false},
+		{"Slash", "req/1", false},
+		{"Equals", "id=1", false},
+		{"NonASCIILetter", "запрос", false},
+		// multibyte characters are counted in bytes
+		{"NonASCIIWithinLength", strings.Repeat("a", maxLength-1) + "é", false},
+	}
+
+	for _, tc := range cases {
+		if valid := Valid(tc.id); valid != tc.valid {
+			t.Errorf("%s: expected valid %v for %q, got %v", tc.name, tc.valid, tc.id, valid)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	uuidV4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ids := make(map[string]bool)
+
+	for i := 0; i < 100; i++ {
+		id := New()
+		if !uuidV4.MatchString(id) || !Valid(id) {
+			t.Fatalf("id %q is not valid UUID version 4", id)
+		}
+		if ids[id] {
+			t.Fatalf("id %q is repeated", id)
+		}
+		ids[id] = true
+	}
+}
+
+func TestContext(t *testing.T) {
+	if id, ok := FromContext(context.Background()); ok {
+		t.Fatalf("context without id has id %q", id)
+	}
+
+	if id, ok := FromContext(NewContext(context.Background(), "req-1")); !ok || id != "req-1" {
+		t.Fatalf("expected id req-1 in context, got %q, %v", id, ok)
+	}
+}
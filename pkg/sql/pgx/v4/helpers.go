@@ -7,6 +7,10 @@ import (
 
 func FinishPgx4Transaction(ctx context.Context, tx Transactioner, err error) error {
 	if err != nil {
+		// context may be already cancelled, but rollback must reach the server anyway
+		if ctx.Err() != nil {
+			ctx = context.Background()
+		}
 		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
 			err = errors.Wrap(err, rollbackErr.Error())
 		}
@@ -0,0 +1,34 @@
+package v4
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/nickeskov/db_forum/pkg/requestid"
+)
+
+// maxApplicationNameLength is limit of postgres, longer names are truncated by server
+const maxApplicationNameLength = 63
+
+// TagSessionWithRequestID returns pgxpool BeforeAcquire hook which sets application_name of connection
+// to "<applicationName>:<request id>" of context, so server logs and pg_stat_activity show request of query.
+// Connection acquired without request id gets plain applicationName. Name is changed only if it differs,
+// server reports current application_name, so extra round trip is made only when it is changed.
+func TagSessionWithRequestID(applicationName string) func(ctx context.Context, conn *pgx.Conn) bool {
+	return func(ctx context.Context, conn *pgx.Conn) bool {
+		name := applicationName
+		if id, ok := requestid.FromContext(ctx); ok {
+			name += ":" + id
+		}
+		if len(name) > maxApplicationNameLength {
+			name = name[:maxApplicationNameLength]
+		}
+
+		if conn.PgConn().ParameterStatus("application_name") == name {
+			return true
+		}
+
+		// tag is best effort, broken connection fails on the next query anyway
+		_, _ = conn.Exec(ctx, "SELECT set_config('application_name', $1, false)", name)
+		return true
+	}
+}
@@ -0,0 +1,63 @@
+package v4
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// tracedMessages are pgx log messages of finished or failed queries, other messages are ignored
+var tracedMessages = map[string]bool{
+	"Query":             true,
+	"Exec":              true,
+	"Prepare failed":    true,
+	"BatchResult.Exec":  true,
+	"BatchResult.Query": true,
+	"BatchResult.Close": true,
+}
+
+// TracingLogger records span for every query reported by pgx, pgx v4 has no query hooks except of logger.
+// Log level of connection must be at least pgx.LogLevelInfo. Query arguments are not recorded.
+type TracingLogger struct {
+	tracer trace.Tracer
+}
+
+func NewTracingLogger(tracer trace.Tracer) TracingLogger {
+	return TracingLogger{
+		tracer: tracer,
+	}
+}
+
+func (logger TracingLogger) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	if !tracedMessages[msg] || !trace.SpanFromContext(ctx).IsRecording() {
+		return
+	}
+
+	// pgx logs queries after their end, batch results are logged without time
+	elapsed, _ := data["time"].(time.Duration)
+
+	_, span := logger.tracer.Start(ctx, "pgx."+msg,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithTimestamp(time.Now().Add(-elapsed)))
+	defer span.End()
+
+	span.SetAttributes(attribute.String("db.system", "postgresql"))
+	if sql, ok := data["sql"].(string); ok {
+		span.SetAttributes(attribute.String("db.statement", sql))
+	}
+	if rowCount, ok := data["rowCount"].(int); ok {
+		span.SetAttributes(attribute.Int("db.rows", rowCount))
+	}
+	if pid, ok := data["pid"].(uint32); ok {
+		span.SetAttributes(attribute.Int64("db.pid", int64(pid)))
+	}
+
+	if err, ok := data["err"].(error); ok {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if level <= pgx.LogLevelError {
+		span.SetStatus(codes.Error, msg+" failed")
+	}
+}